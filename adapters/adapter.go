@@ -48,6 +48,14 @@ type (
 		String(line *Line) string
 	}
 
+	// TraceFormatter
+	// 链路格式化.
+	TraceFormatter interface {
+		// Byte
+		// 批量跨度转成字符集.
+		Byte(spans ...Span) ([]byte, error)
+	}
+
 	// TraceAdapter
 	// 链路适配器.
 	TraceAdapter interface {
@@ -17,6 +17,15 @@ package adapters
 
 import (
 	"encoding/json"
+	"sync/atomic"
+)
+
+// HTTP 请求属性.
+// 由 NewSpanFromRequest 写入跨度, 日志关联跨度时读取.
+const (
+	AttrHttpRequestMethod = "http.request.method"
+	AttrHttpRequestUri    = "http.request.uri"
+	AttrHttpUserAgent     = "http.user.agent"
 )
 
 var (
@@ -30,7 +39,12 @@ var (
 	//       "deploy.host": "fuyibing",
 	//       "deploy.pid": 3721
 	//   }
+	//
+	// 仅在启动时写入; 运行期间经 SetResource 发布, 经 GetResource 读取.
 	Resource = make(Attr)
+
+	// 资源快照.
+	resource atomic.Value
 )
 
 type (
@@ -44,6 +58,25 @@ type (
 	Attr map[string]interface{}
 )
 
+// GetResource
+// 当前系统资源.
+//
+// 返回值不可修改; 未发布时返回 Resource.
+func GetResource() Attr {
+	if v, ok := resource.Load().(Attr); ok {
+		return v
+	}
+	return Resource
+}
+
+// SetResource
+// 发布系统资源.
+//
+// 整体替换, 发布后不可修改, 已读取的资源不受影响.
+func SetResource(attr Attr) {
+	resource.Store(attr)
+}
+
 func (o Attr) Count() int {
 	if o != nil {
 		return len(o)
@@ -16,15 +16,31 @@
 package adapters
 
 import (
+	"fmt"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type (
 	// Bucket
 	// 数据桶.
 	Bucket struct {
-		lines []interface{}
-		mu    sync.RWMutex
+		dropped uint64
+		limit   func() *config.Bucket
+		lines   []interface{}
+		mu      sync.RWMutex
+		space   chan struct{}
+	}
+
+	// DropCounter
+	// 丢弃计数.
+	//
+	// 使用有界数据桶的适配器实现此接口, 返回因溢出丢弃的数量.
+	DropCounter interface {
+		Dropped() uint64
 	}
 )
 
@@ -36,12 +52,64 @@ func NewBucket() *Bucket {
 	}
 }
 
+// NewBoundedBucket
+// 创建有界数据桶.
+//
+// 每次添加时读取容量配置, 配置热更新后立即生效; 丢弃的数据若实现了
+// Release 方法则一并释放.
+func NewBoundedBucket(limit func() *config.Bucket) *Bucket {
+	return &Bucket{
+		limit: limit,
+		lines: make([]interface{}, 0),
+	}
+}
+
 // Add
 // 添加数据入桶.
+//
+// 超过容量时按溢出策略处理, 返回桶中数据数量.
 func (o *Bucket) Add(lines ...interface{}) int {
 	o.mu.Lock()
 	defer o.mu.Unlock()
-	o.lines = append(o.lines, lines...)
+
+	// 1. 不限容量.
+	var c *config.Bucket
+	if o.limit != nil {
+		c = o.limit()
+	}
+	if c == nil || c.Capacity <= 0 {
+		o.lines = append(o.lines, lines...)
+		return len(o.lines)
+	}
+
+	// 2. 逐条加入.
+	for _, v := range lines {
+		// 2.1 未满.
+		if len(o.lines) < c.Capacity {
+			o.lines = append(o.lines, v)
+			continue
+		}
+
+		// 2.2 溢出.
+		switch c.Overflow {
+		case config.OverflowBlock:
+			if o.wait(c.Capacity, time.Duration(c.OverflowMilliseconds)*time.Millisecond) {
+				o.lines = append(o.lines, v)
+			} else {
+				o.drop(v)
+			}
+		case config.OverflowDropOldest:
+			o.dropOldest(v)
+		case config.OverflowDropLevel:
+			if line, ok := v.(*Line); ok && line.Level != base.Off && line.Level <= c.OverflowLogLevel {
+				o.dropOldest(v)
+			} else {
+				o.drop(v)
+			}
+		default:
+			o.drop(v)
+		}
+	}
 	return len(o.lines)
 }
 
@@ -53,6 +121,12 @@ func (o *Bucket) Count() int {
 	return len(o.lines)
 }
 
+// Dropped
+// 因溢出丢弃的数量.
+func (o *Bucket) Dropped() uint64 {
+	return atomic.LoadUint64(&o.dropped)
+}
+
 // Pop
 // 取出1条数据.
 func (o *Bucket) Pop() interface{} {
@@ -67,6 +141,7 @@ func (o *Bucket) Pop() interface{} {
 func (o *Bucket) Popn(n int) (list []interface{}, count int) {
 	o.mu.Lock()
 	defer o.mu.Unlock()
+	defer o.signal()
 
 	// 1. 取出全部.
 	if total := len(o.lines); n >= total {
@@ -92,3 +167,61 @@ func (o *Bucket) Popn(n int) (list []interface{}, count int) {
 	o.lines = o.lines[n:]
 	return
 }
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+// 丢弃数据.
+// 首次丢弃时打印提示, 后续仅计数.
+func (o *Bucket) drop(v interface{}) {
+	if atomic.AddUint64(&o.dropped, 1) == 1 {
+		if c := o.limit(); c != nil {
+			_, _ = fmt.Fprintf(base.Stderr(), "bucket overflow: capacity %d reached, dropping by %s policy\n", c.Capacity, c.Overflow)
+		}
+	}
+	if r, ok := v.(interface{ Release() }); ok {
+		r.Release()
+	}
+}
+
+// 丢弃最早的数据并加入新数据.
+func (o *Bucket) dropOldest(v interface{}) {
+	o.drop(o.lines[0])
+	o.lines[0] = nil
+	o.lines = append(o.lines[1:], v)
+}
+
+// 通知等待方.
+// 取出数据后唤醒因 block 策略等待的 Add.
+func (o *Bucket) signal() {
+	if o.space != nil {
+		close(o.space)
+		o.space = nil
+	}
+}
+
+// 等待空位.
+//
+// 等待期间释放锁, 超时仍无空位时返回 false; 调用方须持有锁.
+func (o *Bucket) wait(capacity int, timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for len(o.lines) >= capacity {
+		if o.space == nil {
+			o.space = make(chan struct{})
+		}
+
+		ch := o.space
+		o.mu.Unlock()
+		select {
+		case <-ch:
+			o.mu.Lock()
+		case <-timer.C:
+			o.mu.Lock()
+			return len(o.lines) < capacity
+		}
+	}
+	return true
+}
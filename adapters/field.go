@@ -0,0 +1,368 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-29
+
+package adapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 字段类型.
+const (
+	FieldAny FieldKind = iota
+	FieldBool
+	FieldDuration
+	FieldError
+	FieldFloat
+	FieldInt
+	FieldString
+	FieldTime
+	FieldUint
+)
+
+type (
+	// Field
+	// 类型字段.
+	//
+	// 按类型保存字段值, 编码时无须反射; 数值类字段不分配内存.
+	//
+	//   log.Info("paid", log.String("order", id), log.Int("amount", 100))
+	Field struct {
+		Key  string
+		Kind FieldKind
+
+		Int int64
+		Str string
+		Any interface{}
+	}
+
+	// FieldKind
+	// 字段类型.
+	FieldKind uint8
+)
+
+// Value
+// 字段值.
+//
+// 用于按字典处理字段的场景, 如: 路由匹配, Kafka 等.
+func (o Field) Value() interface{} {
+	switch o.Kind {
+	case FieldBool:
+		return o.Int == 1
+	case FieldDuration:
+		return time.Duration(o.Int).String()
+	case FieldError:
+		if o.Any == nil {
+			return nil
+		}
+		return o.Any.(error).Error()
+	case FieldFloat:
+		return math.Float64frombits(uint64(o.Int))
+	case FieldInt:
+		return o.Int
+	case FieldString:
+		return o.Str
+	case FieldTime:
+		return time.Unix(0, o.Int).Format(time.RFC3339Nano)
+	case FieldUint:
+		return uint64(o.Int)
+	}
+	return o.Any
+}
+
+// +---------------------------------------------------------------------------+
+// | JSON encoding                                                             |
+// +---------------------------------------------------------------------------+
+
+// AppendJson
+// 追加JSON格式的字段值.
+func (o Field) AppendJson(b []byte) []byte {
+	switch o.Kind {
+	case FieldBool:
+		return strconv.AppendBool(b, o.Int == 1)
+	case FieldDuration:
+		return appendJsonString(b, time.Duration(o.Int).String())
+	case FieldError:
+		if o.Any == nil {
+			return append(b, "null"...)
+		}
+		return appendJsonString(b, o.Any.(error).Error())
+	case FieldFloat:
+		return appendJsonFloat(b, math.Float64frombits(uint64(o.Int)), 64)
+	case FieldInt:
+		return strconv.AppendInt(b, o.Int, 10)
+	case FieldString:
+		return appendJsonString(b, o.Str)
+	case FieldTime:
+		b = append(b, '"')
+		b = time.Unix(0, o.Int).AppendFormat(b, time.RFC3339Nano)
+		return append(b, '"')
+	case FieldUint:
+		return strconv.AppendUint(b, uint64(o.Int), 10)
+	}
+	return appendJsonValue(b, o.Any)
+}
+
+// AppendFields
+// 追加JSON格式的全部字段.
+//
+// 先按名称顺序写入 Attr, 再按添加顺序写入类型字段, 同名时以类型字段为准.
+//
+//	{"id":1,"key":"value"}
+func (o *Line) AppendFields(b []byte) []byte {
+	b = append(b, '{')
+	n := 0
+
+	// 1. 字典字段.
+	if len(o.Attr) > 0 {
+		keys := make([]string, 0, len(o.Attr))
+		for k := range o.Attr {
+			if !o.hasField(k) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if n > 0 {
+				b = append(b, ',')
+			}
+			b = appendJsonString(b, k)
+			b = append(b, ':')
+			b = appendJsonValue(b, o.Attr[k])
+			n++
+		}
+	}
+
+	// 2. 类型字段.
+	for _, f := range o.Fields {
+		if n > 0 {
+			b = append(b, ',')
+		}
+		b = appendJsonString(b, f.Key)
+		b = append(b, ':')
+		b = f.AppendJson(b)
+		n++
+	}
+
+	return append(b, '}')
+}
+
+// AppendLogfmt
+// 追加 logfmt 格式的全部字段.
+//
+// 字段顺序与 AppendFields 一致, 每个字段以空格开头; 值含空格、等号、引号或为空
+// 时加引号.
+//
+//	order=A001 amount=100 note="say hi"
+func (o *Line) AppendLogfmt(b []byte) []byte {
+	// 1. 字典字段.
+	if len(o.Attr) > 0 {
+		keys := make([]string, 0, len(o.Attr))
+		for k := range o.Attr {
+			if !o.hasField(k) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			b = AppendLogfmt(b, k, fmt.Sprint(o.Attr[k]))
+		}
+	}
+
+	// 2. 类型字段.
+	for _, f := range o.Fields {
+		if f.Kind == FieldString {
+			b = AppendLogfmt(b, f.Key, f.Str)
+		} else {
+			b = AppendLogfmt(b, f.Key, fmt.Sprint(f.Value()))
+		}
+	}
+	return b
+}
+
+// AppendLogfmt
+// 追加 logfmt 格式的单个字段, 以空格开头.
+func AppendLogfmt(b []byte, key, value string) []byte {
+	b = append(b, ' ')
+	b = append(b, key...)
+	b = append(b, '=')
+
+	if value == "" || strings.ContainsAny(value, " =\"\t\r\n") {
+		return strconv.AppendQuote(b, value)
+	}
+	return append(b, value...)
+}
+
+// FieldCount
+// 字段数量.
+func (o *Line) FieldCount() int {
+	return len(o.Attr) + len(o.Fields)
+}
+
+// FieldMap
+// 合并全部字段为字典.
+//
+// 无字段时返回 nil; 仅有 Attr 时直接返回 Attr.
+func (o *Line) FieldMap() map[string]interface{} {
+	if len(o.Fields) == 0 {
+		if len(o.Attr) == 0 {
+			return nil
+		}
+		return o.Attr
+	}
+
+	m := make(map[string]interface{}, o.FieldCount())
+	for k, v := range o.Attr {
+		m[k] = v
+	}
+	for _, f := range o.Fields {
+		m[f.Key] = f.Value()
+	}
+	return m
+}
+
+// FieldValue
+// 按名称读取字段.
+//
+// 类型字段优先, 与 FieldMap 一致; 不分配内存.
+func (o *Line) FieldValue(key string) (interface{}, bool) {
+	for i := len(o.Fields) - 1; i >= 0; i-- {
+		if o.Fields[i].Key == key {
+			return o.Fields[i].Value(), true
+		}
+	}
+	v, ok := o.Attr[key]
+	return v, ok
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+func (o *Line) hasField(key string) bool {
+	for _, f := range o.Fields {
+		if f.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// 与 json.Marshal 一致, 极大或极小值使用指数格式; NaN 等转为字符串.
+func appendJsonFloat(b []byte, f float64, bits int) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return appendJsonString(b, strconv.FormatFloat(f, 'g', -1, bits))
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	return strconv.AppendFloat(b, f, format, -1, bits)
+}
+
+func appendJsonString(b []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+
+	b = append(b, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		// 1. 单字节.
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				b = append(b, '\\', c)
+			case c == '\n':
+				b = append(b, '\\', 'n')
+			case c == '\r':
+				b = append(b, '\\', 'r')
+			case c == '\t':
+				b = append(b, '\\', 't')
+			case c < 0x20:
+				b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+			default:
+				b = append(b, c)
+			}
+			i++
+			continue
+		}
+
+		// 2. 多字节.
+		//    无效的UTF-8字符转为 �.
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, `�`...)
+		} else {
+			b = append(b, s[i:i+size]...)
+		}
+		i += size
+	}
+	return append(b, '"')
+}
+
+// 常用类型直接编码, 其它类型使用 json.Marshal.
+func appendJsonValue(b []byte, v interface{}) []byte {
+	switch x := v.(type) {
+	case nil:
+		return append(b, "null"...)
+	case string:
+		return appendJsonString(b, x)
+	case bool:
+		return strconv.AppendBool(b, x)
+	case int:
+		return strconv.AppendInt(b, int64(x), 10)
+	case int8:
+		return strconv.AppendInt(b, int64(x), 10)
+	case int16:
+		return strconv.AppendInt(b, int64(x), 10)
+	case int32:
+		return strconv.AppendInt(b, int64(x), 10)
+	case int64:
+		return strconv.AppendInt(b, x, 10)
+	case uint:
+		return strconv.AppendUint(b, uint64(x), 10)
+	case uint8:
+		return strconv.AppendUint(b, uint64(x), 10)
+	case uint16:
+		return strconv.AppendUint(b, uint64(x), 10)
+	case uint32:
+		return strconv.AppendUint(b, uint64(x), 10)
+	case uint64:
+		return strconv.AppendUint(b, x, 10)
+	case float32:
+		return appendJsonFloat(b, float64(x), 32)
+	case float64:
+		return appendJsonFloat(b, x, 64)
+	case time.Duration:
+		return appendJsonString(b, x.String())
+	case error:
+		return appendJsonString(b, x.Error())
+	}
+
+	if buf, err := json.Marshal(v); err == nil {
+		return append(b, buf...)
+	}
+	return append(b, "null"...)
+}
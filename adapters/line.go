@@ -21,6 +21,7 @@ import (
 	"github.com/go-wares/log/base"
 	"github.com/go-wares/log/config"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -41,6 +42,19 @@ type (
 		Tracer               bool
 		TraceId              string
 		SpanId, ParentSpanId string
+		SpanName             string
+
+		// HTTP 请求.
+		// 关联由 NewSpanFromRequest 创建的跨度时, 读取其请求属性.
+		RequestMethod, RequestUrl, UserAgent string
+
+		// 类型字段.
+		// 回池时保留容量, 重复使用不分配内存.
+		Fields []Field
+
+		// 引用计数.
+		// 同一行日志发送到多个适配器时, 全部释放后才回池.
+		refs int32
 	}
 )
 
@@ -53,11 +67,23 @@ func NewLine(ctx context.Context, level base.LogLevel, format string, args ...in
 	return x.before(ctx, level, format, args...)
 }
 
+// Release
+// 释放引用, 引用计数归零后回池.
 func (o *Line) Release() {
+	if atomic.AddInt32(&o.refs, -1) > 0 {
+		return
+	}
 	o.after()
 	linePool.Put(o)
 }
 
+// Retain
+// 增加N个引用, 每个引用须调用1次 Release.
+func (o *Line) Retain(n int) *Line {
+	atomic.AddInt32(&o.refs, int32(n))
+	return o
+}
+
 // +---------------------------------------------------------------------------+
 // | Access methods                                                            |
 // +---------------------------------------------------------------------------+
@@ -65,6 +91,12 @@ func (o *Line) Release() {
 func (o *Line) after() *Line {
 	o.Attr = nil
 	o.Ctx = nil
+
+	for i := range o.Fields {
+		o.Fields[i] = Field{}
+	}
+	o.Fields = o.Fields[:0]
+
 	o.Level = base.Off
 	o.Text = ""
 
@@ -73,6 +105,10 @@ func (o *Line) after() *Line {
 		o.TraceId = ""
 		o.SpanId = ""
 		o.ParentSpanId = ""
+		o.SpanName = ""
+		o.RequestMethod = ""
+		o.RequestUrl = ""
+		o.UserAgent = ""
 	}
 
 	return o
@@ -81,10 +117,17 @@ func (o *Line) after() *Line {
 // 初始字段.
 func (o *Line) before(ctx context.Context, level base.LogLevel, format string, args ...interface{}) *Line {
 	// 1. 必须字段.
+	o.refs = 1
 	o.Ctx = ctx
 	o.Level = level
 	o.Time = time.Now()
-	o.Text = fmt.Sprintf(format, args...)
+
+	// 无参数时不格式化.
+	if len(args) == 0 {
+		o.Text = format
+	} else {
+		o.Text = fmt.Sprintf(format, args...)
+	}
 
 	// 2. 堆栈日志.
 	if level == base.Fatal {
@@ -111,10 +154,18 @@ func (o *Line) openTracing(ctx context.Context) {
 		o.Tracer = true
 		o.TraceId = v.Trace().TraceId().String()
 		o.SpanId = v.SpanId().String()
+		o.SpanName = v.Name()
 
 		if p := v.ParentSpanId(); p != nil {
 			o.ParentSpanId = p.String()
 		}
+
+		// 1.1 HTTP 请求.
+		//     跨度可能先于日志释放, 创建时持锁复制; 未实现 SpanRequest 的
+		//     跨度不填充.
+		if r, ok := v.(SpanRequest); ok {
+			o.RequestMethod, o.RequestUrl, o.UserAgent = r.Request()
+		}
 		return
 	}
 
@@ -0,0 +1,184 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-30
+
+package adapters
+
+import (
+	"github.com/go-wares/log/config"
+	"strconv"
+)
+
+type (
+	// LogData
+	// 日志数据结构.
+	//
+	// Kafka 消息及文件适配器的 json 格式使用此结构, 由同一管道采集.
+	//
+	//   {
+	//       "content": "日志内容",
+	//       "fields": {
+	//           "id": 1,
+	//           "key": "value"
+	//       },
+	//       "level": "INFO",
+	//       "time": "2023-05-15T09:10:11.234Z",
+	//       "time_ms": 1684113011234,
+	//
+	//       "trace_id": "...",
+	//       "span_id": "...",
+	//       "request_method": "GET",
+	//       "request_url": "/orders",
+	//       "user_agent": "curl/8.0",
+	//
+	//       "pid": 3721,
+	//       "service_addr": "192.168.0.100:8080",
+	//       "service_name": "go-wares-log",
+	//       "service_version": "1.0"
+	//   }
+	LogData struct {
+		Content  string                 `json:"content"`
+		Keywords map[string]interface{} `json:"fields,omitempty"`
+		Level    string                 `json:"level"`
+		Time     string                 `json:"time"`
+		TimeMs   int64                  `json:"time_ms"`
+
+		// +------------------------------------------------------------+
+		// | Open tracing                                               |
+		// +------------------------------------------------------------+
+
+		ParentSpanId string `json:"parent_span_id,omitempty"`
+		SpanId       string `json:"span_id,omitempty"`
+		TraceId      string `json:"trace_id,omitempty"`
+
+		// +------------------------------------------------------------+
+		// | HTTP request                                               |
+		// +------------------------------------------------------------+
+
+		RequestMethod string `json:"request_method,omitempty"`
+		RequestUrl    string `json:"request_url,omitempty"`
+		UserAgent     string `json:"user_agent,omitempty"`
+
+		// +------------------------------------------------------------+
+		// | Server fields                                              |
+		// +------------------------------------------------------------+
+
+		// 进程号
+		Pid int `json:"pid"`
+
+		// 服务地址.
+		//
+		// 例如：192.168.0.100
+		//      192.168.0.100:8080
+		ServiceAddr    []string `json:"service_addr,omitempty"`
+		ServiceName    string   `json:"service_name"`
+		ServiceVersion string   `json:"service_version"`
+	}
+)
+
+// NewLogData
+// 转换日志.
+func NewLogData(c *config.Configuration, line *Line) *LogData {
+	c = c.Snapshot()
+	v := &LogData{
+		Content:        line.Text,
+		Level:          line.Level.String(),
+		Time:           line.Time.Format("2006-01-02T15:04:05.999999Z"),
+		TimeMs:         line.Time.UnixMilli(),
+		Pid:            c.Pid,
+		ServiceAddr:    c.Addr,
+		ServiceName:    c.Name,
+		ServiceVersion: c.Version,
+	}
+
+	// 关键字段.
+	if line.FieldCount() > 0 {
+		v.Keywords = line.FieldMap()
+	}
+
+	// 调用链路.
+	if line.Tracer {
+		v.ParentSpanId = line.ParentSpanId
+		v.SpanId = line.SpanId
+		v.TraceId = line.TraceId
+		v.RequestMethod = line.RequestMethod
+		v.RequestUrl = line.RequestUrl
+		v.UserAgent = line.UserAgent
+	}
+
+	return v
+}
+
+// AppendLogData
+// 追加 LogData 结构的 JSON.
+//
+// 字段名称及顺序与 json.Marshal(NewLogData(c, line)) 一致, 绑定字段经
+// AppendFields 编码, 不经 FieldMap 及反射.
+func AppendLogData(b []byte, c *config.Configuration, line *Line) []byte {
+	c = c.Snapshot()
+
+	// 1. 日志正文.
+	b = append(b, `{"content":`...)
+	b = appendJsonString(b, line.Text)
+	if line.FieldCount() > 0 {
+		b = append(b, `,"fields":`...)
+		b = line.AppendFields(b)
+	}
+	b = append(b, `,"level":`...)
+	b = appendJsonString(b, line.Level.String())
+	b = append(b, `,"time":"`...)
+	b = line.Time.AppendFormat(b, "2006-01-02T15:04:05.999999Z")
+	b = append(b, `","time_ms":`...)
+	b = strconv.AppendInt(b, line.Time.UnixMilli(), 10)
+
+	// 2. 调用链路.
+	if line.Tracer {
+		b = appendJsonPair(b, "parent_span_id", line.ParentSpanId)
+		b = appendJsonPair(b, "span_id", line.SpanId)
+		b = appendJsonPair(b, "trace_id", line.TraceId)
+		b = appendJsonPair(b, "request_method", line.RequestMethod)
+		b = appendJsonPair(b, "request_url", line.RequestUrl)
+		b = appendJsonPair(b, "user_agent", line.UserAgent)
+	}
+
+	// 3. 服务信息.
+	b = append(b, `,"pid":`...)
+	b = strconv.AppendInt(b, int64(c.Pid), 10)
+	if len(c.Addr) > 0 {
+		b = append(b, `,"service_addr":[`...)
+		for i, s := range c.Addr {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendJsonString(b, s)
+		}
+		b = append(b, ']')
+	}
+	b = append(b, `,"service_name":`...)
+	b = appendJsonString(b, c.Name)
+	b = append(b, `,"service_version":`...)
+	b = appendJsonString(b, c.Version)
+	return append(b, '}')
+}
+
+// 追加字符串字段, 空值时忽略(omitempty).
+func appendJsonPair(b []byte, key, value string) []byte {
+	if value == "" {
+		return b
+	}
+	b = append(b, ',', '"')
+	b = append(b, key...)
+	b = append(b, '"', ':')
+	return appendJsonString(b, value)
+}
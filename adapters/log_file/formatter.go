@@ -24,7 +24,9 @@ import (
 type (
 	// Formatter
 	// 格式化.
-	Formatter struct{}
+	Formatter struct {
+		config *config.Configuration
+	}
 )
 
 // Byte
@@ -33,11 +35,67 @@ func (o *Formatter) Byte(_ *adapters.Line) []byte { return nil }
 
 // String
 // 转成字符串.
+//
+// 按配置的格式(format)输出, 默认为 text.
 func (o *Formatter) String(line *adapters.Line) string {
+	switch o.config.Snapshot().LogAdapterFile.Format {
+	case config.FileFormatJson:
+		return o.json(line)
+	case config.FileFormatLogfmt:
+		return o.logfmt(line)
+	}
+	return o.text(line)
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+func (o *Formatter) init() *Formatter {
+	return o
+}
+
+// JSON 格式.
+// 与 Kafka 消息结构一致, 见 adapters.LogData.
+func (o *Formatter) json(line *adapters.Line) string {
+	return string(adapters.AppendLogData(make([]byte, 0, 512), o.config, line))
+}
+
+// logfmt 格式.
+//
+//	time=2023-05-30T09:10:11.234+08:00 level=INFO trace_id=... span_id=... msg="text" key=value
+func (o *Formatter) logfmt(line *adapters.Line) string {
+	b := make([]byte, 0, 256)
+	b = append(b, "time="...)
+	b = line.Time.AppendFormat(b, "2006-01-02T15:04:05.999Z07:00")
+	b = append(b, " level="...)
+	b = append(b, line.Level.String()...)
+
+	// 1. 链路信息.
+	if line.Tracer {
+		b = adapters.AppendLogfmt(b, "trace_id", line.TraceId)
+		b = adapters.AppendLogfmt(b, "span_id", line.SpanId)
+		if line.ParentSpanId != "" {
+			b = adapters.AppendLogfmt(b, "parent_span_id", line.ParentSpanId)
+		}
+	}
+
+	// 2. 用户正文.
+	b = adapters.AppendLogfmt(b, "msg", line.Text)
+
+	// 3. 绑定字段.
+	b = line.AppendLogfmt(b)
+	return string(b)
+}
+
+// 文本格式.
+//
+//	[2023-05-30 09:10:11.234][INFO] [trace-id=...][span-id=...][parent-span-id=...] {"key":"value"} text
+func (o *Formatter) text(line *adapters.Line) string {
 	var (
 		// 日志正文
 		text = fmt.Sprintf("[%s][%s]",
-			line.Time.Format(config.Config.LogTimeFormat),
+			line.Time.Format(o.config.Snapshot().LogTimeFormat),
 			line.Level,
 		)
 	)
@@ -53,10 +111,10 @@ func (o *Formatter) String(line *adapters.Line) string {
 	}
 
 	// 2. 绑定字段.
-	if len(line.Attr) > 0 {
+	if line.FieldCount() > 0 {
 		text = fmt.Sprintf("%s %s",
 			text,
-			line.Attr.Json(),
+			line.AppendFields(nil),
 		)
 	}
 
@@ -69,7 +127,3 @@ func (o *Formatter) String(line *adapters.Line) string {
 	// 4. 单行日志.
 	return text
 }
-
-func (o *Formatter) init() *Formatter {
-	return o
-}
@@ -0,0 +1,178 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-06-01
+
+package log_file
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+	"os"
+	"time"
+)
+
+const (
+	// 写缓冲大小.
+	handleBufferSize = 64 * 1024
+)
+
+type (
+	// 文件句柄.
+	//
+	// 按路径缓存, 写入经缓冲区, 每批写入后刷新缓冲区; 时间段切换、切割、压缩、
+	// 重开或退出时关闭.
+	handle struct {
+		buf  *bufio.Writer
+		file *os.File
+		path string
+		size int64
+	}
+)
+
+// Reopen
+// 重新打开文件.
+//
+// 关闭全部已打开的文件, 下次写入时重新打开; 用于 logrotate 移动文件后.
+func (o *Manager) Reopen() {
+	o.fileMu.Lock()
+	defer o.fileMu.Unlock()
+	o.closeAll()
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+// 追加写入.
+func (o *Manager) append(path string, body []byte) {
+	o.fileMu.Lock()
+	defer o.fileMu.Unlock()
+
+	// 1. 切割文件.
+	//    写入后超过最大值时, 先切割再写入新文件.
+	o.rotate(path, int64(len(body)))
+
+	// 2. 打开文件.
+	h, err := o.open(path)
+	if err != nil {
+		_, _ = fmt.Fprintf(base.Stderr(), "file open: %v\n", err)
+		return
+	}
+
+	// 3. 写入缓冲.
+	n, err := h.buf.Write(body)
+	h.size += int64(n)
+	if err != nil {
+		_, _ = fmt.Fprintf(base.Stderr(), "file write: %v\n", err)
+	}
+}
+
+// 关闭全部文件.
+// 调用方须持有 fileMu.
+func (o *Manager) closeAll() {
+	for path := range o.files {
+		o.closeFile(path)
+	}
+}
+
+// 关闭文件.
+// 调用方须持有 fileMu.
+func (o *Manager) closeFile(path string) {
+	h, ok := o.files[path]
+	if !ok {
+		return
+	}
+
+	delete(o.files, path)
+	if err := h.buf.Flush(); err != nil {
+		_, _ = fmt.Fprintf(base.Stderr(), "file write: %v\n", err)
+	}
+	if err := h.file.Close(); err != nil {
+		_, _ = fmt.Fprintf(base.Stderr(), "file close: %v\n", err)
+	}
+}
+
+// 刷新缓冲区.
+//
+// 每批写入后调用; 按落盘策略 fsync, 并关闭非当前时间段的文件.
+func (o *Manager) flush() {
+	o.fileMu.Lock()
+	defer o.fileMu.Unlock()
+
+	var (
+		now  = time.Now()
+		sync = o.config.Snapshot().LogAdapterFile.Sync == config.FileSyncBatch
+	)
+
+	for path, h := range o.files {
+		// 1. 时间段切换.
+		if !o.isActive(path, now) {
+			o.closeFile(path)
+			continue
+		}
+
+		// 2. 刷新缓冲.
+		if err := h.buf.Flush(); err != nil {
+			_, _ = fmt.Fprintf(base.Stderr(), "file write: %v\n", err)
+			continue
+		}
+
+		// 3. 每批落盘.
+		if sync {
+			if err := h.file.Sync(); err != nil {
+				_, _ = fmt.Fprintf(base.Stderr(), "file sync: %v\n", err)
+			}
+		}
+	}
+}
+
+// 打开文件.
+// 调用方须持有 fileMu.
+func (o *Manager) open(path string) (*handle, error) {
+	if h, ok := o.files[path]; ok {
+		return h, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, o.config.Snapshot().LogAdapterFile.FileMode.Perm())
+	if err != nil {
+		return nil, err
+	}
+
+	h := &handle{buf: bufio.NewWriterSize(file, handleBufferSize), file: file, path: path}
+	if info, se := file.Stat(); se == nil {
+		h.size = info.Size()
+	}
+
+	o.files[path] = h
+	o.symlink(path)
+	return h, nil
+}
+
+// 定时落盘.
+func (o *Manager) sync() {
+	o.fileMu.Lock()
+	defer o.fileMu.Unlock()
+
+	for _, h := range o.files {
+		if err := h.buf.Flush(); err != nil {
+			_, _ = fmt.Fprintf(base.Stderr(), "file write: %v\n", err)
+			continue
+		}
+		if err := h.file.Sync(); err != nil {
+			_, _ = fmt.Fprintf(base.Stderr(), "file sync: %v\n", err)
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/go-wares/log/base"
 	"github.com/go-wares/log/config"
 	"os"
+	"os/signal"
 	"sync"
 	"time"
 )
@@ -33,18 +34,35 @@ type (
 	// 发送用户日志到文件中, 记录在运行节点的磁盘上.
 	Manager struct {
 		bucket      *adapters.Bucket
+		config      *config.Configuration
 		directories map[string]bool
+		fileMu      sync.Mutex
+		files       map[string]*handle
 		formatter   adapters.LogFormatter
 		keeper      base.Keeper
+		linked      string
 		mu          sync.RWMutex
 		name        string
+		retention   *retention
+		saveMu      sync.Mutex
+		saving      sync.WaitGroup
 	}
 )
 
 func New() adapters.LogAdapter {
-	return (&Manager{}).init()
+	return NewWithConfig(config.Config)
 }
 
+// NewWithConfig
+// 基于指定配置创建适配器.
+func NewWithConfig(c *config.Configuration) adapters.LogAdapter {
+	return (&Manager{config: c}).init()
+}
+
+// Dropped
+// 数据桶溢出时丢弃的日志数量.
+func (o *Manager) Dropped() uint64 { return o.bucket.Dropped() }
+
 func (o *Manager) Keeper() base.Keeper { return o.keeper }
 
 // Send
@@ -52,8 +70,8 @@ func (o *Manager) Keeper() base.Keeper { return o.keeper }
 //
 // 若数据桶积压数量超过指定值时, 立即刷盘保存.
 func (o *Manager) Send(line *adapters.Line) {
-	if n := o.bucket.Add(line); n >= config.Config.LogAdapterFile.Batch {
-		go o.save()
+	if n := o.bucket.Add(line); n >= o.config.Snapshot().LogAdapterFile.Batch {
+		o.saveAsync()
 	}
 }
 
@@ -68,26 +86,57 @@ func (o *Manager) SetFormatter(formatter adapters.LogFormatter) {
 // +---------------------------------------------------------------------------+
 
 func (o *Manager) onAfter(ctx context.Context) (ignored bool) {
-	if o.bucket.Count() > 0 {
+	// 1. 等待保存.
+	//    后台保存完成前关闭文件, 其写入会重新打开文件且不再关闭.
+	o.saving.Wait()
+
+	// 2. 清空数据桶.
+	for o.bucket.Count() > 0 {
 		o.save()
-		return o.onAfter(ctx)
 	}
+
+	// 3. 关闭文件.
+	o.Reopen()
 	return
 }
 
 func (o *Manager) onListen(ctx context.Context) (ignored bool) {
+	f := o.config.Snapshot().LogAdapterFile
+
 	// 1. 定时保存.
 	//    每隔指定时长(默认: 350ms)上报一次日志.
-	ticker := time.NewTicker(time.Duration(config.Config.LogAdapterFile.Milliseconds) * time.Millisecond)
+	ticker := time.NewTicker(time.Duration(f.Milliseconds) * time.Millisecond)
 
 	// 2. 关闭定时.
 	defer ticker.Stop()
 
-	// 3. 监听信号.
+	// 3. 定时落盘.
+	//    仅在 sync 为 interval 时开启, 否则为 nil 通道, 永不触发.
+	var syncC <-chan time.Time
+	if f.Sync == config.FileSyncInterval {
+		st := time.NewTicker(time.Duration(f.SyncMilliseconds) * time.Millisecond)
+		defer st.Stop()
+		syncC = st.C
+	}
+
+	// 4. 重开信号.
+	//    收到 SIGHUP 时关闭全部文件, 兼容 logrotate 的 move/create 模式.
+	var reopenC chan os.Signal
+	if *f.ReopenOnSighup {
+		reopenC = make(chan os.Signal, 1)
+		notifyReopen(reopenC)
+		defer signal.Stop(reopenC)
+	}
+
+	// 5. 监听信号.
 	for {
 		select {
 		case <-ticker.C:
-			go o.save()
+			o.saveAsync()
+		case <-syncC:
+			o.sync()
+		case <-reopenC:
+			o.Reopen()
 		case <-ctx.Done():
 			return
 		}
@@ -99,13 +148,19 @@ func (o *Manager) onListen(ctx context.Context) (ignored bool) {
 // +---------------------------------------------------------------------------+
 
 func (o *Manager) init() *Manager {
-	o.bucket = adapters.NewBucket()
+	o.bucket = adapters.NewBoundedBucket(func() *config.Bucket { return &o.config.Snapshot().LogAdapterFile.Bucket })
 	o.directories = make(map[string]bool)
-	o.formatter = (&Formatter{}).init()
+	o.files = make(map[string]*handle)
+	o.formatter = (&Formatter{config: o.config}).init()
 	o.name = fmt.Sprintf("log-file-manager")
 	o.keeper = base.NewKeeper(o.name).
 		After(o.onAfter).
 		Listen(o.onListen)
+
+	// 过期清理.
+	// 作为子 Keeper 在后台压缩及删除日志文件.
+	o.retention = newRetention(o)
+	o.keeper.Add(o.retention.keeper)
 	return o
 }
 
@@ -120,14 +175,19 @@ func (o *Manager) mkdir(path string) {
 
 	// 创建目录.
 	o.directories[path] = true
-	if err := os.MkdirAll(path, os.ModePerm); err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "make dir: %v\n", err)
+	if err := os.MkdirAll(path, o.config.Snapshot().LogAdapterFile.DirMode.Perm()); err != nil {
+		_, _ = fmt.Fprintf(base.Stderr(), "make dir: %v\n", err)
 	}
 }
 
+// 保存日志.
+// 串行执行, 批次按出桶顺序写入, 按大小切割时不会错序.
 func (o *Manager) save() {
+	o.saveMu.Lock()
+	defer o.saveMu.Unlock()
+
 	var (
-		list, count = o.bucket.Popn(config.Config.LogAdapterFile.Batch)
+		list, count = o.bucket.Popn(o.config.Snapshot().LogAdapterFile.Batch)
 		writer      *Writer
 	)
 
@@ -153,3 +213,13 @@ func (o *Manager) save() {
 	writer = NewWriter()
 	writer.Send(o, list)
 }
+
+// 后台保存.
+// 由 onAfter 等待完成.
+func (o *Manager) saveAsync() {
+	o.saving.Add(1)
+	go func() {
+		defer o.saving.Done()
+		o.save()
+	}()
+}
@@ -0,0 +1,310 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-31
+
+package log_file
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// 清理频率.
+	retentionInterval = time.Minute
+)
+
+type (
+	// 过期清理.
+	//
+	// 启动时及每隔1分钟执行1次, 切割文件后立即执行; 依次压缩、按天数删除、按
+	// 数量删除日志文件, 当前写入的文件不参与清理.
+	retention struct {
+		keeper  base.Keeper
+		manager *Manager
+		name    string
+		signal  chan struct{}
+	}
+
+	// 日志文件.
+	// stream 区分普通日志(main)及错误日志(error), 按数量清理时分别计数.
+	retentionFile struct {
+		modTime time.Time
+		path    string
+		stream  string
+	}
+)
+
+func newRetention(manager *Manager) *retention {
+	return (&retention{manager: manager}).init()
+}
+
+// +---------------------------------------------------------------------------+
+// | Event methods                                                             |
+// +---------------------------------------------------------------------------+
+
+func (o *retention) onListen(ctx context.Context) (ignored bool) {
+	// 1. 启动清理.
+	o.clean()
+
+	// 2. 定时清理.
+	ticker := time.NewTicker(retentionInterval)
+	defer ticker.Stop()
+
+	// 3. 监听信号.
+	for {
+		select {
+		case <-ticker.C:
+			o.clean()
+		case <-o.signal:
+			o.clean()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+func (o *retention) clean() {
+	c := o.manager.config.Snapshot().LogAdapterFile
+
+	// 1. 未开启.
+	if !*c.Compress && c.MaxAge <= 0 && c.MaxCount <= 0 {
+		return
+	}
+
+	// 2. 日志文件.
+	//    按修改时间倒序, 最新的在前.
+	files := o.scan(c, time.Now())
+	sort.SliceStable(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+
+	// 3. 逐个清理.
+	var (
+		deadline = time.Now().Add(-time.Duration(c.MaxAge) * 24 * time.Hour)
+		kept     = make(map[string]int)
+	)
+	for _, f := range files {
+		// 3.1 过期或超出数量.
+		//     普通日志与错误日志分别计数.
+		if (c.MaxAge > 0 && f.modTime.Before(deadline)) || (c.MaxCount > 0 && kept[f.stream] >= c.MaxCount) {
+			o.remove(f.path)
+			continue
+		}
+
+		// 3.2 压缩文件.
+		kept[f.stream]++
+		if *c.Compress && !strings.HasSuffix(f.path, gzipExt) {
+			o.compress(f)
+		}
+	}
+}
+
+// 压缩文件.
+// 写入 .gz 文件后删除原文件, 保留原文件的修改时间; 同名压缩文件已存在时
+// 追加序号, 不覆盖已有的压缩文件.
+func (o *retention) compress(f *retentionFile) {
+	o.manager.fileMu.Lock()
+	defer o.manager.fileMu.Unlock()
+
+	// 关闭句柄.
+	// 上个时间段的文件可能仍未关闭.
+	o.manager.closeFile(f.path)
+
+	var (
+		c   = o.manager.config.Snapshot().LogAdapterFile
+		dst = f.path + gzipExt
+	)
+	for i := 1; ; i++ {
+		err := gzipFile(f.path, dst, c.FileMode.Perm())
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			_, _ = fmt.Fprintf(base.Stderr(), "%s compress: %v\n", o.name, err)
+			return
+		}
+
+		// 已存在时追加序号, 如: 2023-05-13.1.log.gz.
+		dst = fmt.Sprintf("%s.%d.%s%s", strings.TrimSuffix(f.path, "."+c.Ext), i, c.Ext, gzipExt)
+	}
+
+	_ = os.Chtimes(dst, f.modTime, f.modTime)
+	_ = os.Remove(f.path)
+}
+
+func (o *retention) init() *retention {
+	o.name = fmt.Sprintf("log-file-retention")
+	o.signal = make(chan struct{}, 1)
+	o.keeper = base.NewKeeper(o.name).Listen(o.onListen)
+	return o
+}
+
+// 通知清理.
+func (o *retention) notify() {
+	select {
+	case o.signal <- struct{}{}:
+	default:
+	}
+}
+
+// 删除文件.
+// 目录为空时一并删除, 并清除目录缓存; 根目录及当前时间段的目录除外.
+func (o *retention) remove(path string) {
+	if err := os.Remove(path); err != nil {
+		_, _ = fmt.Fprintf(base.Stderr(), "%s remove: %v\n", o.name, err)
+		return
+	}
+
+	dir := filepath.Clean(filepath.Dir(path))
+	if dir == filepath.Clean(o.manager.config.Snapshot().LogAdapterFile.Path) || dir == filepath.Clean(o.manager.folderPath(time.Now())) {
+		return
+	}
+	if os.Remove(dir) == nil {
+		o.manager.mu.Lock()
+		for k := range o.manager.directories {
+			if filepath.Clean(k) == dir {
+				delete(o.manager.directories, k)
+			}
+		}
+		o.manager.mu.Unlock()
+	}
+}
+
+// 扫描日志文件.
+//
+// 仅包含按 folder 及 name(或 error_name)格式生成的文件, 含切割、压缩后的文件,
+// 不含当前写入的文件及符号链接; 同一目录下的其它文件不参与清理.
+func (o *retention) scan(c *config.LogAdapterFile, now time.Time) []*retentionFile {
+	files := make([]*retentionFile, 0)
+
+	_ = filepath.Walk(c.Path, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || info.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+		if o.manager.isActive(path, now) {
+			return nil
+		}
+		if stream := retentionStream(c, path); stream != "" {
+			files = append(files, &retentionFile{modTime: info.ModTime(), path: path, stream: stream})
+		}
+		return nil
+	})
+	return files
+}
+
+// gzip 压缩.
+// 目标文件已存在时返回 os.ErrExist 错误, 压缩失败时删除目标文件.
+func gzipFile(src, dst string, perm os.FileMode) (err error) {
+	var (
+		in, out *os.File
+		w       *gzip.Writer
+	)
+
+	if in, err = os.Open(src); err != nil {
+		return
+	}
+	defer func() { _ = in.Close() }()
+
+	if out, err = os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm); err != nil {
+		return
+	}
+	defer func() {
+		if ce := out.Close(); err == nil {
+			err = ce
+		}
+		if err != nil {
+			_ = os.Remove(dst)
+		}
+	}()
+
+	w = gzip.NewWriter(out)
+	if _, err = io.Copy(w, in); err != nil {
+		return
+	}
+	return w.Close()
+}
+
+// 日志类型.
+//
+// 目录须匹配 folder 格式, 文件名去除扩展名及序号后须匹配 name 或 error_name
+// 格式, 如:
+//
+//	./logs/2023-05/2023-05-13.1.log.gz -> main
+//	./logs/2023-05/2023-05-13.error.log -> error
+//
+// 不匹配时返回空字符串.
+func retentionStream(c *config.LogAdapterFile, path string) string {
+	rel, err := filepath.Rel(c.Path, path)
+	if err != nil {
+		return ""
+	}
+
+	// 1. 目录.
+	dir, name := filepath.Split(filepath.ToSlash(rel))
+	if _, err = time.Parse(c.Folder, strings.TrimSuffix(dir, "/")); err != nil {
+		return ""
+	}
+
+	// 2. 扩展名.
+	name = strings.TrimSuffix(name, gzipExt)
+	if !strings.HasSuffix(name, "."+c.Ext) {
+		return ""
+	}
+	name = strings.TrimSuffix(name, "."+c.Ext)
+
+	// 3. 文件名.
+	//    切割及压缩重名时追加序号, 最多2级.
+	for i := 0; i < 3; i++ {
+		if c.ErrorName != "" {
+			if _, err = time.Parse(c.ErrorName, name); err == nil {
+				return "error"
+			}
+		}
+		if _, err = time.Parse(c.Name, name); err == nil {
+			return "main"
+		}
+
+		n := strings.LastIndexByte(name, '.')
+		if n < 0 || !retentionIndex(name[n+1:]) {
+			break
+		}
+		name = name[:n]
+	}
+	return ""
+}
+
+// 是否为序号.
+func retentionIndex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,168 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-31
+
+package log_file
+
+import (
+	"fmt"
+	"github.com/go-wares/log/base"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// 压缩文件扩展名.
+	gzipExt = ".gz"
+
+	// 1MB.
+	megabyte = 1024 * 1024
+)
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+// 错误日志文件路径.
+// 未配置 error_name 时返回空.
+//
+//	./logs/2023-05/2023-05-13.error.log
+func (o *Manager) errorPath(t time.Time) string {
+	f := o.config.Snapshot().LogAdapterFile
+	if f.ErrorName == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/%s.%s",
+		f.Path,
+		t.Format(f.Folder),
+		t.Format(f.ErrorName),
+		f.Ext,
+	)
+}
+
+// 日志文件路径.
+//
+//	./logs/2023-05/2023-05-13.log
+func (o *Manager) filePath(t time.Time) string {
+	f := o.config.Snapshot().LogAdapterFile
+	return fmt.Sprintf("%s/%s/%s.%s",
+		f.Path,
+		t.Format(f.Folder),
+		t.Format(f.Name),
+		f.Ext,
+	)
+}
+
+// 是否为当前时间段的文件.
+// 含主文件及错误日志文件.
+func (o *Manager) isActive(path string, t time.Time) bool {
+	path = filepath.Clean(path)
+	if path == filepath.Clean(o.filePath(t)) {
+		return true
+	}
+	ep := o.errorPath(t)
+	return ep != "" && path == filepath.Clean(ep)
+}
+
+// 日志目录路径.
+//
+//	./logs/2023-05
+func (o *Manager) folderPath(t time.Time) string {
+	f := o.config.Snapshot().LogAdapterFile
+	return fmt.Sprintf("%s/%s",
+		f.Path,
+		t.Format(f.Folder),
+	)
+}
+
+// 按大小切割.
+//
+// 写入N字节后超过最大值时, 将当前文件重命名为带序号的文件, 序号递增, 如:
+//
+//	./logs/2023-05/2023-05-13.log -> ./logs/2023-05/2023-05-13.1.log
+//
+// 调用方须持有 fileMu.
+func (o *Manager) rotate(path string, n int64) {
+	max := int64(o.config.Snapshot().LogAdapterFile.MaxSize) * megabyte
+	if max <= 0 {
+		return
+	}
+
+	// 1. 文件大小.
+	//    已打开的文件以句柄记录的大小为准(含缓冲区).
+	var size int64
+	if h, ok := o.files[path]; ok {
+		size = h.size
+	} else if info, err := os.Stat(path); err == nil {
+		size = info.Size()
+	}
+
+	// 2. 未超过.
+	if size == 0 || size+n <= max {
+		return
+	}
+
+	// 3. 重命名.
+	//    先关闭句柄, 刷新缓冲区.
+	o.closeFile(path)
+	target := rotatePath(path, o.config.Snapshot().LogAdapterFile.Ext)
+	if err := os.Rename(path, target); err != nil {
+		_, _ = fmt.Fprintf(base.Stderr(), "file rotate: %v\n", err)
+		return
+	}
+
+	// 4. 通知压缩.
+	o.retention.notify()
+}
+
+// 切割文件路径.
+// 序号为同名已切割文件(含已压缩)的最大序号加1.
+func rotatePath(path, ext string) string {
+	var (
+		dir    = filepath.Dir(path)
+		prefix = strings.TrimSuffix(filepath.Base(path), "."+ext)
+		index  = 0
+	)
+
+	if entries, err := os.ReadDir(dir); err == nil {
+		for _, entry := range entries {
+			if n, ok := rotateIndex(entry.Name(), prefix, ext); ok && n > index {
+				index = n
+			}
+		}
+	}
+
+	return filepath.Join(dir, fmt.Sprintf("%s.%d.%s", prefix, index+1, ext))
+}
+
+// 切割序号.
+//
+//	2023-05-13.2.log    -> 2, true
+//	2023-05-13.2.log.gz -> 2, true
+//	2023-05-13.log      -> 0, false
+func rotateIndex(name, prefix, ext string) (int, bool) {
+	name = strings.TrimSuffix(name, gzipExt)
+	if !strings.HasPrefix(name, prefix+".") || !strings.HasSuffix(name, "."+ext) {
+		return 0, false
+	}
+
+	s := strings.TrimSuffix(strings.TrimPrefix(name, prefix+"."), "."+ext)
+	if n, err := strconv.Atoi(s); err == nil && n > 0 {
+		return n, true
+	}
+	return 0, false
+}
@@ -0,0 +1,30 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-06-01
+
+//go:build !windows
+// +build !windows
+
+package log_file
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// 监听 SIGHUP 信号.
+func notifyReopen(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGHUP)
+}
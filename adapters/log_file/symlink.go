@@ -0,0 +1,65 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-06-02
+
+package log_file
+
+import (
+	"fmt"
+	"github.com/go-wares/log/base"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// 指向当前文件.
+//
+// 先创建临时链接再重命名覆盖, 读取方不会看到链接缺失的状态, 如:
+//
+//	./logs/current.log -> 2023-05/2023-05-13.log
+//
+// 调用方须持有 fileMu.
+func (o *Manager) symlink(path string) {
+	c := o.config.Snapshot().LogAdapterFile
+
+	// 1. 未开启或未变更.
+	if c.Symlink == "" || path == o.linked || path != o.filePath(time.Now()) {
+		return
+	}
+
+	// 2. 相对路径.
+	//    目录整体移动后链接仍然有效.
+	target, err := filepath.Rel(c.Path, path)
+	if err != nil {
+		target = path
+	}
+
+	// 3. 原子替换.
+	var (
+		link = filepath.Join(c.Path, c.Symlink)
+		tmp  = fmt.Sprintf("%s.%d.tmp", link, os.Getpid())
+	)
+	_ = os.Remove(tmp)
+	if err = os.Symlink(target, tmp); err == nil {
+		if err = os.Rename(tmp, link); err != nil {
+			_ = os.Remove(tmp)
+		}
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(base.Stderr(), "file symlink: %v\n", err)
+		return
+	}
+
+	o.linked = path
+}
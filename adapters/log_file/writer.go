@@ -16,10 +16,8 @@
 package log_file
 
 import (
-	"fmt"
 	"github.com/go-wares/log/adapters"
-	"github.com/go-wares/log/config"
-	"os"
+	"github.com/go-wares/log/base"
 	"strings"
 	"sync"
 )
@@ -60,36 +58,33 @@ func (o *Writer) Send(manager *Manager, list []interface{}) {
 		// 1.1 文件夹名.
 		//
 		// - ./logs/2023-05/2023-05-13.log
-		name := fmt.Sprintf("%s/%s/%s.%s",
-			config.Config.LogAdapterFile.Path,
-			line.Time.Format(config.Config.LogAdapterFile.Folder),
-			line.Time.Format(config.Config.LogAdapterFile.Name),
-			config.Config.LogAdapterFile.Ext,
-		)
+		name := manager.filePath(line.Time)
 
 		// 1.2 创建目录.
 		if _, ok := files[name]; !ok {
 			files[name] = make([]string, 0)
-			manager.mkdir(fmt.Sprintf("%s/%s",
-				config.Config.LogAdapterFile.Path,
-				line.Time.Format(config.Config.LogAdapterFile.Folder),
-			))
+			manager.mkdir(manager.folderPath(line.Time))
 		}
 
 		// 1.3 加入日志.
-		files[name] = append(files[name], manager.formatter.String(line))
+		text := manager.formatter.String(line)
+		files[name] = append(files[name], text)
+
+		// 1.4 错误日志.
+		//     级别不低于 error_level 时同时写入错误日志文件.
+		if line.Level != base.Off && line.Level <= manager.config.Snapshot().LogAdapterFile.ErrorLogLevel {
+			if ep := manager.errorPath(line.Time); ep != "" {
+				files[ep] = append(files[ep], text)
+			}
+		}
 	}
 
-	// 2. 并行写入.
-	w := &sync.WaitGroup{}
+	// 2. 顺序写入.
+	//    写入缓存的文件句柄, 完成后刷新缓冲区.
 	for fp, fl := range files {
-		w.Add(1)
-		go func(fp string, fl []string) {
-			defer w.Done()
-			o.write(fp, fl)
-		}(fp, fl)
+		manager.append(fp, []byte(strings.Join(fl, "\n")+"\n"))
 	}
-	w.Wait()
+	manager.flush()
 }
 
 // +---------------------------------------------------------------------------+
@@ -108,29 +103,3 @@ func (o *Writer) init() *Writer {
 	o.paths = make(map[string]bool)
 	return o
 }
-
-// 写入日志.
-func (o *Writer) write(path string, list []string) {
-	var (
-		err  error
-		file *os.File
-	)
-
-	// 1. 打开文件.
-	if file, err = os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, os.ModePerm); err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "file open: %v\n", err)
-		return
-	}
-
-	// 2. 关闭文件.
-	defer func() {
-		if err = file.Close(); err != nil {
-			_, _ = fmt.Fprintf(os.Stderr, "file close: %v\n", err)
-		}
-	}()
-
-	// 3. 写入日志.
-	if _, err = file.WriteString(strings.Join(list, "\n") + "\n"); err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "file write: %v\n", err)
-	}
-}
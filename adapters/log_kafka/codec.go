@@ -0,0 +1,192 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-06-09
+
+package log_kafka
+
+import (
+	"github.com/go-wares/log/adapters"
+)
+
+const (
+	// AvroSchema
+	// Avro 编码的消息结构.
+	//
+	// 消息体为单条记录的二进制编码, 不含 Schema Registry 前缀; 缺失的字符串
+	// 字段为空字符串, fields 的值按 json 编码为字符串(字符串原样).
+	AvroSchema = `{"type":"record","name":"LogData","namespace":"github.com.go_wares.log","fields":[` +
+		`{"name":"content","type":"string"},` +
+		`{"name":"fields","type":{"type":"map","values":"string"}},` +
+		`{"name":"level","type":"string"},` +
+		`{"name":"time","type":"string"},` +
+		`{"name":"time_ms","type":"long"},` +
+		`{"name":"parent_span_id","type":"string"},` +
+		`{"name":"span_id","type":"string"},` +
+		`{"name":"trace_id","type":"string"},` +
+		`{"name":"request_method","type":"string"},` +
+		`{"name":"request_url","type":"string"},` +
+		`{"name":"user_agent","type":"string"},` +
+		`{"name":"pid","type":"int"},` +
+		`{"name":"service_addr","type":{"type":"array","items":"string"}},` +
+		`{"name":"service_name","type":"string"},` +
+		`{"name":"service_version","type":"string"}]}`
+
+	// ProtoSchema
+	// Protobuf 编码的消息结构.
+	//
+	// fields 的值按 json 编码为字符串(字符串原样).
+	ProtoSchema = `syntax = "proto3";
+
+message LogData {
+  string content = 1;
+  map<string, string> fields = 2;
+  string level = 3;
+  string time = 4;
+  int64 time_ms = 5;
+  string parent_span_id = 6;
+  string span_id = 7;
+  string trace_id = 8;
+  string request_method = 9;
+  string request_url = 10;
+  string user_agent = 11;
+  int32 pid = 12;
+  repeated string service_addr = 13;
+  string service_name = 14;
+  string service_version = 15;
+}
+`
+)
+
+// Protobuf 编码类型.
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+// +---------------------------------------------------------------------------+
+// | Avro binary encoding                                                      |
+// +---------------------------------------------------------------------------+
+
+// 按 AvroSchema 编码.
+func encodeAvro(d *adapters.LogData) []byte {
+	b := make([]byte, 0, 512)
+	b = avroString(b, d.Content)
+
+	// 字典.
+	// 单个块, 以 0 结束.
+	if n := len(d.Keywords); n > 0 {
+		b = avroLong(b, int64(n))
+		for _, k := range sortedKeys(d.Keywords) {
+			b = avroString(b, k)
+			b = avroString(b, stringValue(d.Keywords[k]))
+		}
+	}
+	b = avroLong(b, 0)
+
+	b = avroString(b, d.Level)
+	b = avroString(b, d.Time)
+	b = avroLong(b, d.TimeMs)
+	b = avroString(b, d.ParentSpanId)
+	b = avroString(b, d.SpanId)
+	b = avroString(b, d.TraceId)
+	b = avroString(b, d.RequestMethod)
+	b = avroString(b, d.RequestUrl)
+	b = avroString(b, d.UserAgent)
+	b = avroLong(b, int64(d.Pid))
+
+	// 数组.
+	if n := len(d.ServiceAddr); n > 0 {
+		b = avroLong(b, int64(n))
+		for _, s := range d.ServiceAddr {
+			b = avroString(b, s)
+		}
+	}
+	b = avroLong(b, 0)
+
+	b = avroString(b, d.ServiceName)
+	return avroString(b, d.ServiceVersion)
+}
+
+// int 与 long 均为 zigzag 变长编码.
+func avroLong(b []byte, v int64) []byte {
+	return appendVarint(b, uint64(v<<1)^uint64(v>>63))
+}
+
+func avroString(b []byte, v string) []byte {
+	b = avroLong(b, int64(len(v)))
+	return append(b, v...)
+}
+
+// +---------------------------------------------------------------------------+
+// | Protobuf wire format                                                      |
+// +---------------------------------------------------------------------------+
+
+// 按 ProtoSchema 编码.
+func encodeProto(d *adapters.LogData) []byte {
+	b := make([]byte, 0, 512)
+	b = protoString(b, 1, d.Content)
+	for _, k := range sortedKeys(d.Keywords) {
+		entry := protoString(nil, 1, k)
+		entry = protoString(entry, 2, stringValue(d.Keywords[k]))
+		b = protoBytes(b, 2, entry)
+	}
+	b = protoString(b, 3, d.Level)
+	b = protoString(b, 4, d.Time)
+	b = protoInt(b, 5, d.TimeMs)
+	b = protoString(b, 6, d.ParentSpanId)
+	b = protoString(b, 7, d.SpanId)
+	b = protoString(b, 8, d.TraceId)
+	b = protoString(b, 9, d.RequestMethod)
+	b = protoString(b, 10, d.RequestUrl)
+	b = protoString(b, 11, d.UserAgent)
+	b = protoInt(b, 12, int64(d.Pid))
+	for _, s := range d.ServiceAddr {
+		b = protoBytes(b, 13, []byte(s))
+	}
+	b = protoString(b, 14, d.ServiceName)
+	return protoString(b, 15, d.ServiceVersion)
+}
+
+func appendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+// 长度前缀字段.
+// 空值也写入, 用于重复字段及字典项.
+func protoBytes(b []byte, field int, v []byte) []byte {
+	b = appendVarint(b, uint64(field)<<3|wireBytes)
+	b = appendVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func protoInt(b []byte, field int, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendVarint(b, uint64(field)<<3|wireVarint)
+	return appendVarint(b, uint64(v))
+}
+
+func protoString(b []byte, field int, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = appendVarint(b, uint64(field)<<3|wireBytes)
+	b = appendVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
@@ -0,0 +1,170 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-06-07
+
+package log_kafka
+
+import (
+	"github.com/Shopify/sarama"
+	"github.com/go-wares/log/config"
+	"sync"
+)
+
+type (
+	// ErrorHandler
+	// 投递失败回调.
+	//
+	// 消息发送失败且无法写入暂存时, 逐条回调.
+	ErrorHandler func(msg *sarama.ProducerMessage, err error)
+
+	// Producer
+	// 消息生产者.
+	//
+	// 由适配器按批次调用 Send; 同步实现返回发送结果, 部分失败时返回
+	// sarama.ProducerErrors; 异步实现写入发送队列后返回, 失败经回调通知.
+	Producer interface {
+		Close() error
+		Send(msg []*sarama.ProducerMessage) error
+	}
+
+	// ProducerFactory
+	// 生产者工厂.
+	//
+	// 按配置创建生产者; 异步实现须将失败消息回调 onError, 由适配器写入暂存或
+	// 通知 ErrorHandler.
+	ProducerFactory func(k *config.LogAdapterKafka, onError ErrorHandler) (Producer, error)
+
+	asyncProducer struct {
+		closed   bool
+		done     chan struct{}
+		mu       sync.RWMutex
+		onError  ErrorHandler
+		producer sarama.AsyncProducer
+	}
+
+	syncProducer struct {
+		producer sarama.SyncProducer
+	}
+)
+
+// NewAsyncProducer
+// 异步生产者.
+//
+// 在后台读取 Errors() 通道并回调 onError; 调用方须开启
+// Producer.Return.Errors, 关闭 Producer.Return.Successes.
+func NewAsyncProducer(producer sarama.AsyncProducer, onError ErrorHandler) Producer {
+	o := &asyncProducer{done: make(chan struct{}), onError: onError, producer: producer}
+	go o.listen()
+	return o
+}
+
+// NewSyncProducer
+// 同步生产者.
+func NewSyncProducer(producer sarama.SyncProducer) Producer {
+	return &syncProducer{producer: producer}
+}
+
+// +---------------------------------------------------------------------------+
+// | Interface methods                                                         |
+// +---------------------------------------------------------------------------+
+
+// Close
+// 关闭生产者.
+//
+// 等待队列中的消息发送完成, 并回调全部失败消息后返回; 关闭后 Send 返回
+// sarama.ErrShuttingDown.
+func (o *asyncProducer) Close() error {
+	o.mu.Lock()
+	if o.closed {
+		o.mu.Unlock()
+		return nil
+	}
+	o.closed = true
+	o.mu.Unlock()
+
+	o.producer.AsyncClose()
+	<-o.done
+	return nil
+}
+
+func (o *asyncProducer) Send(msg []*sarama.ProducerMessage) error {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if o.closed {
+		return sarama.ErrShuttingDown
+	}
+	for _, m := range msg {
+		o.producer.Input() <- m
+	}
+	return nil
+}
+
+func (o *syncProducer) Close() error { return o.producer.Close() }
+
+func (o *syncProducer) Send(msg []*sarama.ProducerMessage) error {
+	return o.producer.SendMessages(msg)
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+// 监听失败.
+// 通道在生产者关闭后结束.
+func (o *asyncProducer) listen() {
+	defer close(o.done)
+	for e := range o.producer.Errors() {
+		if o.onError != nil {
+			o.onError(e.Msg, e.Err)
+		}
+	}
+}
+
+// 按配置创建生产者.
+func newProducer(k *config.LogAdapterKafka, onError ErrorHandler) (Producer, error) {
+	c, err := NewProducerConfig(k)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 异步模式.
+	if k.Mode == config.KafkaModeAsync {
+		p, pe := sarama.NewAsyncProducer(k.Host, c)
+		if pe != nil {
+			return nil, pe
+		}
+		return NewAsyncProducer(p, onError), nil
+	}
+
+	// 2. 同步模式.
+	p, err := sarama.NewSyncProducer(k.Host, c)
+	if err != nil {
+		return nil, err
+	}
+	return NewSyncProducer(p), nil
+}
+
+// 转为逐条失败.
+// 非 sarama.ProducerErrors 时, 整批视为失败.
+func producerErrors(msg []*sarama.ProducerMessage, err error) sarama.ProducerErrors {
+	if pe, ok := err.(sarama.ProducerErrors); ok {
+		return pe
+	}
+	list := make(sarama.ProducerErrors, 0, len(msg))
+	for _, m := range msg {
+		list = append(list, &sarama.ProducerError{Msg: m, Err: err})
+	}
+	return list
+}
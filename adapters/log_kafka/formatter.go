@@ -16,7 +16,6 @@
 package log_kafka
 
 import (
-	"encoding/json"
 	"github.com/go-wares/log/adapters"
 	"github.com/go-wares/log/config"
 )
@@ -25,96 +24,38 @@ type (
 	// Data
 	// 存储到 Kafka 的数据结构.
 	//
-	//   {
-	//       "time": "2023-05-15 09:10:11.234",
-	//       "timestamp_ms": 1684113011234,
-	//       "level": "INFO",
-	//       "content": "日志内容",
-	//
-	//       "keywords": {
-	//           "id": 1,
-	//           "key": "value"
-	//       },
-	//
-	//       "pid": 3721,
-	//       "service_addr": "192.168.0.100:8080",
-	//       "service_name": "go-wares-log",
-	//       "service_version": "1.0"
-	//   }
-	Data struct {
-		Content  string                 `json:"content"`
-		Keywords map[string]interface{} `json:"fields,omitempty"`
-		Level    string                 `json:"level"`
-		Time     string                 `json:"time"`
-		TimeMs   int64                  `json:"time_ms"`
-
-		// +------------------------------------------------------------+
-		// | Open tracing                                               |
-		// +------------------------------------------------------------+
-
-		ParentSpanId string `json:"parent_span_id,omitempty"`
-		SpanId       string `json:"span_id,omitempty"`
-		TraceId      string `json:"trace_id,omitempty"`
-
-		// +------------------------------------------------------------+
-		// | HTTP Client request                                        |
-		// +------------------------------------------------------------+
-
-		RequestMethod string `json:"request_method,omitempty"`
-		RequestUrl    string `json:"request_url,omitempty"`
-		UserAgent     string `json:"user_agent,omitempty"`
-
-		// +------------------------------------------------------------+
-		// | Server fields                                              |
-		// +------------------------------------------------------------+
-
-		// 进程号
-		Pid int `json:"pid"`
-
-		// 服务地址.
-		//
-		// 例如：192.168.0.100
-		//      192.168.0.100:8080
-		ServiceAddr    []string `json:"service_addr,omitempty"`
-		ServiceName    string   `json:"service_name"`
-		ServiceVersion string   `json:"service_version"`
-	}
+	// 默认结构及 json 编码时与文件适配器的 json 格式一致, 见 adapters.LogData.
+	Data = adapters.LogData
 
 	// Formatter
 	// 格式化.
-	Formatter struct{}
+	//
+	// 按 schema 配置选择消息结构及编码, 见 config.KafkaSchema.
+	Formatter struct {
+		config *config.Configuration
+	}
 )
 
+// NewFormatter
+// 创建 Kafka 消息格式化.
+func NewFormatter(c *config.Configuration) adapters.LogFormatter {
+	return (&Formatter{config: c}).init()
+}
+
 // Byte
 // 转成Byte字符集.
 func (o *Formatter) Byte(line *adapters.Line) (body []byte) {
-	v := &Data{
-		Content:        line.Text,
-		Level:          line.Level.String(),
-		Time:           line.Time.Format("2006-01-02T15:04:05.999999Z"),
-		TimeMs:         line.Time.UnixMilli(),
-		Pid:            config.Config.Pid,
-		ServiceAddr:    config.Config.Addr,
-		ServiceName:    config.Config.Name,
-		ServiceVersion: config.Config.Version,
+	c := o.config.Snapshot()
+	switch c.LogAdapterKafka.Schema.Encoding {
+	case config.KafkaEncodingAvro:
+		return encodeAvro(adapters.NewLogData(c, line))
+	case config.KafkaEncodingProtobuf:
+		return encodeProto(adapters.NewLogData(c, line))
 	}
 
-	// 关键字段.
-	if line.Attr.Count() > 0 {
-		v.Keywords = line.Attr
-	}
-
-	// 调用链路.
-	if line.Tracer {
-		v.ParentSpanId = line.ParentSpanId
-		v.SpanId = line.SpanId
-		v.TraceId = line.TraceId
-	}
-
-	if buf, err := json.Marshal(v); err == nil {
+	if buf, err := newRecord(c, line).Json(); err == nil {
 		return buf
 	}
-
 	return nil
 }
 
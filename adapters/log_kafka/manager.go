@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"github.com/Shopify/sarama"
 	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/adapters/spool"
 	"github.com/go-wares/log/base"
 	"github.com/go-wares/log/config"
 	"sync"
@@ -33,18 +34,36 @@ type (
 	// 发送用户日志到Kafka.
 	Manager struct {
 		bucket    *adapters.Bucket
+		closed    bool
+		config    *config.Configuration
+		factory   ProducerFactory
+		fixed     bool
 		formatter adapters.LogFormatter
+		handler   ErrorHandler
 		keeper    base.Keeper
 		mu        sync.RWMutex
 		name      string
-		producer  sarama.SyncProducer
+		producer  Producer
+		saveMu    sync.Mutex
+		saving    sync.WaitGroup
+		spool     *spool.Spool
 	}
 )
 
 func New() adapters.LogAdapter {
-	return (&Manager{}).init()
+	return NewWithConfig(config.Config)
 }
 
+// NewWithConfig
+// 基于指定配置创建适配器.
+func NewWithConfig(c *config.Configuration) adapters.LogAdapter {
+	return (&Manager{config: c}).init()
+}
+
+// Dropped
+// 数据桶溢出时丢弃的日志数量.
+func (o *Manager) Dropped() uint64 { return o.bucket.Dropped() }
+
 func (o *Manager) Keeper() base.Keeper { return o.keeper }
 
 // Send
@@ -52,33 +71,85 @@ func (o *Manager) Keeper() base.Keeper { return o.keeper }
 //
 // 若数据桶积压数量超过指定值时, 立即刷盘保存.
 func (o *Manager) Send(line *adapters.Line) {
-	if n := o.bucket.Add(line); n >= config.Config.LogAdapterKafka.Batch {
-		go o.save()
+	if n := o.bucket.Add(line); n >= o.config.Snapshot().LogAdapterKafka.Batch {
+		o.saveAsync()
 	}
 }
 
+// SetErrorHandler
+// 设置投递失败回调.
+//
+// 消息发送失败且无法写入暂存(未启用或已满)时逐条回调; 默认打印到标准错误.
+func (o *Manager) SetErrorHandler(handler ErrorHandler) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.handler = handler
+}
+
 // SetFormatter
 // 设置格式.
 func (o *Manager) SetFormatter(formatter adapters.LogFormatter) {
 	o.formatter = formatter
 }
 
+// SetProducer
+// 设置生产者.
+//
+// 替代按配置创建的生产者, 如: NewMemoryProducer(); 由调用方负责关闭.
+func (o *Manager) SetProducer(producer Producer) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.fixed, o.producer = true, producer
+}
+
+// SetProducerFactory
+// 设置生产者工厂.
+//
+// 替代按配置创建生产者的过程, 创建的生产者由适配器在退出时关闭.
+func (o *Manager) SetProducerFactory(factory ProducerFactory) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.factory = factory
+}
+
 // +---------------------------------------------------------------------------+
 // | Event methods                                                             |
 // +---------------------------------------------------------------------------+
 
 func (o *Manager) onAfter(ctx context.Context) (ignored bool) {
-	if o.bucket.Count() > 0 {
+	// 1. 退出暂存.
+	//    重放使用生产者, 须在关闭前结束.
+	o.spool.Keeper().Stop()
+	for !o.spool.Keeper().Stopped() {
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	// 2. 等待发送.
+	o.saving.Wait()
+
+	// 3. 清空数据桶.
+	for o.bucket.Count() > 0 {
 		o.save()
-		return o.onAfter(ctx)
 	}
+
+	// 4. 关闭生产者.
+	o.closeProducer()
+	return
+}
+
+func (o *Manager) onBefore(ctx context.Context) (ignored bool) {
+	// 重新启动.
+	// 退出时关闭的生产者在下次发送时重建.
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.closed = false
 	return
 }
 
 func (o *Manager) onListen(ctx context.Context) (ignored bool) {
 	// 1. 定时保存.
 	//    每隔指定时长(默认: 350ms)上报一次日志.
-	ticker := time.NewTicker(time.Duration(config.Config.LogAdapterKafka.Milliseconds) * time.Millisecond)
+	ticker := time.NewTicker(time.Duration(o.config.Snapshot().LogAdapterKafka.Milliseconds) * time.Millisecond)
 
 	// 2. 关闭定时.
 	defer ticker.Stop()
@@ -87,7 +158,7 @@ func (o *Manager) onListen(ctx context.Context) (ignored bool) {
 	for {
 		select {
 		case <-ticker.C:
-			go o.save()
+			o.saveAsync()
 		case <-ctx.Done():
 			return
 		}
@@ -99,16 +170,99 @@ func (o *Manager) onListen(ctx context.Context) (ignored bool) {
 // +---------------------------------------------------------------------------+
 
 func (o *Manager) init() *Manager {
-	o.bucket = adapters.NewBucket()
-	o.formatter = (&Formatter{}).init()
+	o.bucket = adapters.NewBoundedBucket(func() *config.Bucket { return &o.config.Snapshot().LogAdapterKafka.Bucket })
+	o.formatter = (&Formatter{config: o.config}).init()
 	o.name = fmt.Sprintf("log-kafka-manager")
 	o.keeper = base.NewKeeper(o.name).
 		After(o.onAfter).
+		Before(o.onBefore).
 		Listen(o.onListen)
+
+	// 校验配置.
+	// 启动时打印无效的生产者配置, 如: 证书无法加载.
+	if _, err := NewProducerConfig(o.config.Snapshot().LogAdapterKafka); err != nil {
+		_, _ = fmt.Fprintf(base.Stderr(), "%s: %v\n", o.name, err)
+	}
+
+	// 磁盘暂存.
+	// 作为子 Keeper 在后台重放发送失败的批次.
+	o.spool = spool.New("log-kafka-spool", func() *config.Spool { return &o.config.Snapshot().LogAdapterKafka.Spool }, o.replay)
+	o.keeper.Add(o.spool.Keeper())
 	return o
 }
 
-func (o *Manager) getProducer() (sarama.SyncProducer, error) {
+// 关闭生产者.
+//
+// 异步模式下等待队列中的消息发送完成; 经 SetProducer 设置的生产者不关闭.
+// 关闭后不再创建生产者. 关闭时不持有锁, 剩余的失败消息经 fail 回调时须读锁.
+func (o *Manager) closeProducer() {
+	producer := func() Producer {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+
+		o.closed = true
+		if o.fixed {
+			return nil
+		}
+		p := o.producer
+		o.producer = nil
+		return p
+	}()
+
+	if producer != nil {
+		if err := producer.Close(); err != nil {
+			_, _ = fmt.Fprintf(base.Stderr(), "%s: %v\n", o.name, err)
+		}
+	}
+}
+
+// 投递消息.
+//
+// 存在未重放的批次时直接写入暂存, 保证顺序; 发送失败时仅暂存失败的消息.
+func (o *Manager) deliver(msg []*sarama.ProducerMessage) {
+	// 1. 顺序写入.
+	if o.spool.Pending() {
+		if err := o.spool.Write(spoolRecords(msg)); err != nil {
+			o.notify(producerErrors(msg, err))
+		}
+		return
+	}
+
+	// 2. 发送消息.
+	producer, err := o.getProducer()
+	if err == nil {
+		err = producer.Send(msg)
+	}
+
+	// 3. 发送失败.
+	if err != nil {
+		o.fail(producerErrors(msg, err))
+	}
+}
+
+// 发送失败.
+// 写入暂存, 恢复后重放; 未启用或写入失败时回调.
+func (o *Manager) fail(errs sarama.ProducerErrors) {
+	msg := make([]*sarama.ProducerMessage, 0, len(errs))
+	for _, e := range errs {
+		msg = append(msg, e.Msg)
+	}
+
+	se := o.spool.Write(spoolRecords(msg))
+	if se == nil {
+		return
+	}
+	if se != spool.ErrDisabled {
+		for _, e := range errs {
+			e.Err = fmt.Errorf("%v, %v", e.Err, se)
+		}
+	}
+	o.notify(errs)
+}
+
+func (o *Manager) getProducer() (Producer, error) {
+	k := o.config.Snapshot().LogAdapterKafka
+
 	o.mu.Lock()
 	defer o.mu.Unlock()
 
@@ -117,36 +271,83 @@ func (o *Manager) getProducer() (sarama.SyncProducer, error) {
 		return o.producer, nil
 	}
 
-	// 准备连接.
-	var (
-		c   = sarama.NewConfig()
-		err error
-	)
+	// 已经关闭.
+	// 适配器退出后不再创建连接, 重新启动后恢复.
+	if o.closed {
+		return nil, fmt.Errorf("%s: producer closed", o.name)
+	}
 
-	// 超时配置.
-	c.Net.MaxOpenRequests = config.Config.LogAdapterKafka.ProducerMaxRequest
-	c.Net.DialTimeout = time.Duration(config.Config.LogAdapterKafka.ProducerTimeout) * time.Second
-	c.Net.ReadTimeout = time.Duration(config.Config.LogAdapterKafka.ProducerTimeout) * time.Second
-	c.Net.WriteTimeout = time.Duration(config.Config.LogAdapterKafka.ProducerTimeout) * time.Second
+	// 创建连接.
+	factory := o.factory
+	if factory == nil {
+		factory = newProducer
+	}
+	p, err := factory(k, func(msg *sarama.ProducerMessage, err error) {
+		o.fail(sarama.ProducerErrors{{Msg: msg, Err: err}})
+	})
+	if err != nil {
+		return nil, err
+	}
+	o.producer = p
+	return o.producer, nil
+}
 
-	// 生产者配置.
-	c.Producer.RequiredAcks = sarama.NoResponse
-	c.Producer.Timeout = time.Duration(config.Config.LogAdapterKafka.ProducerTimeout) * time.Second
-	c.Producer.Retry.Max = config.Config.LogAdapterKafka.ProducerRetry
-	c.Producer.Retry.Backoff = 300 * time.Millisecond
-	c.Producer.Return.Errors = true
-	c.Producer.Return.Successes = true
-	c.Producer.CompressionLevel = sarama.CompressionLevelDefault
+// 回调失败.
+func (o *Manager) notify(errs sarama.ProducerErrors) {
+	o.mu.RLock()
+	handler := o.handler
+	o.mu.RUnlock()
 
-	// 其它配置
-	c.ChannelBufferSize = config.Config.LogAdapterKafka.ProducerBufferSize
-	o.producer, err = sarama.NewSyncProducer(config.Config.LogAdapterKafka.Host, c)
-	return o.producer, err
+	k := o.config.Snapshot().LogAdapterKafka
+	for _, e := range errs {
+		if handler != nil {
+			handler(e.Msg, e.Err)
+			continue
+		}
+		_, _ = fmt.Fprintf(base.Stderr(), "%v topic: %s, host: %v\n",
+			e.Err,
+			k.Topic,
+			k.Host,
+		)
+	}
 }
 
+// 重放暂存.
+// 按暂存时的主题发送(未记录时使用当前配置的主题), 部分失败时整批重试(至少一次).
+func (o *Manager) replay(records []spool.Record) error {
+	producer, err := o.getProducer()
+	if err != nil {
+		return err
+	}
+
+	msg := make([]*sarama.ProducerMessage, 0, len(records))
+	for _, rec := range records {
+		m := &sarama.ProducerMessage{
+			Topic: rec.Topic,
+			Value: sarama.ByteEncoder(rec.Value),
+		}
+		if m.Topic == "" {
+			m.Topic = o.config.Snapshot().LogAdapterKafka.Topic
+		}
+		if len(rec.Key) > 0 {
+			m.Key = sarama.ByteEncoder(rec.Key)
+		}
+		for _, h := range rec.Headers {
+			m.Headers = append(m.Headers, sarama.RecordHeader{Key: h.Key, Value: h.Value})
+		}
+		msg = append(msg, m)
+	}
+	return producer.Send(msg)
+}
+
+// 保存日志.
+// 串行执行, 批次按出桶顺序发送.
 func (o *Manager) save() {
+	o.saveMu.Lock()
+	defer o.saveMu.Unlock()
+
 	var (
-		list, count = o.bucket.Popn(config.Config.LogAdapterKafka.Batch)
+		list, count = o.bucket.Popn(o.config.Snapshot().LogAdapterKafka.Batch)
 		writer      *Writer
 	)
 
@@ -172,3 +373,32 @@ func (o *Manager) save() {
 	writer = NewWriter()
 	writer.Send(o, list)
 }
+
+// 后台保存.
+// 由 onAfter 等待完成.
+func (o *Manager) saveAsync() {
+	o.saving.Add(1)
+	go func() {
+		defer o.saving.Done()
+		o.save()
+	}()
+}
+
+// 转为暂存记录.
+func spoolRecords(msg []*sarama.ProducerMessage) []spool.Record {
+	records := make([]spool.Record, 0, len(msg))
+	for _, m := range msg {
+		rec := spool.Record{Topic: m.Topic}
+		if m.Key != nil {
+			rec.Key, _ = m.Key.Encode()
+		}
+		if m.Value != nil {
+			rec.Value, _ = m.Value.Encode()
+		}
+		for _, h := range m.Headers {
+			rec.Headers = append(rec.Headers, spool.Header{Key: h.Key, Value: h.Value})
+		}
+		records = append(records, rec)
+	}
+	return records
+}
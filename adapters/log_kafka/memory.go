@@ -0,0 +1,110 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-06-07
+
+package log_kafka
+
+import (
+	"github.com/Shopify/sarama"
+	"sync"
+)
+
+type (
+	// MemoryProducer
+	// 内存生产者.
+	//
+	// 记录成功发送的批次, 不连接集群; 用于测试适配器的批量、顺序及重试.
+	//
+	//   p := log_kafka.NewMemoryProducer()
+	//   adapter.(*log_kafka.Manager).SetProducer(p)
+	MemoryProducer struct {
+		batches [][]*sarama.ProducerMessage
+		closed  bool
+		fail    int
+		failErr error
+		mu      sync.Mutex
+	}
+)
+
+// NewMemoryProducer
+// 创建内存生产者.
+func NewMemoryProducer() *MemoryProducer {
+	return &MemoryProducer{batches: make([][]*sarama.ProducerMessage, 0)}
+}
+
+// Batches
+// 成功发送的批次.
+func (o *MemoryProducer) Batches() [][]*sarama.ProducerMessage {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([][]*sarama.ProducerMessage(nil), o.batches...)
+}
+
+// Closed
+// 是否已关闭.
+func (o *MemoryProducer) Closed() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.closed
+}
+
+// FailNext
+// 后续 n 次发送失败.
+//
+// 失败时整批返回 sarama.ProducerErrors, 每条消息的错误均为 err.
+func (o *MemoryProducer) FailNext(n int, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.fail, o.failErr = n, err
+}
+
+// Messages
+// 成功发送的消息.
+//
+// 按发送顺序展开全部批次.
+func (o *MemoryProducer) Messages() []*sarama.ProducerMessage {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	list := make([]*sarama.ProducerMessage, 0)
+	for _, batch := range o.batches {
+		list = append(list, batch...)
+	}
+	return list
+}
+
+// +---------------------------------------------------------------------------+
+// | Interface methods                                                         |
+// +---------------------------------------------------------------------------+
+
+func (o *MemoryProducer) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.closed = true
+	return nil
+}
+
+func (o *MemoryProducer) Send(msg []*sarama.ProducerMessage) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// 1. 模拟失败.
+	if o.fail > 0 {
+		o.fail--
+		return producerErrors(msg, o.failErr)
+	}
+
+	// 2. 记录批次.
+	o.batches = append(o.batches, append([]*sarama.ProducerMessage(nil), msg...))
+	return nil
+}
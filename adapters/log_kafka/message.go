@@ -0,0 +1,76 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-06-06
+
+package log_kafka
+
+import (
+	"fmt"
+	"github.com/Shopify/sarama"
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/config"
+)
+
+var (
+	headerLevel   = []byte("level")
+	headerService = []byte("service")
+	headerSpanId  = []byte("span_id")
+	headerTraceId = []byte("trace_id")
+)
+
+// NewMessage
+// 构建消息.
+//
+// 按主题路由选择主题, 按分区键策略设置消息键, 开启消息头时附加级别、服务名
+// 及链路标识; body 为已格式化的消息体.
+func NewMessage(c *config.Configuration, line *adapters.Line, body []byte) *sarama.ProducerMessage {
+	c = c.Snapshot()
+	var (
+		k   = c.LogAdapterKafka
+		msg = &sarama.ProducerMessage{Topic: topic(c, line), Value: sarama.ByteEncoder(body)}
+	)
+
+	// 1. 消息键.
+	//    键为空时由分区器随机或轮询选择分区.
+	if key := partitionKey(c, line); key != "" {
+		msg.Key = sarama.StringEncoder(key)
+	}
+
+	// 2. 消息头.
+	if *k.Headers {
+		msg.Headers = []sarama.RecordHeader{
+			{Key: headerLevel, Value: []byte(line.Level.String())},
+			{Key: headerService, Value: []byte(c.Name)},
+			{Key: headerTraceId, Value: []byte(line.TraceId)},
+			{Key: headerSpanId, Value: []byte(line.SpanId)},
+		}
+	}
+	return msg
+}
+
+// 分区键.
+func partitionKey(c *config.Configuration, line *adapters.Line) string {
+	k := c.LogAdapterKafka
+	switch k.PartitionKey {
+	case config.KafkaPartitionRoundRobin:
+		return ""
+	case config.KafkaPartitionService:
+		return c.Name
+	case config.KafkaPartitionField:
+		if v, ok := line.FieldValue(k.PartitionField); ok {
+			return fmt.Sprint(v)
+		}
+	}
+	return line.TraceId
+}
@@ -0,0 +1,146 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-06-05
+
+package log_kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/Shopify/sarama"
+	"github.com/go-wares/log/config"
+	"os"
+	"time"
+)
+
+var (
+	producerAcks = map[config.KafkaAcks]sarama.RequiredAcks{
+		config.KafkaAcksAll:    sarama.WaitForAll,
+		config.KafkaAcksLeader: sarama.WaitForLocal,
+		config.KafkaAcksNone:   sarama.NoResponse,
+	}
+
+	producerCompression = map[config.KafkaCompression]sarama.CompressionCodec{
+		config.KafkaCompressionGzip:   sarama.CompressionGZIP,
+		config.KafkaCompressionLz4:    sarama.CompressionLZ4,
+		config.KafkaCompressionNone:   sarama.CompressionNone,
+		config.KafkaCompressionSnappy: sarama.CompressionSnappy,
+		config.KafkaCompressionZstd:   sarama.CompressionZSTD,
+	}
+)
+
+// NewProducerConfig
+// 生产者配置.
+//
+// 按适配器配置构建 sarama 配置, 加载 TLS 证书并设置 SASL 认证; 返回前经
+// sarama 校验.
+func NewProducerConfig(k *config.LogAdapterKafka) (*sarama.Config, error) {
+	var (
+		c       = sarama.NewConfig()
+		err     error
+		timeout = time.Duration(k.ProducerTimeout) * time.Second
+	)
+
+	// 1. 集群版本.
+	if c.Version, err = sarama.ParseKafkaVersion(k.Version); err != nil {
+		return nil, err
+	}
+	if k.ClientId != "" {
+		c.ClientID = k.ClientId
+	}
+
+	// 2. 超时配置.
+	c.Net.MaxOpenRequests = k.ProducerMaxRequest
+	c.Net.DialTimeout = timeout
+	c.Net.ReadTimeout = timeout
+	c.Net.WriteTimeout = timeout
+
+	// 3. 生产者配置.
+	c.Producer.RequiredAcks = producerAcks[k.Acks]
+	c.Producer.Timeout = timeout
+	c.Producer.Retry.Max = k.ProducerRetry
+	c.Producer.Retry.Backoff = 300 * time.Millisecond
+	c.Producer.Return.Errors = true
+	c.Producer.Return.Successes = k.Mode != config.KafkaModeAsync
+	c.Producer.Compression = producerCompression[k.Compression]
+	c.Producer.CompressionLevel = sarama.CompressionLevelDefault
+	if k.PartitionKey == config.KafkaPartitionRoundRobin {
+		c.Producer.Partitioner = sarama.NewRoundRobinPartitioner
+	}
+
+	// 4. 连接加密.
+	if k.Tls.Enable {
+		if c.Net.TLS.Config, err = producerTls(&k.Tls); err != nil {
+			return nil, err
+		}
+		c.Net.TLS.Enable = true
+	}
+
+	// 5. 身份认证.
+	if k.Sasl.Mechanism != "" {
+		c.Net.SASL.Enable = true
+		c.Net.SASL.Handshake = true
+		c.Net.SASL.Mechanism = sarama.SASLMechanism(k.Sasl.Mechanism)
+		c.Net.SASL.User = k.Sasl.Username
+		c.Net.SASL.Password = k.Sasl.Password
+
+		switch k.Sasl.Mechanism {
+		case config.KafkaSaslScramSha256:
+			c.Net.SASL.SCRAMClientGeneratorFunc = newScramSha256()
+		case config.KafkaSaslScramSha512:
+			c.Net.SASL.SCRAMClientGeneratorFunc = newScramSha512()
+		}
+		if c.Version.IsAtLeast(sarama.V1_0_0_0) {
+			c.Net.SASL.Version = sarama.SASLHandshakeV1
+		}
+	}
+
+	// 6. 其它配置.
+	c.ChannelBufferSize = k.ProducerBufferSize
+	if err = c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// TLS 配置.
+func producerTls(t *config.KafkaTls) (*tls.Config, error) {
+	c := &tls.Config{
+		InsecureSkipVerify: t.InsecureSkipVerify,
+		ServerName:         t.ServerName,
+	}
+
+	// 1. 根证书.
+	if t.CaFile != "" {
+		body, err := os.ReadFile(t.CaFile)
+		if err != nil {
+			return nil, err
+		}
+		c.RootCAs = x509.NewCertPool()
+		if !c.RootCAs.AppendCertsFromPEM(body) {
+			return nil, fmt.Errorf("tls: no certificate found in %s", t.CaFile)
+		}
+	}
+
+	// 2. 客户端证书.
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		c.Certificates = []tls.Certificate{cert}
+	}
+	return c, nil
+}
@@ -0,0 +1,128 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-06-11
+
+package log_kafka
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	// 映射为空.
+	// RFC 3454 B.1.
+	saslprepNothing = &unicode.RangeTable{
+		R16: []unicode.Range16{
+			{Lo: 0x00ad, Hi: 0x00ad, Stride: 1},
+			{Lo: 0x034f, Hi: 0x034f, Stride: 1},
+			{Lo: 0x1806, Hi: 0x1806, Stride: 1},
+			{Lo: 0x180b, Hi: 0x180d, Stride: 1},
+			{Lo: 0x200b, Hi: 0x200d, Stride: 1},
+			{Lo: 0x2060, Hi: 0x2060, Stride: 1},
+			{Lo: 0xfe00, Hi: 0xfe0f, Stride: 1},
+			{Lo: 0xfeff, Hi: 0xfeff, Stride: 1},
+		},
+	}
+
+	// 非 ASCII 空格, 映射为空格.
+	// RFC 3454 C.1.2.
+	saslprepSpace = &unicode.RangeTable{
+		R16: []unicode.Range16{
+			{Lo: 0x00a0, Hi: 0x00a0, Stride: 1},
+			{Lo: 0x1680, Hi: 0x1680, Stride: 1},
+			{Lo: 0x2000, Hi: 0x200b, Stride: 1},
+			{Lo: 0x202f, Hi: 0x202f, Stride: 1},
+			{Lo: 0x205f, Hi: 0x205f, Stride: 1},
+			{Lo: 0x3000, Hi: 0x3000, Stride: 1},
+		},
+	}
+
+	// 禁用字符.
+	// RFC 3454 C.2.1 - C.9, 非字符码点(C.4)另行判断.
+	saslprepProhibited = &unicode.RangeTable{
+		R16: []unicode.Range16{
+			{Lo: 0x0000, Hi: 0x001f, Stride: 1},
+			{Lo: 0x007f, Hi: 0x009f, Stride: 1},
+			{Lo: 0x0340, Hi: 0x0341, Stride: 1},
+			{Lo: 0x06dd, Hi: 0x06dd, Stride: 1},
+			{Lo: 0x070f, Hi: 0x070f, Stride: 1},
+			{Lo: 0x180e, Hi: 0x180e, Stride: 1},
+			{Lo: 0x200c, Hi: 0x200f, Stride: 1},
+			{Lo: 0x2028, Hi: 0x202e, Stride: 1},
+			{Lo: 0x2060, Hi: 0x2063, Stride: 1},
+			{Lo: 0x206a, Hi: 0x206f, Stride: 1},
+			{Lo: 0x2ff0, Hi: 0x2ffb, Stride: 1},
+			{Lo: 0xd800, Hi: 0xf8ff, Stride: 1},
+			{Lo: 0xfdd0, Hi: 0xfdef, Stride: 1},
+			{Lo: 0xfeff, Hi: 0xfeff, Stride: 1},
+			{Lo: 0xfff9, Hi: 0xfffd, Stride: 1},
+		},
+		R32: []unicode.Range32{
+			{Lo: 0x1d173, Hi: 0x1d17a, Stride: 1},
+			{Lo: 0xe0001, Hi: 0xe0001, Stride: 1},
+			{Lo: 0xe0020, Hi: 0xe007f, Stride: 1},
+			{Lo: 0xf0000, Hi: 0x10ffff, Stride: 1},
+		},
+	}
+)
+
+// SASLprep.
+//
+// 按 RFC 4013 处理用户名及密码: 映射非 ASCII 空格及可忽略字符, 拒绝控制字符、
+// 私有区及非字符码点等禁用字符. 标准库未提供 NFKC 规范化及双向属性, 不做规范
+// 化及双向校验, 非 ASCII 字符须为规范化形式.
+func saslprep(s string) (string, error) {
+	// 1. ASCII 可打印字符.
+	//    无须处理.
+	if saslprepAscii(s) {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i, r := range s {
+		// 2. 无效字符.
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(s[i:]); size == 1 {
+				return "", fmt.Errorf("invalid utf-8 at offset %d", i)
+			}
+		}
+
+		// 3. 映射字符.
+		switch {
+		case unicode.Is(saslprepNothing, r):
+			continue
+		case unicode.Is(saslprepSpace, r):
+			r = ' '
+		}
+
+		// 4. 禁用字符.
+		if unicode.Is(saslprepProhibited, r) || r&0xfffe == 0xfffe {
+			return "", fmt.Errorf("prohibited character %U", r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), nil
+}
+
+func saslprepAscii(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] >= 0x7f {
+			return false
+		}
+	}
+	return true
+}
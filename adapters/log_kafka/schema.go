@@ -0,0 +1,299 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-06-09
+
+package log_kafka
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// ECS 版本.
+	ecsVersion = "1.6.0"
+
+	// GELF 版本.
+	gelfVersion = "1.1"
+)
+
+var (
+	// GELF 附加字段名.
+	gelfKey = regexp.MustCompile(`[^\w.\-]`)
+
+	// GELF 级别.
+	// 按 syslog 严重程度.
+	gelfLevel = map[base.LogLevel]int{
+		base.Debug: 7,
+		base.Info:  6,
+		base.Warn:  4,
+		base.Error: 3,
+		base.Fatal: 2,
+	}
+)
+
+type (
+	// 有序字段.
+	// 按添加顺序编码, 消息体字段顺序稳定.
+	record []recordField
+
+	recordField struct {
+		key   string
+		value interface{}
+	}
+)
+
+// 按结构转换.
+func newRecord(c *config.Configuration, line *adapters.Line) record {
+	var (
+		d = adapters.NewLogData(c, line)
+		s = c.LogAdapterKafka.Schema
+		r record
+	)
+
+	switch s.Layout {
+	case config.KafkaLayoutEcs:
+		r = newEcsRecord(d, s.Flatten)
+	case config.KafkaLayoutGelf:
+		r = newGelfRecord(d, line.Level)
+	default:
+		r = newDefaultRecord(d, s.Flatten)
+	}
+	return r.rename(s.Rename)
+}
+
+// +---------------------------------------------------------------------------+
+// | Layouts                                                                   |
+// +---------------------------------------------------------------------------+
+
+// 默认结构.
+// 字段及顺序与 adapters.LogData 一致.
+func newDefaultRecord(d *adapters.LogData, flatten bool) record {
+	r := make(record, 0, 16)
+	r = r.add("content", d.Content)
+	r = r.add("fields", nil)
+	r = r.add("level", d.Level)
+	r = r.add("time", d.Time)
+	r = r.add("time_ms", d.TimeMs)
+	r = r.addString("parent_span_id", d.ParentSpanId)
+	r = r.addString("span_id", d.SpanId)
+	r = r.addString("trace_id", d.TraceId)
+	r = r.addString("request_method", d.RequestMethod)
+	r = r.addString("request_url", d.RequestUrl)
+	r = r.addString("user_agent", d.UserAgent)
+	r = r.add("pid", d.Pid)
+	if len(d.ServiceAddr) > 0 {
+		r = r.add("service_addr", d.ServiceAddr)
+	}
+	r = r.add("service_name", d.ServiceName)
+	r = r.add("service_version", d.ServiceVersion)
+	return r.fields(1, d.Keywords, flatten)
+}
+
+// ECS 结构.
+// 字段见 Elastic Common Schema, 日志字段写入 labels.
+func newEcsRecord(d *adapters.LogData, flatten bool) record {
+	r := make(record, 0, 16)
+	r = r.add("@timestamp", time.UnixMilli(d.TimeMs).UTC().Format("2006-01-02T15:04:05.000Z"))
+	r = r.add("log.level", strings.ToLower(d.Level))
+	r = r.add("message", d.Content)
+	r = r.add("labels", nil)
+	r = r.add("ecs.version", ecsVersion)
+	r = r.add("service.name", d.ServiceName)
+	r = r.addString("service.version", d.ServiceVersion)
+	r = r.add("process.pid", d.Pid)
+	if len(d.ServiceAddr) > 0 {
+		r = r.add("host.ip", d.ServiceAddr)
+	}
+	r = r.addString("trace.id", d.TraceId)
+	r = r.addString("span.id", d.SpanId)
+	r = r.addString("http.request.method", d.RequestMethod)
+	r = r.addString("url.path", d.RequestUrl)
+	r = r.addString("user_agent.original", d.UserAgent)
+	return r.fields(3, d.Keywords, flatten)
+}
+
+// GELF 结构.
+//
+// 自定义字段以下划线开头, 值仅支持字符串及数值; 日志字段始终展开, 与内置
+// 字段同名或为 id 时以 _field_ 开头.
+func newGelfRecord(d *adapters.LogData, level base.LogLevel) record {
+	r := make(record, 0, 16)
+	r = r.add("version", gelfVersion)
+	r = r.add("host", d.ServiceName)
+
+	// 1. 消息内容.
+	//    首行作为短消息, 多行时附加完整消息.
+	if i := strings.IndexByte(d.Content, '\n'); i >= 0 {
+		r = r.add("short_message", d.Content[:i])
+		r = r.add("full_message", d.Content)
+	} else {
+		r = r.add("short_message", d.Content)
+	}
+	r = r.add("timestamp", float64(d.TimeMs)/1000)
+	r = r.add("level", gelfLevel[level])
+
+	// 2. 内置字段.
+	r = r.addString("_service_version", d.ServiceVersion)
+	r = r.add("_pid", d.Pid)
+	if len(d.ServiceAddr) > 0 {
+		r = r.add("_service_addr", strings.Join(d.ServiceAddr, ","))
+	}
+	r = r.addString("_trace_id", d.TraceId)
+	r = r.addString("_span_id", d.SpanId)
+	r = r.addString("_parent_span_id", d.ParentSpanId)
+	r = r.addString("_request_method", d.RequestMethod)
+	r = r.addString("_request_url", d.RequestUrl)
+	r = r.addString("_user_agent", d.UserAgent)
+
+	// 3. 日志字段.
+	for _, k := range sortedKeys(d.Keywords) {
+		key := "_" + gelfKey.ReplaceAllString(k, "_")
+		if key == "_id" || r.has(key) {
+			key = "_field_" + key[1:]
+		}
+		r = r.add(key, gelfValue(d.Keywords[k]))
+	}
+	return r
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+func (o record) add(key string, value interface{}) record {
+	return append(o, recordField{key: key, value: value})
+}
+
+// 非空字符串.
+func (o record) addString(key, value string) record {
+	if value == "" {
+		return o
+	}
+	return o.add(key, value)
+}
+
+// 日志字段.
+//
+// 写入第 i 个字段(占位); 展开时与已有字段不同名的追加到末尾, 其余保留在
+// 原位置, 全部展开时移除占位.
+func (o record) fields(i int, fields map[string]interface{}, flatten bool) record {
+	if flatten && len(fields) > 0 {
+		nested := make(map[string]interface{})
+		for _, k := range sortedKeys(fields) {
+			if o.has(k) {
+				nested[k] = fields[k]
+				continue
+			}
+			o = o.add(k, fields[k])
+		}
+		fields = nested
+	}
+	if len(fields) == 0 {
+		return append(o[:i], o[i+1:]...)
+	}
+	o[i].value = fields
+	return o
+}
+
+func (o record) has(key string) bool {
+	for _, f := range o {
+		if f.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Json
+// 按字段顺序编码.
+func (o record) Json() ([]byte, error) {
+	b := make([]byte, 0, 512)
+	b = append(b, '{')
+	for i, f := range o {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		key, _ := json.Marshal(f.key)
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, key...)
+		b = append(b, ':')
+		b = append(b, value...)
+	}
+	return append(b, '}'), nil
+}
+
+// 字段重命名.
+//
+// 新字段名与未重命名的字段同名时(如展开的日志字段)不重命名, 避免重复的键;
+// 与内置字段同名已由配置校验拒绝.
+func (o record) rename(names map[string]string) record {
+	if len(names) == 0 {
+		return o
+	}
+
+	kept := make(map[string]bool, len(o))
+	for _, f := range o {
+		if _, ok := names[f.key]; !ok {
+			kept[f.key] = true
+		}
+	}
+	for i, f := range o {
+		if to, ok := names[f.key]; ok && !kept[to] {
+			o[i].key = to
+		}
+	}
+	return o
+}
+
+// GELF 字段值.
+// 非字符串及数值的转为字符串.
+func gelfValue(v interface{}) interface{} {
+	switch v.(type) {
+	case string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	}
+	return stringValue(v)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// 转为字符串.
+// 字符串原样返回, 其它按 json 编码.
+func stringValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	if buf, err := json.Marshal(v); err == nil {
+		return string(buf)
+	}
+	return fmt.Sprint(v)
+}
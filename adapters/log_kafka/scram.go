@@ -0,0 +1,222 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-06-05
+
+package log_kafka
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/Shopify/sarama"
+	"hash"
+	"strconv"
+	"strings"
+)
+
+const (
+	// 最少迭代次数.
+	scramMinIterations = 4096
+)
+
+type (
+	// SCRAM 客户端.
+	//
+	// 按 RFC 5802 实现 SCRAM-SHA-256 及 SCRAM-SHA-512 认证, 不支持通道绑定;
+	// 用户名及密码经 SASLprep 处理, 迭代次数不少于 4096 次(RFC 7677).
+	scramClient struct {
+		authMessage     string
+		clientFirstBare string
+		done            bool
+		hash            func() hash.Hash
+		nonce           string
+		password        string
+		serverSignature []byte
+		step            int
+		username        string
+	}
+)
+
+func newScramClient(h func() hash.Hash) func() sarama.SCRAMClient {
+	return func() sarama.SCRAMClient { return &scramClient{hash: h} }
+}
+
+func newScramSha256() func() sarama.SCRAMClient { return newScramClient(sha256.New) }
+func newScramSha512() func() sarama.SCRAMClient { return newScramClient(sha512.New) }
+
+// +---------------------------------------------------------------------------+
+// | Interface methods                                                         |
+// +---------------------------------------------------------------------------+
+
+func (o *scramClient) Begin(username, password, _ string) (err error) {
+	if username, err = saslprep(username); err != nil {
+		return fmt.Errorf("scram: invalid username: %v", err)
+	}
+	if password, err = saslprep(password); err != nil {
+		return fmt.Errorf("scram: invalid password: %v", err)
+	}
+
+	buf := make([]byte, 24)
+	if _, err = rand.Read(buf); err != nil {
+		return err
+	}
+
+	o.nonce = base64.RawStdEncoding.EncodeToString(buf)
+	o.password = password
+	o.username = username
+	o.done, o.step = false, 0
+	return nil
+}
+
+func (o *scramClient) Done() bool { return o.done }
+
+func (o *scramClient) Step(challenge string) (string, error) {
+	o.step++
+	switch o.step {
+	case 1:
+		return o.clientFirst(), nil
+	case 2:
+		return o.clientFinal(challenge)
+	case 3:
+		return "", o.serverFinal(challenge)
+	}
+	return "", errors.New("scram: unexpected step")
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+// 客户端首条消息.
+//
+//	n,,n=user,r=nonce
+func (o *scramClient) clientFirst() string {
+	name := strings.NewReplacer("=", "=3D", ",", "=2C").Replace(o.username)
+	o.clientFirstBare = "n=" + name + ",r=" + o.nonce
+	return "n,," + o.clientFirstBare
+}
+
+// 客户端最终消息.
+//
+//	c=biws,r=nonce,p=proof
+func (o *scramClient) clientFinal(serverFirst string) (string, error) {
+	var (
+		attrs = scramAttrs(serverFirst)
+		nonce = attrs["r"]
+	)
+
+	// 1. 校验参数.
+	if !strings.HasPrefix(nonce, o.nonce) || len(nonce) == len(o.nonce) {
+		return "", errors.New("scram: server nonce mismatch")
+	}
+	salt, err := base64.StdEncoding.DecodeString(attrs["s"])
+	if err != nil {
+		return "", fmt.Errorf("scram: invalid salt: %v", err)
+	}
+	iter, err := strconv.Atoi(attrs["i"])
+	if err != nil || iter <= 0 {
+		return "", fmt.Errorf("scram: invalid iteration count %q", attrs["i"])
+	}
+	if iter < scramMinIterations {
+		return "", fmt.Errorf("scram: iteration count %d below minimum %d", iter, scramMinIterations)
+	}
+
+	// 2. 计算证明.
+	var (
+		salted    = scramPbkdf2(o.hash, []byte(o.password), salt, iter)
+		clientKey = scramHmac(o.hash, salted, "Client Key")
+		storedKey = o.hash()
+	)
+	storedKey.Write(clientKey)
+
+	final := "c=biws,r=" + nonce
+	o.authMessage = o.clientFirstBare + "," + serverFirst + "," + final
+
+	proof := scramHmac(o.hash, storedKey.Sum(nil), o.authMessage)
+	for i := range proof {
+		proof[i] ^= clientKey[i]
+	}
+
+	// 3. 服务端签名.
+	//    用于校验服务端最终消息.
+	o.serverSignature = scramHmac(o.hash, scramHmac(o.hash, salted, "Server Key"), o.authMessage)
+	return final + ",p=" + base64.StdEncoding.EncodeToString(proof), nil
+}
+
+// 校验服务端最终消息.
+//
+//	v=signature
+func (o *scramClient) serverFinal(challenge string) error {
+	o.done = true
+
+	attrs := scramAttrs(challenge)
+	if e, ok := attrs["e"]; ok {
+		return fmt.Errorf("scram: server error: %s", e)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(attrs["v"])
+	if err != nil || !hmac.Equal(signature, o.serverSignature) {
+		return errors.New("scram: server signature mismatch")
+	}
+	return nil
+}
+
+// 解析属性.
+//
+//	r=abc,s=xyz,i=4096 -> {r: abc, s: xyz, i: 4096}
+func scramAttrs(s string) map[string]string {
+	attrs := make(map[string]string)
+	for _, kv := range strings.Split(s, ",") {
+		if i := strings.IndexByte(kv, '='); i > 0 {
+			attrs[kv[:i]] = kv[i+1:]
+		}
+	}
+	return attrs
+}
+
+func scramHmac(h func() hash.Hash, key []byte, s string) []byte {
+	m := hmac.New(h, key)
+	m.Write([]byte(s))
+	return m.Sum(nil)
+}
+
+// PBKDF2.
+// 输出长度与摘要长度相同, 仅计算第1块.
+func scramPbkdf2(h func() hash.Hash, password, salt []byte, iter int) []byte {
+	var (
+		m     = hmac.New(h, password)
+		index [4]byte
+	)
+
+	binary.BigEndian.PutUint32(index[:], 1)
+	m.Write(salt)
+	m.Write(index[:])
+
+	u := m.Sum(nil)
+	key := append([]byte(nil), u...)
+	for n := 1; n < iter; n++ {
+		m.Reset()
+		m.Write(u)
+		u = m.Sum(u[:0])
+		for i := range key {
+			key[i] ^= u[i]
+		}
+	}
+	return key
+}
@@ -0,0 +1,91 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-06-08
+
+package log_kafka
+
+import (
+	"fmt"
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+	"strings"
+)
+
+// 选择主题.
+//
+// 依次按字段、级别、模板选择, 首个允许的主题生效, 均无效时使用默认主题.
+func topic(c *config.Configuration, line *adapters.Line) string {
+	k := c.LogAdapterKafka
+
+	// 1. 按字段.
+	if k.TopicField != "" {
+		if v, ok := line.FieldValue(k.TopicField); ok {
+			if s := fmt.Sprint(v); k.AllowTopic(s) {
+				return s
+			}
+		}
+	}
+
+	// 2. 按级别.
+	if s, ok := k.TopicLevel[base.Level(line.Level.String())]; ok && k.AllowTopic(s) {
+		return s
+	}
+
+	// 3. 按模板.
+	if k.TopicTemplate != "" {
+		if s, ok := topicTemplate(c, line); ok && k.AllowTopic(s) {
+			return s
+		}
+	}
+	return k.Topic
+}
+
+// 展开模板.
+// 占位符对应的字段不存在或模板不完整时返回 false.
+func topicTemplate(c *config.Configuration, line *adapters.Line) (string, bool) {
+	var (
+		sb  strings.Builder
+		tpl = c.LogAdapterKafka.TopicTemplate
+	)
+
+	for {
+		// 1. 普通文本.
+		i := strings.IndexByte(tpl, '{')
+		if i < 0 {
+			sb.WriteString(tpl)
+			return sb.String(), true
+		}
+		sb.WriteString(tpl[:i])
+
+		// 2. 占位符.
+		j := strings.IndexByte(tpl[i:], '}')
+		if j < 0 {
+			return "", false
+		}
+		switch name := tpl[i+1 : i+j]; name {
+		case "service":
+			sb.WriteString(c.Name)
+		case "level":
+			sb.WriteString(strings.ToLower(line.Level.String()))
+		default:
+			v, ok := line.FieldValue(name)
+			if !ok {
+				return "", false
+			}
+			_, _ = fmt.Fprint(&sb, v)
+		}
+		tpl = tpl[i+j+1:]
+	}
+}
@@ -19,8 +19,7 @@ import (
 	"fmt"
 	"github.com/Shopify/sarama"
 	"github.com/go-wares/log/adapters"
-	"github.com/go-wares/log/config"
-	"os"
+	"github.com/go-wares/log/base"
 	"sync"
 )
 
@@ -60,32 +59,20 @@ func (o *Writer) Release() {
 // 批量发送过程..
 func (o *Writer) Send(manager *Manager, list []interface{}) {
 	var (
-		err      error
-		msg      = make([]*sarama.ProducerMessage, 0)
-		producer sarama.SyncProducer
-		buf      []byte
+		msg = make([]*sarama.ProducerMessage, 0)
+		buf []byte
 	)
 
-	// 1. 后置执行.
+	// 1. 捕获异常.
 	defer func() {
-		// 1.1 捕获异常.
 		if v := recover(); v != nil {
-			_, _ = fmt.Fprintf(os.Stderr, "%v, topic: %s, host: %v\n%s\n",
+			_, _ = fmt.Fprintf(base.Stderr(), "%v, topic: %s, host: %v\n%s\n",
 				v,
-				config.Config.LogAdapterKafka.Topic,
-				config.Config.LogAdapterKafka.Host,
+				manager.config.Snapshot().LogAdapterKafka.Topic,
+				manager.config.Snapshot().LogAdapterKafka.Host,
 				adapters.Backstack().String(),
 			)
 		}
-
-		// 1.2 打印错误.
-		if err != nil {
-			_, _ = fmt.Fprintf(os.Stderr, "%v topic: %s, host: %v\n",
-				err,
-				config.Config.LogAdapterKafka.Topic,
-				config.Config.LogAdapterKafka.Host,
-			)
-		}
 	}()
 
 	// 2. 格式消息.
@@ -97,17 +84,14 @@ func (o *Writer) Send(manager *Manager, list []interface{}) {
 			}
 
 			// 2.2 消息结构.
-			msg = append(msg, &sarama.ProducerMessage{
-				Topic: config.Config.LogAdapterKafka.Topic,
-				Value: sarama.ByteEncoder(buf),
-				Key:   sarama.StringEncoder(line.Level.String()),
-			})
+			msg = append(msg, NewMessage(manager.config, line, buf))
 		}
 	}
 
 	// 3. 发送过程.
-	if producer, err = manager.getProducer(); err == nil {
-		err = producer.SendMessages(msg)
+	//    失败时写入暂存, 恢复后重放; 无法暂存时回调.
+	if len(msg) > 0 {
+		manager.deliver(msg)
 	}
 }
 
@@ -0,0 +1,235 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-20
+
+package log_multi
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+	"sync"
+)
+
+type (
+	// Manager
+	// 组合管理器.
+	//
+	// 发送用户日志到多个适配器, 如同时打印到终端、写入文件和 Kafka. 每个适配器
+	// 独立运行, 任一适配器异常不影响其它适配器.
+	Manager struct {
+		config  *config.Configuration
+		keeper  base.Keeper
+		mu      sync.RWMutex
+		name    string
+		targets []*target
+	}
+
+	target struct {
+		adapter adapters.LogAdapter
+		name    base.LogAdapter
+	}
+)
+
+func New() *Manager {
+	return NewWithConfig(config.Config)
+}
+
+// NewWithConfig
+// 基于指定配置创建组合管理器.
+//
+// 路由规则和适配器级别读取此配置.
+func NewWithConfig(c *config.Configuration) *Manager {
+	return (&Manager{config: c}).init()
+}
+
+// Add
+// 添加适配器.
+//
+// 适配器的 Keeper 作为子 Keeper, 随组合管理器启动和退出; 运行中添加时立即启动.
+func (o *Manager) Add(name base.LogAdapter, adapter adapters.LogAdapter) *Manager {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if adapter != nil && o.keeper.Add(adapter.Keeper()) {
+		targets := make([]*target, 0, len(o.targets)+1)
+		targets = append(targets, o.targets...)
+		o.targets = append(targets, &target{adapter: adapter, name: name})
+	}
+	return o
+}
+
+// Count
+// 适配器数量.
+func (o *Manager) Count() int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return len(o.targets)
+}
+
+// Del
+// 删除适配器.
+//
+// 退出适配器的 Keeper, 退出前已加入数据桶的日志会被处理完成.
+func (o *Manager) Del(name base.LogAdapter) (yes bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	targets := make([]*target, 0, len(o.targets))
+	for _, t := range o.targets {
+		if t.name != name {
+			targets = append(targets, t)
+			continue
+		}
+
+		yes = true
+		o.keeper.Del(t.adapter.Keeper())
+		t.adapter.Keeper().Stop()
+	}
+	o.targets = targets
+	return
+}
+
+// Get
+// 获取适配器.
+func (o *Manager) Get(name base.LogAdapter) adapters.LogAdapter {
+	for _, t := range o.list() {
+		if t.name == name {
+			return t.adapter
+		}
+	}
+	return nil
+}
+
+func (o *Manager) Keeper() base.Keeper { return o.keeper }
+
+// Names
+// 适配器名称.
+func (o *Manager) Names() base.LogAdapters {
+	names := make(base.LogAdapters, 0)
+	for _, t := range o.list() {
+		names = append(names, t.name)
+	}
+	return names
+}
+
+// Send
+// 按路由规则发送.
+//
+// 未配置路由规则时发往全部适配器, 不合并字段.
+func (o *Manager) Send(line *adapters.Line) {
+	var names base.LogAdapters
+	if len(o.config.Snapshot().LogRoute) > 0 {
+		names = o.config.LogRouteMatch(line.Level, line.FieldMap(), line.SpanName)
+	}
+	o.SendTo(line, names)
+}
+
+// SendTo
+// 发送到指定适配器.
+//
+// 参数 names 为 nil 时发送到全部适配器, 且各适配器仅接收不低于自身级别的日志.
+// 日志引用计数与接收的适配器数量一致, 每个适配器处理完成后释放1次, 全部释放
+// 后回池.
+//
+// 依次调用各适配器的 Send, 单个适配器阻塞时拖慢其它适配器, 故配置多个日志适配
+// 器时校验拒绝数据桶的 block 溢出策略.
+func (o *Manager) SendTo(line *adapters.Line, names base.LogAdapters) {
+	var (
+		buf     [8]*target
+		list    = buf[:0]
+		targets = o.list()
+	)
+
+	// 1. 筛选适配器.
+	for _, t := range targets {
+		if names != nil && !names.Contains(t.name) {
+			continue
+		}
+		if o.config.LogAdapterOn(t.name, line.Level) {
+			list = append(list, t)
+		}
+	}
+
+	// 2. 无接收适配器.
+	if len(list) == 0 {
+		line.Release()
+		return
+	}
+
+	// 3. 增加引用.
+	line.Retain(len(list) - 1)
+
+	// 4. 逐个发送.
+	for _, t := range list {
+		o.dispatch(t, line)
+	}
+}
+
+// SetFormatter
+// 设置全部适配器的格式.
+func (o *Manager) SetFormatter(formatter adapters.LogFormatter) {
+	for _, t := range o.list() {
+		t.adapter.SetFormatter(formatter)
+	}
+}
+
+// +---------------------------------------------------------------------------+
+// | Event methods                                                             |
+// +---------------------------------------------------------------------------+
+
+func (o *Manager) onListen(ctx context.Context) (ignored bool) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+// 发送到单个适配器.
+//
+// 适配器异常时仅打印错误, 不释放日志引用, 避免适配器已释放时重复释放; 此时日志
+// 不再回池, 由 GC 回收.
+func (o *Manager) dispatch(t *target, line *adapters.Line) {
+	defer func() {
+		if r := recover(); r != nil {
+			_, _ = fmt.Fprintf(base.Stderr(), "%s send to %s: %v\n", o.name, t.name, r)
+		}
+	}()
+
+	t.adapter.Send(line)
+}
+
+// 适配器快照.
+//
+// 添加和删除时整体替换列表, 发送过程无需持有锁.
+func (o *Manager) list() []*target {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.targets
+}
+
+func (o *Manager) init() *Manager {
+	o.name = fmt.Sprintf("log-multi-manager")
+	o.targets = make([]*target, 0)
+	o.keeper = base.NewKeeper(o.name).Listen(o.onListen)
+	return o
+}
@@ -0,0 +1,41 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-19
+
+package log_otlp
+
+import (
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/config"
+)
+
+type (
+	// Formatter
+	// 格式化.
+	//
+	// 生成 LogRecord.body 正文, 级别、时间、链路和字段由 OTLP 结构单独上报.
+	Formatter struct {
+		config *config.Configuration
+	}
+)
+
+// Byte
+// 转成Byte字符集.
+func (o *Formatter) Byte(line *adapters.Line) []byte { return []byte(o.String(line)) }
+
+// String
+// 转成字符串.
+func (o *Formatter) String(line *adapters.Line) string { return line.Text }
+
+func (o *Formatter) init() *Formatter { return o }
@@ -0,0 +1,170 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-19
+
+package log_otlp
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/adapters/otlp"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+	"time"
+)
+
+type (
+	// Manager
+	// 日志管理器.
+	//
+	// 以 OTLP/HTTP 协议上报用户日志到 OpenTelemetry Collector.
+	Manager struct {
+		bucket    *adapters.Bucket
+		config    *config.Configuration
+		formatter adapters.LogFormatter
+		keeper    base.Keeper
+		name      string
+	}
+)
+
+func New() adapters.LogAdapter {
+	return NewWithConfig(config.Config)
+}
+
+// NewWithConfig
+// 基于指定配置创建适配器.
+func NewWithConfig(c *config.Configuration) adapters.LogAdapter {
+	return (&Manager{config: c}).init()
+}
+
+func (o *Manager) Keeper() base.Keeper { return o.keeper }
+
+// Send
+// 加入数据桶.
+//
+// 若数据桶积压数量超过指定值时, 立即上报.
+func (o *Manager) Send(line *adapters.Line) {
+	if n := o.bucket.Add(line); n >= o.config.Snapshot().LogAdapterOtlp.Batch {
+		go o.save()
+	}
+}
+
+// SetFormatter
+// 设置格式.
+func (o *Manager) SetFormatter(formatter adapters.LogFormatter) {
+	o.formatter = formatter
+}
+
+// +---------------------------------------------------------------------------+
+// | Event methods                                                             |
+// +---------------------------------------------------------------------------+
+
+func (o *Manager) onAfter(ctx context.Context) (ignored bool) {
+	if o.bucket.Count() > 0 {
+		o.save()
+		return o.onAfter(ctx)
+	}
+	return
+}
+
+func (o *Manager) onListen(ctx context.Context) (ignored bool) {
+	// 1. 定时保存.
+	//    每隔指定时长(默认: 350ms)上报一次日志.
+	ticker := time.NewTicker(time.Duration(o.config.Snapshot().LogAdapterOtlp.Milliseconds) * time.Millisecond)
+
+	// 2. 关闭定时.
+	defer ticker.Stop()
+
+	// 3. 监听信号.
+	for {
+		select {
+		case <-ticker.C:
+			go o.save()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+func (o *Manager) init() *Manager {
+	o.bucket = adapters.NewBucket()
+	o.formatter = (&Formatter{config: o.config}).init()
+	o.name = fmt.Sprintf("log-otlp-manager")
+	o.keeper = base.NewKeeper(o.name).
+		After(o.onAfter).
+		Listen(o.onListen)
+	return o
+}
+
+func (o *Manager) save() {
+	var (
+		list, count = o.bucket.Popn(o.config.Snapshot().LogAdapterOtlp.Batch)
+		records     = make([]*otlp.LogRecord, 0)
+	)
+
+	// 1. 空数据桶.
+	if count == 0 {
+		return
+	}
+
+	// 2. 释放实例.
+	defer func() {
+		// 2.1 捕获异常.
+		if r := recover(); r != nil {
+			_, _ = fmt.Fprintf(base.Stderr(), "otlp log fatal: %v\n%s\n", r,
+				adapters.Backstack().String(),
+			)
+		}
+
+		// 2.2 释放日志.
+		for _, v := range list {
+			v.(*adapters.Line).Release()
+		}
+	}()
+
+	// 3. 转换日志.
+	for _, v := range list {
+		line := v.(*adapters.Line)
+		records = append(records, otlp.NewLogRecord(line, o.formatter.String(line)))
+	}
+
+	// 4. 消息编码.
+	var (
+		body []byte
+		cfg  = o.config.Snapshot().LogAdapterOtlp
+		data = otlp.NewLogsData(records...)
+		err  error
+	)
+	if cfg.Encoding == config.OtlpEncodingJson {
+		if body, err = data.Json(); err != nil {
+			_, _ = fmt.Fprintf(base.Stderr(), "otlp log formatter: %v\n", err)
+			return
+		}
+	} else {
+		body = data.Protobuf()
+	}
+
+	// 5. 上报日志.
+	w := otlp.NewWriter()
+	defer w.Release()
+
+	if err = w.Send(cfg.Endpoint, cfg.Encoding, cfg.Headers, body); err != nil {
+		_, _ = fmt.Fprintf(base.Stderr(), "otlp log: %v\n", err)
+	}
+}
@@ -25,7 +25,9 @@ import (
 type (
 	// Formatter
 	// 格式化.
-	Formatter struct{}
+	Formatter struct {
+		config *config.Configuration
+	}
 )
 
 // Byte
@@ -38,7 +40,7 @@ func (o *Formatter) String(line *adapters.Line) string {
 	var (
 		// 日志正文.
 		text = fmt.Sprintf("[%s][%s]",
-			line.Time.Format(config.Config.LogTimeFormat),
+			line.Time.Format(o.config.Snapshot().LogTimeFormat),
 			line.Level,
 		)
 	)
@@ -52,10 +54,10 @@ func (o *Formatter) String(line *adapters.Line) string {
 	}
 
 	// 2. 绑定字段.
-	if len(line.Attr) > 0 {
+	if line.FieldCount() > 0 {
 		text = fmt.Sprintf("%s %s",
 			text,
-			line.Attr.Json(),
+			line.AppendFields(nil),
 		)
 	}
 
@@ -66,7 +68,7 @@ func (o *Formatter) String(line *adapters.Line) string {
 	)
 
 	// 4. 日志着色.
-	if *config.Config.LogAdapterTerm.Color {
+	if *o.config.Snapshot().LogAdapterTerm.Color {
 		return o.color(line.Level, text)
 	}
 
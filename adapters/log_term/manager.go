@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"github.com/go-wares/log/adapters"
 	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
 	"os"
 )
 
@@ -29,6 +30,7 @@ type (
 	//
 	// 发送用户日志到终端(Terminal)上打印.
 	Manager struct {
+		config    *config.Configuration
 		formatter adapters.LogFormatter
 		keeper    base.Keeper
 		name      string
@@ -36,8 +38,13 @@ type (
 )
 
 func New() adapters.LogAdapter {
-	return (&Manager{}).
-		init()
+	return NewWithConfig(config.Config)
+}
+
+// NewWithConfig
+// 基于指定配置创建适配器.
+func NewWithConfig(c *config.Configuration) adapters.LogAdapter {
+	return (&Manager{config: c}).init()
 }
 
 func (o *Manager) Keeper() base.Keeper { return o.keeper }
@@ -69,7 +76,7 @@ func (o *Manager) onListen(ctx context.Context) (ignored bool) {
 // +---------------------------------------------------------------------------+
 
 func (o *Manager) init() *Manager {
-	o.formatter = (&Formatter{}).init()
+	o.formatter = (&Formatter{config: o.config}).init()
 	o.name = fmt.Sprintf("log-term-manager")
 	o.keeper = base.NewKeeper(o.name).Listen(o.onListen)
 	return o
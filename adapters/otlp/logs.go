@@ -0,0 +1,150 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-19
+
+package otlp
+
+import (
+	"encoding/json"
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+)
+
+var (
+	// Severity
+	// 日志级别对应的 SeverityNumber.
+	Severity = map[base.LogLevel]int{
+		base.Debug: 5,
+		base.Info:  9,
+		base.Warn:  13,
+		base.Error: 17,
+		base.Fatal: 21,
+	}
+)
+
+type (
+	// LogsData
+	// 日志上报请求(ExportLogsServiceRequest).
+	LogsData struct {
+		ResourceLogs []*ResourceLogs `json:"resourceLogs"`
+	}
+
+	// ResourceLogs
+	// 资源日志.
+	ResourceLogs struct {
+		Resource  *Resource    `json:"resource"`
+		ScopeLogs []*ScopeLogs `json:"scopeLogs"`
+	}
+
+	// ScopeLogs
+	// 埋点日志.
+	ScopeLogs struct {
+		Scope      *Scope       `json:"scope"`
+		LogRecords []*LogRecord `json:"logRecords"`
+	}
+
+	// LogRecord
+	// 单行日志.
+	LogRecord struct {
+		TimeUnixNano         uint64      `json:"timeUnixNano,string"`
+		ObservedTimeUnixNano uint64      `json:"observedTimeUnixNano,string"`
+		SeverityNumber       int         `json:"severityNumber"`
+		SeverityText         string      `json:"severityText"`
+		Body                 *AnyValue   `json:"body"`
+		Attributes           []*KeyValue `json:"attributes,omitempty"`
+		TraceId              string      `json:"traceId,omitempty"`
+		SpanId               string      `json:"spanId,omitempty"`
+	}
+)
+
+// NewLogsData
+// 创建上报请求.
+func NewLogsData(records ...*LogRecord) *LogsData {
+	return &LogsData{
+		ResourceLogs: []*ResourceLogs{{
+			Resource: NewResource(adapters.GetResource()),
+			ScopeLogs: []*ScopeLogs{{
+				Scope:      &Scope{Name: config.Name, Version: config.Version},
+				LogRecords: records,
+			}},
+		}},
+	}
+}
+
+// NewLogRecord
+// 转换日志, 日志正文由调用方格式化.
+func NewLogRecord(line *adapters.Line, body string) *LogRecord {
+	v := &LogRecord{
+		TimeUnixNano:         uint64(line.Time.UnixNano()),
+		ObservedTimeUnixNano: uint64(line.Time.UnixNano()),
+		SeverityNumber:       Severity[line.Level],
+		SeverityText:         line.Level.String(),
+		Body:                 NewAnyValue(body),
+		Attributes:           NewKeyValues(line.FieldMap()),
+	}
+
+	if line.Tracer {
+		v.TraceId = line.TraceId
+		v.SpanId = line.SpanId
+	}
+	return v
+}
+
+// Json
+// 按 OTLP/JSON 编码.
+func (o *LogsData) Json() ([]byte, error) { return json.Marshal(o) }
+
+// Protobuf
+// 按 OTLP/Protobuf 编码.
+func (o *LogsData) Protobuf() []byte {
+	var b []byte
+	for _, rl := range o.ResourceLogs {
+		b = appendMessage(b, 1, rl.appendProto(nil))
+	}
+	return b
+}
+
+// +---------------------------------------------------------------------------+
+// | Protobuf encoding                                                         |
+// +---------------------------------------------------------------------------+
+
+func (o *ResourceLogs) appendProto(b []byte) []byte {
+	b = appendMessage(b, 1, o.Resource.appendProto(nil))
+	for _, sl := range o.ScopeLogs {
+		b = appendMessage(b, 2, sl.appendProto(nil))
+	}
+	return b
+}
+
+func (o *ScopeLogs) appendProto(b []byte) []byte {
+	b = appendMessage(b, 1, o.Scope.appendProto(nil))
+	for _, lr := range o.LogRecords {
+		b = appendMessage(b, 2, lr.appendProto(nil))
+	}
+	return b
+}
+
+func (o *LogRecord) appendProto(b []byte) []byte {
+	b = appendFixed64(b, 1, o.TimeUnixNano)
+	b = appendInt(b, 2, int64(o.SeverityNumber))
+	b = appendString(b, 3, o.SeverityText)
+	b = appendMessage(b, 5, o.Body.appendProto(nil))
+	for _, kv := range o.Attributes {
+		b = appendMessage(b, 6, kv.appendProto(nil))
+	}
+	b = appendBytes(b, 9, decodeId(o.TraceId))
+	b = appendBytes(b, 10, decodeId(o.SpanId))
+	return appendFixed64(b, 11, o.ObservedTimeUnixNano)
+}
@@ -0,0 +1,208 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-19
+
+package otlp
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// AnyValue 类型.
+const (
+	kindString = iota
+	kindBool
+	kindInt
+	kindDouble
+)
+
+type (
+	// AnyValue
+	// 属性值.
+	//
+	//   {"stringValue": "value"}
+	//   {"intValue": "1"}
+	AnyValue struct {
+		kind int
+		b    bool
+		d    float64
+		i    int64
+		s    string
+	}
+
+	// KeyValue
+	// 属性.
+	KeyValue struct {
+		Key   string    `json:"key"`
+		Value *AnyValue `json:"value"`
+	}
+
+	// Resource
+	// 资源.
+	Resource struct {
+		Attributes []*KeyValue `json:"attributes,omitempty"`
+	}
+
+	// Scope
+	// 埋点库.
+	Scope struct {
+		Name    string `json:"name,omitempty"`
+		Version string `json:"version,omitempty"`
+	}
+)
+
+// NewAnyValue
+// 创建属性值.
+func NewAnyValue(v interface{}) *AnyValue {
+	switch x := v.(type) {
+	case string:
+		return &AnyValue{kind: kindString, s: x}
+	case bool:
+		return &AnyValue{kind: kindBool, b: x}
+	case int:
+		return &AnyValue{kind: kindInt, i: int64(x)}
+	case int8:
+		return &AnyValue{kind: kindInt, i: int64(x)}
+	case int16:
+		return &AnyValue{kind: kindInt, i: int64(x)}
+	case int32:
+		return &AnyValue{kind: kindInt, i: int64(x)}
+	case int64:
+		return &AnyValue{kind: kindInt, i: x}
+	case uint:
+		return &AnyValue{kind: kindInt, i: int64(x)}
+	case uint8:
+		return &AnyValue{kind: kindInt, i: int64(x)}
+	case uint16:
+		return &AnyValue{kind: kindInt, i: int64(x)}
+	case uint32:
+		return &AnyValue{kind: kindInt, i: int64(x)}
+	case uint64:
+		return &AnyValue{kind: kindInt, i: int64(x)}
+	case float32:
+		return &AnyValue{kind: kindDouble, d: float64(x)}
+	case float64:
+		return &AnyValue{kind: kindDouble, d: x}
+	case error:
+		return &AnyValue{kind: kindString, s: x.Error()}
+	case fmt.Stringer:
+		return &AnyValue{kind: kindString, s: x.String()}
+	}
+
+	// 复合结构.
+	if buf, err := json.Marshal(v); err == nil {
+		return &AnyValue{kind: kindString, s: string(buf)}
+	}
+	return &AnyValue{kind: kindString, s: fmt.Sprintf("%v", v)}
+}
+
+// NewKeyValues
+// 属性列表, 按键名排序.
+func NewKeyValues(attrs ...map[string]interface{}) []*KeyValue {
+	list := make([]*KeyValue, 0)
+	for _, attr := range attrs {
+		for k, v := range attr {
+			list = append(list, &KeyValue{Key: k, Value: NewAnyValue(v)})
+		}
+	}
+
+	if len(list) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	return list
+}
+
+// NewResource
+// 创建资源.
+func NewResource(attr map[string]interface{}) *Resource {
+	return &Resource{Attributes: NewKeyValues(attr)}
+}
+
+// +---------------------------------------------------------------------------+
+// | JSON encoding                                                             |
+// +---------------------------------------------------------------------------+
+
+// MarshalJSON
+// 按 OTLP/JSON 规范编码, 64位整型转为字符串.
+func (o *AnyValue) MarshalJSON() ([]byte, error) {
+	switch o.kind {
+	case kindBool:
+		return json.Marshal(map[string]bool{"boolValue": o.b})
+	case kindInt:
+		return json.Marshal(map[string]string{"intValue": strconv.FormatInt(o.i, 10)})
+	case kindDouble:
+		return json.Marshal(map[string]float64{"doubleValue": o.d})
+	}
+	return json.Marshal(map[string]string{"stringValue": o.s})
+}
+
+// +---------------------------------------------------------------------------+
+// | Protobuf encoding                                                         |
+// +---------------------------------------------------------------------------+
+
+func (o *AnyValue) appendProto(b []byte) []byte {
+	switch o.kind {
+	case kindBool:
+		b = appendTag(b, 2, wireVarint)
+		if o.b {
+			return appendVarint(b, 1)
+		}
+		return appendVarint(b, 0)
+	case kindInt:
+		return appendVarint(appendTag(b, 3, wireVarint), uint64(o.i))
+	case kindDouble:
+		return appendDouble(b, 4, o.d)
+	}
+	b = appendTag(b, 1, wireBytes)
+	b = appendVarint(b, uint64(len(o.s)))
+	return append(b, o.s...)
+}
+
+func (o *KeyValue) appendProto(b []byte) []byte {
+	b = appendString(b, 1, o.Key)
+	return appendMessage(b, 2, o.Value.appendProto(nil))
+}
+
+func (o *Resource) appendProto(b []byte) []byte {
+	for _, kv := range o.Attributes {
+		b = appendMessage(b, 1, kv.appendProto(nil))
+	}
+	return b
+}
+
+func (o *Scope) appendProto(b []byte) []byte {
+	b = appendString(b, 1, o.Name)
+	return appendString(b, 2, o.Version)
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+// 十六进制ID转为字节.
+func decodeId(str string) []byte {
+	if str == "" {
+		return nil
+	}
+	if buf, err := hex.DecodeString(str); err == nil {
+		return buf
+	}
+	return nil
+}
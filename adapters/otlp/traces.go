@@ -0,0 +1,180 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-19
+
+package otlp
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/config"
+)
+
+// SpanKindInternal
+// 跨度类型: 内部调用.
+const SpanKindInternal = 1
+
+type (
+	// TracesData
+	// 链路上报请求(ExportTraceServiceRequest).
+	TracesData struct {
+		ResourceSpans []*ResourceSpans `json:"resourceSpans"`
+	}
+
+	// ResourceSpans
+	// 资源跨度.
+	ResourceSpans struct {
+		Resource   *Resource     `json:"resource"`
+		ScopeSpans []*ScopeSpans `json:"scopeSpans"`
+	}
+
+	// ScopeSpans
+	// 埋点跨度.
+	ScopeSpans struct {
+		Scope *Scope  `json:"scope"`
+		Spans []*Span `json:"spans"`
+	}
+
+	// Span
+	// 跨度.
+	Span struct {
+		TraceId           string      `json:"traceId"`
+		SpanId            string      `json:"spanId"`
+		ParentSpanId      string      `json:"parentSpanId,omitempty"`
+		Name              string      `json:"name"`
+		Kind              int         `json:"kind"`
+		StartTimeUnixNano uint64      `json:"startTimeUnixNano,string"`
+		EndTimeUnixNano   uint64      `json:"endTimeUnixNano,string"`
+		Attributes        []*KeyValue `json:"attributes,omitempty"`
+		Events            []*Event    `json:"events,omitempty"`
+	}
+
+	// Event
+	// 跨度事件, 对应跨度日志.
+	Event struct {
+		TimeUnixNano uint64      `json:"timeUnixNano,string"`
+		Name         string      `json:"name"`
+		Attributes   []*KeyValue `json:"attributes,omitempty"`
+	}
+)
+
+// NewTracesData
+// 基于跨度列表创建上报请求.
+func NewTracesData(list ...adapters.Span) *TracesData {
+	spans := make([]*Span, 0)
+	for _, sp := range list {
+		spans = append(spans, NewSpan(sp))
+	}
+
+	return &TracesData{
+		ResourceSpans: []*ResourceSpans{{
+			Resource: NewResource(adapters.GetResource()),
+			ScopeSpans: []*ScopeSpans{{
+				Scope: &Scope{Name: config.Name, Version: config.Version},
+				Spans: spans,
+			}},
+		}},
+	}
+}
+
+// NewSpan
+// 转换跨度.
+func NewSpan(sp adapters.Span) *Span {
+	v := &Span{
+		TraceId:           hex.EncodeToString(sp.Trace().TraceId().Body()),
+		SpanId:            hex.EncodeToString(sp.SpanId().Body()),
+		Name:              sp.Name(),
+		Kind:              SpanKindInternal,
+		StartTimeUnixNano: uint64(sp.StartTime().UnixNano()),
+		EndTimeUnixNano:   uint64(sp.EndTime().UnixNano()),
+		Attributes:        NewKeyValues(sp.Attr()),
+	}
+
+	if pid := sp.ParentSpanId(); pid != nil {
+		v.ParentSpanId = hex.EncodeToString(pid.Body())
+	}
+
+	// 跨度日志转为事件.
+	for _, line := range sp.Logs() {
+		v.Events = append(v.Events, &Event{
+			TimeUnixNano: uint64(line.Time.UnixNano()),
+			Name:         line.Text,
+			Attributes: NewKeyValues(line.Attr, map[string]interface{}{
+				"log.severity": line.Level.String(),
+			}),
+		})
+	}
+	return v
+}
+
+// Json
+// 按 OTLP/JSON 编码.
+func (o *TracesData) Json() ([]byte, error) { return json.Marshal(o) }
+
+// Protobuf
+// 按 OTLP/Protobuf 编码.
+func (o *TracesData) Protobuf() []byte {
+	var b []byte
+	for _, rs := range o.ResourceSpans {
+		b = appendMessage(b, 1, rs.appendProto(nil))
+	}
+	return b
+}
+
+// +---------------------------------------------------------------------------+
+// | Protobuf encoding                                                         |
+// +---------------------------------------------------------------------------+
+
+func (o *ResourceSpans) appendProto(b []byte) []byte {
+	b = appendMessage(b, 1, o.Resource.appendProto(nil))
+	for _, ss := range o.ScopeSpans {
+		b = appendMessage(b, 2, ss.appendProto(nil))
+	}
+	return b
+}
+
+func (o *ScopeSpans) appendProto(b []byte) []byte {
+	b = appendMessage(b, 1, o.Scope.appendProto(nil))
+	for _, sp := range o.Spans {
+		b = appendMessage(b, 2, sp.appendProto(nil))
+	}
+	return b
+}
+
+func (o *Span) appendProto(b []byte) []byte {
+	b = appendBytes(b, 1, decodeId(o.TraceId))
+	b = appendBytes(b, 2, decodeId(o.SpanId))
+	b = appendBytes(b, 4, decodeId(o.ParentSpanId))
+	b = appendString(b, 5, o.Name)
+	b = appendInt(b, 6, int64(o.Kind))
+	b = appendFixed64(b, 7, o.StartTimeUnixNano)
+	b = appendFixed64(b, 8, o.EndTimeUnixNano)
+	for _, kv := range o.Attributes {
+		b = appendMessage(b, 9, kv.appendProto(nil))
+	}
+	for _, ev := range o.Events {
+		b = appendMessage(b, 11, ev.appendProto(nil))
+	}
+	return b
+}
+
+func (o *Event) appendProto(b []byte) []byte {
+	b = appendFixed64(b, 1, o.TimeUnixNano)
+	b = appendString(b, 2, o.Name)
+	for _, kv := range o.Attributes {
+		b = appendMessage(b, 3, kv.appendProto(nil))
+	}
+	return b
+}
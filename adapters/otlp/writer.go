@@ -0,0 +1,106 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-19
+
+package otlp
+
+import (
+	"fmt"
+	"github.com/go-wares/log/config"
+	"github.com/valyala/fasthttp"
+	"net/http"
+	"sync"
+)
+
+var (
+	writerPool sync.Pool
+)
+
+type (
+	// Writer
+	// OTLP/HTTP 上报.
+	Writer interface {
+		Release()
+		Send(endpoint string, encoding config.OtlpEncoding, headers map[string]string, body []byte) error
+	}
+
+	writer struct {
+		request  *fasthttp.Request
+		response *fasthttp.Response
+	}
+)
+
+func NewWriter() Writer {
+	if o := writerPool.Get(); o != nil {
+		return o.(*writer).before()
+	}
+
+	o := (&writer{}).init()
+	o.before()
+	return o
+}
+
+func (o *writer) Release() {
+	o.after()
+	writerPool.Put(o)
+}
+
+// Send
+// 发送上报请求.
+func (o *writer) Send(endpoint string, encoding config.OtlpEncoding, headers map[string]string, body []byte) error {
+	// 1. 准备请求.
+	o.request.SetRequestURI(endpoint)
+	o.request.SetBody(body)
+	o.request.Header.SetMethod(http.MethodPost)
+	o.request.Header.SetContentType(encoding.ContentType())
+
+	// 2. 附加请求头.
+	for k, v := range headers {
+		o.request.Header.Set(k, v)
+	}
+
+	// 3. 发送请求.
+	if err := fasthttp.Do(o.request, o.response); err != nil {
+		return err
+	}
+
+	// 4. 上报结果.
+	if code := o.response.StatusCode(); code >= http.StatusBadRequest {
+		return fmt.Errorf("status code %d, %s", code, o.response.Body())
+	}
+	return nil
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+func (o *writer) after() *writer {
+	fasthttp.ReleaseRequest(o.request)
+	fasthttp.ReleaseResponse(o.response)
+
+	o.request = nil
+	o.response = nil
+	return o
+}
+
+func (o *writer) before() *writer {
+	o.request = fasthttp.AcquireRequest()
+	o.response = fasthttp.AcquireResponse()
+	return o
+}
+
+func (o *writer) init() *writer {
+	return o
+}
@@ -78,4 +78,13 @@ type (
 		Error(format string, args ...interface{})
 		Fatal(format string, args ...interface{})
 	}
+
+	// SpanRequest
+	// HTTP 请求属性.
+	//
+	// 基于 HTTP 请求创建的跨度实现此接口, 持锁读取请求方法、路径及 User-Agent,
+	// 未关联请求时为空字符串; 日志创建时据此填充请求字段.
+	SpanRequest interface {
+		Request() (method, url, userAgent string)
+	}
 )
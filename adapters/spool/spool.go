@@ -0,0 +1,442 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-06-04
+
+package spool
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// 暂存文件扩展名.
+	fileExt = ".spool"
+
+	// 1MB.
+	megabyte = 1024 * 1024
+)
+
+var (
+	// ErrDisabled
+	// 未配置暂存目录.
+	ErrDisabled = errors.New("spool disabled")
+
+	// ErrFull
+	// 暂存目录超过最大容量.
+	ErrFull = errors.New("spool full")
+)
+
+type (
+	// Record
+	// 暂存记录.
+	//
+	// Key 为分区键, 同一暂存目录内的记录按写入顺序重放, 同一分区键的记录顺序
+	// 不变; Topic 为空时由重放方决定目标.
+	Record struct {
+		Topic      string
+		Key, Value []byte
+		Headers    []Header
+	}
+
+	// Header
+	// 记录头.
+	Header struct {
+		Key, Value []byte
+	}
+
+	// Sender
+	// 重放回调.
+	//
+	// 返回 nil 时删除暂存文件, 否则保留文件并按指数间隔重试.
+	Sender func(records []Record) error
+
+	// Spool
+	// 磁盘暂存队列.
+	//
+	// 每个失败的批次写为1个文件, 文件名以纳秒时间开头, 按文件名顺序逐个重放,
+	// 任一文件失败时停止本轮重放. 存在未重放的文件时, 新批次也须写入暂存, 以
+	// 保证顺序.
+	Spool struct {
+		config func() *config.Spool
+		keeper base.Keeper
+		mu     sync.Mutex
+		name   string
+		sender Sender
+		seq    uint64
+		signal chan struct{}
+
+		// 已加载目录的统计.
+		count int
+		path  string
+		size  int64
+	}
+)
+
+// New
+// 创建暂存队列.
+//
+// 参数 c 每次读取时调用, 配置热更新后立即生效.
+func New(name string, c func() *config.Spool, sender Sender) *Spool {
+	return (&Spool{config: c, name: name, sender: sender}).init()
+}
+
+// Enabled
+// 是否已配置暂存目录.
+func (o *Spool) Enabled() bool {
+	return o.config().SpoolPath != ""
+}
+
+func (o *Spool) Keeper() base.Keeper { return o.keeper }
+
+// Pending
+// 是否有未重放的批次.
+func (o *Spool) Pending() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.load() && o.count > 0
+}
+
+// Write
+// 写入暂存.
+//
+// 先写临时文件并同步到磁盘, 再重命名并同步目录, 重放时不会读到不完整的文件,
+// 宕机后已写入的暂存不会丢失.
+func (o *Spool) Write(records []Record) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// 1. 未启用.
+	if !o.load() {
+		return ErrDisabled
+	}
+
+	// 2. 编码记录.
+	body := encode(records)
+
+	// 3. 超过容量.
+	if o.size+int64(len(body)) > int64(o.config().SpoolMaxSize)*megabyte {
+		return ErrFull
+	}
+
+	// 4. 写入文件.
+	o.seq++
+	var (
+		name = fmt.Sprintf("%020d-%06d%s", time.Now().UnixNano(), o.seq%1000000, fileExt)
+		path = filepath.Join(o.path, name)
+		tmp  = path + ".tmp"
+	)
+	if err := syncFile(tmp, body); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := syncDir(o.path); err != nil {
+		_, _ = fmt.Fprintf(base.Stderr(), "%s sync %s: %v\n", o.name, o.path, err)
+	}
+
+	// 5. 统计数量.
+	o.count++
+	o.size += int64(len(body))
+	o.notify()
+	return nil
+}
+
+// +---------------------------------------------------------------------------+
+// | Event methods                                                             |
+// +---------------------------------------------------------------------------+
+
+func (o *Spool) onListen(ctx context.Context) (ignored bool) {
+	var (
+		c      = o.config()
+		delay  = time.Duration(c.SpoolMilliseconds) * time.Millisecond
+		failed bool
+		timer  = time.NewTimer(delay)
+	)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+		case <-o.signal:
+			// 重试间隔内不提前重放.
+			if failed {
+				continue
+			}
+			if !timer.Stop() {
+				<-timer.C
+			}
+		case <-ctx.Done():
+			return
+		}
+
+		// 1. 重放成功.
+		//    还原间隔.
+		c = o.config()
+		if failed = !o.replay(); !failed {
+			delay = time.Duration(c.SpoolMilliseconds) * time.Millisecond
+			timer.Reset(delay)
+			continue
+		}
+
+		// 2. 重放失败.
+		//    间隔加倍, 不超过最大值.
+		if delay *= 2; delay > time.Duration(c.SpoolMaxMilliseconds)*time.Millisecond {
+			delay = time.Duration(c.SpoolMaxMilliseconds) * time.Millisecond
+		}
+		timer.Reset(delay)
+	}
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+// 暂存文件列表.
+// 按文件名(即写入顺序)升序.
+func (o *Spool) files() []string {
+	entries, err := os.ReadDir(o.path)
+	if err != nil {
+		return nil
+	}
+
+	list := make([]string, 0)
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), fileExt) {
+			list = append(list, entry.Name())
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+func (o *Spool) init() *Spool {
+	o.signal = make(chan struct{}, 1)
+	o.keeper = base.NewKeeper(o.name).Listen(o.onListen)
+	return o
+}
+
+// 加载目录.
+//
+// 目录变更(含首次)时创建目录并统计已有文件, 未启用时返回 false; 调用方须
+// 持有锁.
+func (o *Spool) load() bool {
+	path := o.config().SpoolPath
+	if path == "" {
+		return false
+	}
+	if path == o.path {
+		return true
+	}
+
+	// 1. 创建目录.
+	if err := os.MkdirAll(path, 0755); err != nil {
+		_, _ = fmt.Fprintf(base.Stderr(), "%s mkdir: %v\n", o.name, err)
+		return false
+	}
+
+	// 2. 已有文件.
+	//    上次退出前未重放的批次.
+	o.count, o.path, o.size = 0, path, 0
+	for _, name := range o.files() {
+		if info, err := os.Stat(filepath.Join(path, name)); err == nil {
+			o.count++
+			o.size += info.Size()
+		}
+	}
+	if o.count > 0 {
+		o.notify()
+	}
+	return true
+}
+
+// 通知重放.
+func (o *Spool) notify() {
+	select {
+	case o.signal <- struct{}{}:
+	default:
+	}
+}
+
+// 按顺序重放.
+// 全部成功或无暂存文件时返回 true.
+func (o *Spool) replay() bool {
+	o.mu.Lock()
+	if !o.load() {
+		o.mu.Unlock()
+		return true
+	}
+	var (
+		dir   = o.path
+		names = o.files()
+	)
+	o.mu.Unlock()
+
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+
+		// 1. 读取文件.
+		//    读取失败时保留文件并停止重放, 保证顺序; 损坏的文件无法重放, 直接删除.
+		body, err := os.ReadFile(path)
+		if err != nil {
+			_, _ = fmt.Fprintf(base.Stderr(), "%s read %s: %v\n", o.name, name, err)
+			return false
+		}
+		records, err := decode(body)
+		if err != nil {
+			_, _ = fmt.Fprintf(base.Stderr(), "%s decode %s: %v\n", o.name, name, err)
+			o.remove(dir, path, int64(len(body)))
+			continue
+		}
+
+		// 2. 重放失败.
+		//    保留文件, 后续文件不再重放, 保证顺序.
+		if err = o.sender(records); err != nil {
+			_, _ = fmt.Fprintf(base.Stderr(), "%s replay: %v\n", o.name, err)
+			return false
+		}
+
+		// 3. 删除文件.
+		o.remove(dir, path, int64(len(body)))
+	}
+	return true
+}
+
+// 删除暂存文件.
+func (o *Spool) remove(dir, path string, size int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if err := os.Remove(path); err != nil {
+		_, _ = fmt.Fprintf(base.Stderr(), "%s remove: %v\n", o.name, err)
+		return
+	}
+	if dir == o.path {
+		o.count--
+		o.size -= size
+	}
+}
+
+// +---------------------------------------------------------------------------+
+// | Codec methods                                                             |
+// +---------------------------------------------------------------------------+
+
+// 解码记录.
+func decode(body []byte) (records []Record, err error) {
+	var (
+		r = bytes.NewReader(body)
+		n uint64
+	)
+
+	// 读取字节.
+	read := func() (p []byte) {
+		var size uint64
+		if size, err = binary.ReadUvarint(r); err != nil {
+			return
+		}
+		if size > uint64(r.Len()) {
+			err = io.ErrUnexpectedEOF
+			return
+		}
+		p = make([]byte, size)
+		_, _ = r.Read(p)
+		return
+	}
+
+	for r.Len() > 0 && err == nil {
+		rec := Record{Topic: string(read()), Key: read(), Value: read()}
+		if n, err = binary.ReadUvarint(r); err != nil {
+			break
+		}
+		for i := uint64(0); i < n && err == nil; i++ {
+			rec.Headers = append(rec.Headers, Header{Key: read(), Value: read()})
+		}
+		records = append(records, rec)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// 编码记录.
+//
+//	uvarint(len(topic)) topic uvarint(len(key)) key uvarint(len(value)) value uvarint(len(headers)) [uvarint(len(k)) k uvarint(len(v)) v]...
+func encode(records []Record) []byte {
+	var (
+		buf = make([]byte, 0, 1024)
+		tmp [binary.MaxVarintLen64]byte
+	)
+
+	// 写入字节.
+	write := func(p []byte) {
+		buf = append(buf, tmp[:binary.PutUvarint(tmp[:], uint64(len(p)))]...)
+		buf = append(buf, p...)
+	}
+
+	for _, rec := range records {
+		write([]byte(rec.Topic))
+		write(rec.Key)
+		write(rec.Value)
+		buf = append(buf, tmp[:binary.PutUvarint(tmp[:], uint64(len(rec.Headers)))]...)
+		for _, h := range rec.Headers {
+			write(h.Key)
+			write(h.Value)
+		}
+	}
+	return buf
+}
+
+// 同步目录.
+// 重命名后同步目录项, 保证宕机后文件名可见.
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	return f.Sync()
+}
+
+// 写入并同步文件.
+func syncFile(path string, body []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(body); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
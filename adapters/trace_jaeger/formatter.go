@@ -28,9 +28,17 @@ import (
 )
 
 type (
-	formatter struct{}
+	formatter struct {
+		config *config.Configuration
+	}
 )
 
+// NewFormatter
+// 创建Jaeger thrift格式化.
+func NewFormatter(c *config.Configuration) adapters.TraceFormatter {
+	return (&formatter{config: c}).init()
+}
+
 func (o *formatter) Byte(vs ...adapters.Span) ([]byte, error) {
 	return o.thrift(vs...)
 }
@@ -53,7 +61,7 @@ func (o *formatter) buildLogs(list []*adapters.Line) []*jaeger.Log {
 		logs = append(logs, &jaeger.Log{
 			Timestamp: x.Time.UnixMicro(),
 			Fields: o.buildTagsMapper(x.Attr, adapters.Attr{
-				x.Time.Format(config.Config.LogTimeFormat): x.Text,
+				x.Time.Format(o.config.Snapshot().LogTimeFormat): x.Text,
 				"log-level": x.Level,
 			}),
 		})
@@ -63,8 +71,8 @@ func (o *formatter) buildLogs(list []*adapters.Line) []*jaeger.Log {
 
 func (o *formatter) buildProcess() *jaeger.Process {
 	return &jaeger.Process{
-		ServiceName: config.Config.TraceAdapterJaeger.Topic,
-		Tags:        o.buildTagsMapper(adapters.Resource),
+		ServiceName: o.config.Snapshot().TraceAdapterJaeger.Topic,
+		Tags:        o.buildTagsMapper(adapters.GetResource()),
 	}
 }
 
@@ -19,8 +19,10 @@ import (
 	"context"
 	"fmt"
 	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/adapters/spool"
 	"github.com/go-wares/log/base"
 	"github.com/go-wares/log/config"
+	"sync"
 	"time"
 )
 
@@ -29,25 +31,39 @@ type (
 	// 链路(Jaeger)管理器.
 	Manager struct {
 		bucket    *adapters.Bucket
+		config    *config.Configuration
 		formatter *formatter
 		keeper    base.Keeper
 		name      string
+		saveMu    sync.Mutex
+		saving    sync.WaitGroup
+		spool     *spool.Spool
 	}
 )
 
 func New() adapters.TraceAdapter {
-	return (&Manager{}).init()
+	return NewWithConfig(config.Config)
+}
+
+// NewWithConfig
+// 基于指定配置创建适配器.
+func NewWithConfig(c *config.Configuration) adapters.TraceAdapter {
+	return (&Manager{config: c}).init()
 }
 
 // +---------------------------------------------------------------------------+
 // | Interface methods                                                         |
 // +---------------------------------------------------------------------------+
 
+// Dropped
+// 数据桶溢出时丢弃的跨度数量.
+func (o *Manager) Dropped() uint64 { return o.bucket.Dropped() }
+
 func (o *Manager) Keeper() base.Keeper { return o.keeper }
 
 func (o *Manager) Send(span adapters.Span) {
-	if n := o.bucket.Add(span); n >= config.Config.TraceAdapterJaeger.Batch {
-		go o.save()
+	if n := o.bucket.Add(span); n >= o.config.Snapshot().TraceAdapterJaeger.Batch {
+		o.saveAsync()
 	}
 }
 
@@ -56,9 +72,12 @@ func (o *Manager) Send(span adapters.Span) {
 // +---------------------------------------------------------------------------+
 
 func (o *Manager) onAfter(ctx context.Context) (ignored bool) {
-	if o.bucket.Count() > 0 {
+	// 1. 等待上报.
+	o.saving.Wait()
+
+	// 2. 清空数据桶.
+	for o.bucket.Count() > 0 {
 		o.save()
-		return o.onAfter(ctx)
 	}
 	return
 }
@@ -66,7 +85,7 @@ func (o *Manager) onAfter(ctx context.Context) (ignored bool) {
 func (o *Manager) onListen(ctx context.Context) (ignored bool) {
 	// 1. 定时保存.
 	//    每隔指定时长(默认: 350ms)上报一次链路跨度.
-	ticker := time.NewTicker(time.Duration(config.Config.TraceAdapterJaeger.Milliseconds) * time.Millisecond)
+	ticker := time.NewTicker(time.Duration(o.config.Snapshot().TraceAdapterJaeger.Milliseconds) * time.Millisecond)
 
 	// 2. 关闭定时.
 	defer ticker.Stop()
@@ -75,7 +94,7 @@ func (o *Manager) onListen(ctx context.Context) (ignored bool) {
 	for {
 		select {
 		case <-ticker.C:
-			go o.save()
+			o.saveAsync()
 		case <-ctx.Done():
 			return
 		}
@@ -87,18 +106,70 @@ func (o *Manager) onListen(ctx context.Context) (ignored bool) {
 // +---------------------------------------------------------------------------+
 
 func (o *Manager) init() *Manager {
-	o.bucket = adapters.NewBucket()
-	o.formatter = (&formatter{}).init()
+	o.bucket = adapters.NewBoundedBucket(func() *config.Bucket { return &o.config.Snapshot().TraceAdapterJaeger.Bucket })
+	o.formatter = (&formatter{config: o.config}).init()
 	o.name = fmt.Sprintf("trace-jaeger-manager")
 	o.keeper = base.NewKeeper(o.name).
 		After(o.onAfter).
 		Listen(o.onListen)
+
+	// 磁盘暂存.
+	// 作为子 Keeper 在后台重放上报失败的批次.
+	o.spool = spool.New("trace-jaeger-spool", func() *config.Spool { return &o.config.Snapshot().TraceAdapterJaeger.Spool }, o.replay)
+	o.keeper.Add(o.spool.Keeper())
 	return o
 }
 
+// 投递跨度.
+// 存在未重放的批次时直接写入暂存, 保证顺序; 上报失败时写入暂存.
+func (o *Manager) deliver(body []byte) error {
+	records := []spool.Record{{Value: body}}
+
+	// 1. 顺序写入.
+	if o.spool.Pending() {
+		return o.spool.Write(records)
+	}
+
+	// 2. 上报跨度.
+	err := o.post(body)
+	if err == nil {
+		return nil
+	}
+
+	// 3. 写入暂存.
+	if se := o.spool.Write(records); se == nil {
+		return nil
+	} else if se != spool.ErrDisabled {
+		return fmt.Errorf("%v, %v", err, se)
+	}
+	return err
+}
+
+// 上报已编码的跨度.
+func (o *Manager) post(body []byte) error {
+	w := NewWriter()
+	defer w.Release()
+	return w.Post(o.config, body)
+}
+
+// 重放暂存.
+func (o *Manager) replay(records []spool.Record) error {
+	for _, rec := range records {
+		if err := o.post(rec.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 保存跨度.
+// 串行执行, 批次按出桶顺序上报或写入暂存.
 func (o *Manager) save() {
+	o.saveMu.Lock()
+	defer o.saveMu.Unlock()
+
 	var (
-		buf, count = o.bucket.Popn(config.Config.TraceAdapterJaeger.Batch)
+		buf, count = o.bucket.Popn(o.config.Snapshot().TraceAdapterJaeger.Batch)
 		list       = make([]adapters.Span, 0)
 	)
 
@@ -109,7 +180,14 @@ func (o *Manager) save() {
 
 	// 2. 释放实例.
 	defer func() {
-		// 2.1 释放日志.
+		// 2.1 捕获异常.
+		if r := recover(); r != nil {
+			_, _ = fmt.Fprintf(base.Stderr(), "jaeger fatal: %v\n%s\n", r,
+				adapters.Backstack().String(),
+			)
+		}
+
+		// 2.2 释放日志.
 		for _, v := range list {
 			v.Release()
 		}
@@ -120,7 +198,25 @@ func (o *Manager) save() {
 		list = append(list, x.(adapters.Span))
 	}
 
-	v := NewWriter()
-	v.Send(o.formatter, list...)
-	v.Release()
+	// 4. 格式转换.
+	body, err := o.formatter.Byte(list...)
+	if err != nil {
+		_, _ = fmt.Fprintf(base.Stderr(), "jaeger formatter: %v\n", err)
+		return
+	}
+
+	// 5. 上报跨度.
+	if err = o.deliver(body); err != nil {
+		_, _ = fmt.Fprintf(base.Stderr(), "jaeger trace: %v\n", err)
+	}
+}
+
+// 后台保存.
+// 由 onAfter 等待完成.
+func (o *Manager) saveAsync() {
+	o.saving.Add(1)
+	go func() {
+		defer o.saving.Done()
+		o.save()
+	}()
 }
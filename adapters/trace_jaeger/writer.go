@@ -20,10 +20,10 @@ import (
 	"encoding/base64"
 	"fmt"
 	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/base"
 	"github.com/go-wares/log/config"
 	"github.com/valyala/fasthttp"
 	"net/http"
-	"os"
 	"sync"
 )
 
@@ -33,6 +33,10 @@ var (
 
 type (
 	Writer interface {
+		// Post
+		// 上报已编码的跨度.
+		Post(c *config.Configuration, body []byte) error
+
 		Release()
 		Send(formatter *formatter, lines ...adapters.Span)
 	}
@@ -65,7 +69,7 @@ func (o *writer) Send(formatter *formatter, lines ...adapters.Span) {
 	defer func() {
 		// 1.1 捕获异常.
 		if r := recover(); r != nil {
-			_, _ = fmt.Fprintf(os.Stderr, "jaeger fatal: %v\n%s\n", r,
+			_, _ = fmt.Fprintf(base.Stderr(), "jaeger fatal: %v\n%s\n", r,
 				adapters.Backstack().String(),
 			)
 		}
@@ -77,27 +81,42 @@ func (o *writer) Send(formatter *formatter, lines ...adapters.Span) {
 		err  error
 	)
 	if body, err = formatter.Byte(lines...); err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "jaeger formatter: %v\n", err)
+		_, _ = fmt.Fprintf(base.Stderr(), "jaeger formatter: %v\n", err)
+	}
+
+	// 3. 发送请求.
+	if err = o.Post(formatter.config, body); err != nil {
+		_, _ = fmt.Fprintf(base.Stderr(), "jaeger trace: %v\n", err)
 	}
+}
 
-	// 2.1 构建消息.
+// Post
+// 上报已编码的跨度.
+//
+// 网络错误或响应状态码不是 2xx 时返回错误.
+func (o *writer) Post(c *config.Configuration, body []byte) (err error) {
+	// 1. 构建消息.
+	c = c.Snapshot()
 	buf := bytes.NewBuffer(body)
 
-	// 3. 准备请求.
-	o.request.SetRequestURI(config.Config.TraceAdapterJaeger.Endpoint)
+	// 2. 准备请求.
+	o.request.SetRequestURI(c.TraceAdapterJaeger.Endpoint)
 	o.request.SetBodyStream(buf, buf.Len())
 	o.request.Header.SetMethod(http.MethodPost)
 	o.request.Header.SetContentType("application/x-thrift")
 
-	// 4. 基础鉴权.
-	if usr := config.Config.TraceAdapterJaeger.Username; usr != "" {
-		pwd := config.Config.TraceAdapterJaeger.Password
+	// 3. 基础鉴权.
+	if usr := c.TraceAdapterJaeger.Username; usr != "" {
+		pwd := c.TraceAdapterJaeger.Password
 		o.request.Header.Set("Authorization", fmt.Sprintf("Basic %s", base64.StdEncoding.EncodeToString([]byte(usr+":"+pwd))))
 	}
 
-	// 5. 发送请求.
+	// 4. 发送请求.
 	if err = fasthttp.Do(o.request, o.response); err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "jaeger trace: %v\n", err)
+		return
+	}
+	if code := o.response.StatusCode(); code < http.StatusOK || code >= http.StatusMultipleChoices {
+		err = fmt.Errorf("unexpected status code: %d", code)
 	}
 	return
 }
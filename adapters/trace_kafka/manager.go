@@ -0,0 +1,288 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-18
+
+package trace_kafka
+
+import (
+	"context"
+	"fmt"
+	"github.com/Shopify/sarama"
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/adapters/trace_jaeger"
+	"github.com/go-wares/log/adapters/trace_zipkin"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+	"sync"
+	"time"
+)
+
+type (
+	// ProducerFactory
+	// 生产者工厂.
+	//
+	// 按配置创建生产者, 替代默认的 sarama.NewSyncProducer.
+	ProducerFactory func(k *config.TraceAdapterKafka) (sarama.SyncProducer, error)
+
+	// Manager
+	// 链路(Kafka)管理器.
+	//
+	// 发送链路跨度到 Kafka 主题, 由采集端消费.
+	Manager struct {
+		bucket    *adapters.Bucket
+		closed    bool
+		config    *config.Configuration
+		factory   ProducerFactory
+		fixed     bool
+		formatter adapters.TraceFormatter
+		keeper    base.Keeper
+		mu        sync.RWMutex
+		name      string
+		producer  sarama.SyncProducer
+		saving    sync.WaitGroup
+	}
+)
+
+func New() adapters.TraceAdapter {
+	return NewWithConfig(config.Config)
+}
+
+// NewWithConfig
+// 基于指定配置创建适配器.
+func NewWithConfig(c *config.Configuration) adapters.TraceAdapter {
+	return (&Manager{config: c}).init()
+}
+
+// +---------------------------------------------------------------------------+
+// | Interface methods                                                         |
+// +---------------------------------------------------------------------------+
+
+func (o *Manager) Keeper() base.Keeper { return o.keeper }
+
+func (o *Manager) Send(span adapters.Span) {
+	if n := o.bucket.Add(span); n >= o.config.Snapshot().TraceAdapterKafka.Batch {
+		o.saveAsync()
+	}
+}
+
+// SetProducer
+// 设置生产者.
+//
+// 替代按配置创建的连接, 用于测试或共享连接; 适配器退出时不关闭.
+func (o *Manager) SetProducer(producer sarama.SyncProducer) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.fixed, o.producer = true, producer
+}
+
+// SetProducerFactory
+// 设置生产者工厂.
+//
+// 创建的生产者由适配器在退出时关闭.
+func (o *Manager) SetProducerFactory(factory ProducerFactory) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.factory = factory
+}
+
+// +---------------------------------------------------------------------------+
+// | Event methods                                                             |
+// +---------------------------------------------------------------------------+
+
+func (o *Manager) onAfter(ctx context.Context) (ignored bool) {
+	// 1. 等待发送.
+	o.saving.Wait()
+
+	// 2. 清空数据桶.
+	for o.bucket.Count() > 0 {
+		o.save()
+	}
+
+	// 3. 关闭连接.
+	o.closeProducer()
+	return
+}
+
+func (o *Manager) onBefore(ctx context.Context) (ignored bool) {
+	// 重新启动.
+	// 退出时关闭的连接在下次发送时重建.
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.closed = false
+	return
+}
+
+func (o *Manager) onListen(ctx context.Context) (ignored bool) {
+	// 1. 定时保存.
+	//    每隔指定时长(默认: 350ms)上报一次链路跨度.
+	ticker := time.NewTicker(time.Duration(o.config.Snapshot().TraceAdapterKafka.Milliseconds) * time.Millisecond)
+
+	// 2. 关闭定时.
+	defer ticker.Stop()
+
+	// 3. 监听信号.
+	for {
+		select {
+		case <-ticker.C:
+			o.saveAsync()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+func (o *Manager) init() *Manager {
+	o.bucket = adapters.NewBucket()
+	o.name = fmt.Sprintf("trace-kafka-manager")
+	o.keeper = base.NewKeeper(o.name).
+		After(o.onAfter).
+		Before(o.onBefore).
+		Listen(o.onListen)
+
+	// 跨度编码.
+	// 每个跨度编码为1条消息.
+	switch o.config.Snapshot().TraceAdapterKafka.Encoding {
+	case config.TraceEncodingThrift:
+		o.formatter = trace_jaeger.NewFormatter(o.config)
+	default:
+		o.formatter = trace_zipkin.NewSpanFormatter(o.config)
+	}
+	return o
+}
+
+// 关闭连接.
+// 外部设置的生产者不关闭; 关闭时不持有锁.
+func (o *Manager) closeProducer() {
+	producer := func() sarama.SyncProducer {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+
+		o.closed = true
+		if o.fixed {
+			return nil
+		}
+		p := o.producer
+		o.producer = nil
+		return p
+	}()
+
+	if producer != nil {
+		if err := producer.Close(); err != nil {
+			_, _ = fmt.Fprintf(base.Stderr(), "%s: %v\n", o.name, err)
+		}
+	}
+}
+
+func (o *Manager) getProducer() (sarama.SyncProducer, error) {
+	k := o.config.Snapshot().TraceAdapterKafka
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// 复用连接.
+	if o.producer != nil {
+		return o.producer, nil
+	}
+
+	// 已经关闭.
+	// 适配器退出后不再创建连接, 重新启动后恢复.
+	if o.closed {
+		return nil, fmt.Errorf("%s: producer closed", o.name)
+	}
+
+	// 创建连接.
+	factory := o.factory
+	if factory == nil {
+		factory = newProducer
+	}
+	p, err := factory(k)
+	if err != nil {
+		return nil, err
+	}
+	o.producer = p
+	return o.producer, nil
+}
+
+// 按配置创建生产者.
+func newProducer(k *config.TraceAdapterKafka) (sarama.SyncProducer, error) {
+	// 准备连接.
+	c := sarama.NewConfig()
+
+	// 超时配置.
+	c.Net.MaxOpenRequests = k.ProducerMaxRequest
+	c.Net.DialTimeout = time.Duration(k.ProducerTimeout) * time.Second
+	c.Net.ReadTimeout = time.Duration(k.ProducerTimeout) * time.Second
+	c.Net.WriteTimeout = time.Duration(k.ProducerTimeout) * time.Second
+
+	// 生产者配置.
+	c.Producer.RequiredAcks = sarama.NoResponse
+	c.Producer.Timeout = time.Duration(k.ProducerTimeout) * time.Second
+	c.Producer.Retry.Max = k.ProducerRetry
+	c.Producer.Retry.Backoff = 300 * time.Millisecond
+	c.Producer.Return.Errors = true
+	c.Producer.Return.Successes = true
+	c.Producer.CompressionLevel = sarama.CompressionLevelDefault
+
+	// 其它配置
+	c.ChannelBufferSize = k.ProducerBufferSize
+	return sarama.NewSyncProducer(k.Host, c)
+}
+
+func (o *Manager) save() {
+	var (
+		buf, count = o.bucket.Popn(o.config.Snapshot().TraceAdapterKafka.Batch)
+		list       = make([]adapters.Span, 0)
+		writer     *Writer
+	)
+
+	// 1. 空数据桶.
+	if count == 0 {
+		return
+	}
+
+	// 2. 释放实例.
+	defer func() {
+		// 2.1 释放跨度.
+		for _, v := range list {
+			v.Release()
+		}
+
+		// 2.2 释放实例.
+		if writer != nil {
+			writer.Release()
+		}
+	}()
+
+	// 3. 获取实例.
+	for _, x := range buf {
+		list = append(list, x.(adapters.Span))
+	}
+
+	writer = NewWriter()
+	writer.Send(o, list)
+}
+
+// 后台发送.
+// 由 onAfter 等待完成.
+func (o *Manager) saveAsync() {
+	o.saving.Add(1)
+	go func() {
+		defer o.saving.Done()
+		o.save()
+	}()
+}
@@ -0,0 +1,130 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-18
+
+package trace_kafka
+
+import (
+	"fmt"
+	"github.com/Shopify/sarama"
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/base"
+	"sync"
+)
+
+var (
+	writerPool sync.Pool
+)
+
+type (
+	// Writer
+	// 写跨度.
+	//
+	// 上报跨度到 Kafka, 每个跨度一条消息, 以链路ID为消息键.
+	Writer struct{}
+)
+
+// NewWriter
+// 获取写实例.
+func NewWriter() *Writer {
+	// 1. 池中获取.
+	if g := writerPool.Get(); g != nil {
+		return g.(*Writer).before()
+	}
+
+	// 2. 新建实例.
+	g := (&Writer{}).init()
+	return g.before()
+}
+
+// Release
+// 释放实例.
+func (o *Writer) Release() {
+	o.after()
+	writerPool.Put(o)
+}
+
+// Send
+// 批量发送过程.
+//
+// 格式化失败的跨度逐条打印并跳过, 其它跨度照常发送.
+func (o *Writer) Send(manager *Manager, list []adapters.Span) {
+	var (
+		err      error
+		k        = manager.config.Snapshot().TraceAdapterKafka
+		msg      = make([]*sarama.ProducerMessage, 0)
+		producer sarama.SyncProducer
+	)
+
+	// 1. 后置执行.
+	defer func() {
+		// 1.1 捕获异常.
+		if v := recover(); v != nil {
+			_, _ = fmt.Fprintf(base.Stderr(), "%v, topic: %s, host: %v\n%s\n",
+				v,
+				k.Topic,
+				k.Host,
+				adapters.Backstack().String(),
+			)
+		}
+
+		// 1.2 打印错误.
+		if err != nil {
+			_, _ = fmt.Fprintf(base.Stderr(), "%v topic: %s, host: %v\n",
+				err,
+				k.Topic,
+				k.Host,
+			)
+		}
+	}()
+
+	// 2. 格式消息.
+	for _, span := range list {
+		// 2.1 消息正文.
+		//     单个跨度失败时跳过.
+		buf, fe := manager.formatter.Byte(span)
+		if fe != nil {
+			_, _ = fmt.Fprintf(base.Stderr(), "%v span: %s, topic: %s\n",
+				fe,
+				span.SpanId(),
+				k.Topic,
+			)
+			continue
+		}
+
+		// 2.2 消息结构.
+		//     同一链路的跨度进入同一分区.
+		msg = append(msg, &sarama.ProducerMessage{
+			Topic: k.Topic,
+			Key:   sarama.StringEncoder(span.Trace().TraceId().String()),
+			Value: sarama.ByteEncoder(buf),
+		})
+	}
+
+	// 3. 发送过程.
+	if len(msg) == 0 {
+		return
+	}
+	if producer, err = manager.getProducer(); err == nil {
+		err = producer.SendMessages(msg)
+	}
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+func (o *Writer) after() *Writer  { return o }
+func (o *Writer) before() *Writer { return o }
+func (o *Writer) init() *Writer   { return o }
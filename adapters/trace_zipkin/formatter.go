@@ -0,0 +1,201 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-18
+
+package trace_zipkin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/config"
+)
+
+type (
+	// Span
+	// Zipkin v2 跨度结构.
+	//
+	//   {
+	//       "traceId": "5af7183fb1d4cf5f5af7183fb1d4cf5f",
+	//       "id": "6b221d5bc9e6496c",
+	//       "parentId": "5af7183fb1d4cf5f",
+	//       "name": "span name",
+	//       "timestamp": 1684113011234000,
+	//       "duration": 1234,
+	//       "localEndpoint": {"serviceName": "go-wares-log"},
+	//       "annotations": [{"timestamp": 1684113011234100, "value": "[INFO] text"}],
+	//       "tags": {"key": "value"}
+	//   }
+	Span struct {
+		TraceId       string        `json:"traceId"`
+		Id            string        `json:"id"`
+		ParentId      string        `json:"parentId,omitempty"`
+		Name          string        `json:"name"`
+		Timestamp     int64         `json:"timestamp"`
+		Duration      int64         `json:"duration"`
+		LocalEndpoint *Endpoint     `json:"localEndpoint,omitempty"`
+		Annotations   []*Annotation `json:"annotations,omitempty"`
+		Tags          Tags          `json:"tags,omitempty"`
+	}
+
+	// Annotation
+	// 跨度日志.
+	Annotation struct {
+		Timestamp int64  `json:"timestamp"`
+		Value     string `json:"value"`
+	}
+
+	// Endpoint
+	// 服务节点.
+	Endpoint struct {
+		ServiceName string `json:"serviceName"`
+		Ipv4        string `json:"ipv4,omitempty"`
+	}
+
+	// Tags
+	// 跨度标签, 仅支持字符串值.
+	Tags map[string]string
+
+	formatter struct {
+		config *config.Configuration
+	}
+
+	spanFormatter struct {
+		*formatter
+	}
+)
+
+// NewFormatter
+// 创建Zipkin v2 JSON格式化.
+func NewFormatter(c *config.Configuration) adapters.TraceFormatter {
+	return (&formatter{config: c}).init()
+}
+
+// NewSpanFormatter
+// 创建Zipkin v2 JSON单跨度格式化.
+//
+// 每个跨度编码为1个JSON对象, 多个跨度以换行分隔; 用于每个跨度一条消息的场景,
+// 如: Kafka.
+func NewSpanFormatter(c *config.Configuration) adapters.TraceFormatter {
+	return &spanFormatter{formatter: (&formatter{config: c}).init()}
+}
+
+func (o *formatter) Byte(vs ...adapters.Span) ([]byte, error) {
+	return json.Marshal(o.buildSpans(vs...))
+}
+
+func (o *spanFormatter) Byte(vs ...adapters.Span) ([]byte, error) {
+	var (
+		buf      bytes.Buffer
+		endpoint = o.buildEndpoint()
+	)
+	for i, sp := range vs {
+		b, err := json.Marshal(o.buildSpan(sp, endpoint))
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		buf.Write(b)
+	}
+	return buf.Bytes(), nil
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+func (o *formatter) buildAnnotations(list []*adapters.Line) []*Annotation {
+	annotations := make([]*Annotation, 0)
+
+	for _, x := range list {
+		value := fmt.Sprintf("[%s] %s", x.Level, x.Text)
+		if x.Attr.Count() > 0 {
+			value = fmt.Sprintf("%s %s", value, x.Attr.Json())
+		}
+
+		annotations = append(annotations, &Annotation{
+			Timestamp: x.Time.UnixMicro(),
+			Value:     value,
+		})
+	}
+
+	if len(annotations) > 0 {
+		return annotations
+	}
+	return nil
+}
+
+func (o *formatter) buildEndpoint() *Endpoint {
+	c := o.config.Snapshot()
+	v := &Endpoint{ServiceName: c.TraceAdapterZipkin.Topic}
+	if len(c.Addr) > 0 {
+		v.Ipv4 = c.Addr[0]
+	}
+	return v
+}
+
+func (o *formatter) buildSpan(sp adapters.Span, endpoint *Endpoint) *Span {
+	span := &Span{
+		TraceId:       sp.Trace().TraceId().String(),
+		Id:            sp.SpanId().String(),
+		Name:          sp.Name(),
+		Timestamp:     sp.StartTime().UnixMicro(),
+		Duration:      sp.EndTime().Sub(sp.StartTime()).Microseconds(),
+		LocalEndpoint: endpoint,
+	}
+
+	if pid := sp.ParentSpanId(); pid != nil {
+		span.ParentId = pid.String()
+	}
+
+	span.Annotations = o.buildAnnotations(sp.Logs())
+	span.Tags = o.buildTags(adapters.GetResource(), sp.Attr())
+	return span
+}
+
+func (o *formatter) buildSpans(sps ...adapters.Span) []*Span {
+	var (
+		endpoint = o.buildEndpoint()
+		list     = make([]*Span, 0)
+	)
+	for _, sp := range sps {
+		list = append(list, o.buildSpan(sp, endpoint))
+	}
+	return list
+}
+
+func (o *formatter) buildTags(attrs ...adapters.Attr) Tags {
+	tags := make(Tags)
+
+	for _, attr := range attrs {
+		for k, v := range attr {
+			switch s := v.(type) {
+			case string:
+				tags[k] = s
+			default:
+				tags[k] = fmt.Sprintf("%v", v)
+			}
+		}
+	}
+
+	if len(tags) > 0 {
+		return tags
+	}
+	return nil
+}
+
+func (o *formatter) init() *formatter { return o }
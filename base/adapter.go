@@ -15,11 +15,27 @@
 
 package base
 
+import (
+	"strings"
+)
+
 type (
 	// LogAdapter
 	// 日志适配器.
 	LogAdapter string
 
+	// LogAdapters
+	// 日志适配器列表.
+	//
+	// 配置时既可以是单个值, 也可以是列表或逗号分隔的字符串.
+	//
+	//   log_adapter: term
+	//   log_adapter: term, file
+	//   log_adapter:
+	//     - term
+	//     - file
+	LogAdapters []LogAdapter
+
 	// TraceAdapter
 	// 调用链适配器.
 	TraceAdapter string
@@ -29,10 +45,48 @@ const (
 	LogTerm  LogAdapter = "term"
 	LogFile  LogAdapter = "file"
 	LogKafka LogAdapter = "kafka"
+	LogOtlp  LogAdapter = "otlp"
 )
 
 const (
 	TraceJaeger TraceAdapter = "jaeger"
 	TraceZipkin TraceAdapter = "zipkin"
 	TraceKafka  TraceAdapter = "kafka"
+	TraceOtlp   TraceAdapter = "otlp"
 )
+
+// Contains
+// 是否包含指定适配器.
+func (o LogAdapters) Contains(adapter LogAdapter) bool {
+	for _, v := range o {
+		if v == adapter {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmarshalYAML
+// 解析 YAML 配置.
+func (o *LogAdapters) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var (
+		list []string
+		str  string
+	)
+
+	// 1. 单值或逗号分隔.
+	if err := unmarshal(&str); err == nil {
+		list = strings.Split(str, ",")
+	} else if err = unmarshal(&list); err != nil {
+		return err
+	}
+
+	// 2. 去重去空.
+	*o = make(LogAdapters, 0)
+	for _, s := range list {
+		if v := LogAdapter(strings.TrimSpace(s)); v != "" && !o.Contains(v) {
+			*o = append(*o, v)
+		}
+	}
+	return nil
+}
@@ -18,7 +18,6 @@ package base
 import (
 	"context"
 	"fmt"
-	"os"
 	"sync"
 	"time"
 )
@@ -195,10 +194,16 @@ func (o *keeper) Start(ctx context.Context) (err error) {
 		}
 
 		// 7.3 过程上下文.
-		o.ctx, o.cancel = context.WithCancel(ctx)
+		//     与子 Keeper 快照在同一锁内完成, 避免与运行中 Add 重复启动.
+		children := func() []Keeper {
+			o.mu.Lock()
+			defer o.mu.Unlock()
+			o.ctx, o.cancel = context.WithCancel(ctx)
+			return o.childrenList()
+		}()
 
 		// 7.3.1 启动子 Keeper.
-		o.childrenStart(o.ctx)
+		o.childrenStart(o.ctx, children)
 
 		// 7.3.2 启动过程执行器.
 		o.runHandlers(o.ctx, o.listListen)
@@ -218,7 +223,7 @@ func (o *keeper) Stop() {
 	defer o.mu.Unlock()
 
 	if o.ctx != nil && o.ctx.Err() == nil {
-		o.restart = true
+		o.restart = false
 		o.cancel()
 	}
 }
@@ -250,6 +255,12 @@ func (o *keeper) Add(v Keeper) (yes bool) {
 	v.SetParent(o)
 
 	o.children[v.Name()] = v
+
+	// 3. 运行中添加.
+	//    立即在当前过程上下文中启动子 Keeper.
+	if o.ctx != nil && o.ctx.Err() == nil {
+		o.childrenStart(o.ctx, []Keeper{v})
+	}
 	return true
 }
 
@@ -303,25 +314,32 @@ func (o *keeper) Panic(handler KeeperPanicHandler) Keeper {
 // | Access methods                                                            |
 // +---------------------------------------------------------------------------+
 
-func (o *keeper) childrenStart(ctx context.Context) {
-	for _, v := range func() map[string]Keeper {
-		o.mu.RLock()
-		defer o.mu.RUnlock()
-		return o.children
-	}() {
+// 子 Keeper 快照.
+//
+// 调用方须持有锁.
+func (o *keeper) childrenList() []Keeper {
+	list := make([]Keeper, 0, len(o.children))
+	for _, v := range o.children {
+		list = append(list, v)
+	}
+	return list
+}
+
+func (o *keeper) childrenStart(ctx context.Context, children []Keeper) {
+	for _, v := range children {
 		go func(k Keeper) {
 			if err := k.Start(ctx); err != nil {
-				_, _ = fmt.Fprintf(os.Stderr, fmt.Sprintf("%s start child: %v", o.name, err))
+				_, _ = fmt.Fprintf(Stderr(), fmt.Sprintf("%s start child: %v", o.name, err))
 			}
 		}(v)
 	}
 }
 
 func (o *keeper) childrenWaiter() bool {
-	for _, v := range func() map[string]Keeper {
+	for _, v := range func() []Keeper {
 		o.mu.RLock()
 		defer o.mu.RUnlock()
-		return o.children
+		return o.childrenList()
 	}() {
 		if !v.Stopped() {
 			time.Sleep(time.Millisecond * 3)
@@ -358,7 +376,7 @@ func (o *keeper) runHandlers(ctx context.Context, handlers []KeeperHandler) (ign
 			if o.panicHandler != nil {
 				o.panicHandler(ctx, v)
 			} else {
-				_, _ = fmt.Fprintf(os.Stderr, fmt.Sprintf("%s runtime fatal: %v", o.name, v))
+				_, _ = fmt.Fprintf(Stderr(), fmt.Sprintf("%s runtime fatal: %v", o.name, v))
 			}
 		}
 	}()
@@ -50,10 +50,23 @@ func (x Level) LogLevel() (l Level, ll LogLevel) {
 		}
 	}
 
+	// 关闭或无效配置.
+	l = Level(s)
 	ll = Off
 	return
 }
 
+// Valid
+// 是否为有效级别.
+//
+// 未配置(默认 INFO)和 OFF 均为有效级别.
+func (x Level) Valid() bool {
+	if _, ll := x.LogLevel(); ll != Off {
+		return true
+	}
+	return strings.EqualFold(string(x), "OFF")
+}
+
 const (
 	Off LogLevel = iota
 	Fatal
@@ -0,0 +1,45 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-06-10
+
+package base
+
+import (
+	"os"
+	"sync/atomic"
+)
+
+var stderr atomic.Value
+
+type stderrFile struct{ file *os.File }
+
+// Stderr
+// 内部诊断输出.
+//
+// 适配器的错误等诊断信息写入此处, 默认为 os.Stderr; 捕获标准错误输出期间为
+// 捕获前的原始输出, 诊断信息不会写回日志形成循环.
+func Stderr() *os.File {
+	if v, ok := stderr.Load().(stderrFile); ok && v.file != nil {
+		return v.file
+	}
+	return os.Stderr
+}
+
+// SetStderr
+// 设置内部诊断输出.
+//
+// 为 nil 时还原为 os.Stderr.
+func SetStderr(file *os.File) {
+	stderr.Store(stderrFile{file: file})
+}
@@ -0,0 +1,85 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-06-03
+
+package config
+
+import (
+	"github.com/go-wares/log/base"
+)
+
+type (
+	// OverflowPolicy
+	// 数据桶溢出策略.
+	OverflowPolicy string
+)
+
+const (
+	OverflowBlock      OverflowPolicy = "block"
+	OverflowDropLevel  OverflowPolicy = "drop_level"
+	OverflowDropNewest OverflowPolicy = "drop_newest"
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+)
+
+type (
+	// Bucket
+	// 数据桶容量配置.
+	//
+	// 内嵌于文件、消息及 Jaeger 适配器配置, 下游阻塞时限制内存中积压的数量.
+	//
+	//   log_adapter_file:
+	//     capacity: 10000
+	//     overflow: drop_level
+	//     overflow_level: WARN
+	Bucket struct {
+		// 最大容量.
+		//
+		// - 默认：0
+		// - 说明：0 或负数表示不限制; 配置正数时超出部分按溢出策略处理, 首次
+		//   丢弃时打印提示, 丢弃数量见 Logger.Dropped.
+		Capacity int `yaml:"capacity" json:"capacity"`
+
+		// 溢出策略.
+		//
+		// - 默认：drop_newest
+		// - 支持：block (阻塞等待, 超时后丢弃新数据), drop_newest (丢弃新数据),
+		//   drop_oldest (丢弃最早的数据), drop_level (丢弃低于 overflow_level 的
+		//   新日志, 不低于的日志丢弃最早的数据; 链路跨度按 drop_newest 处理)
+		// - 说明：多个日志适配器依次发送, 其中一个阻塞时拖慢全部适配器, 故配置
+		//   多个日志适配器时不支持 block.
+		Overflow OverflowPolicy `yaml:"overflow" json:"overflow"`
+
+		// 阻塞超时.
+		// 溢出策略为 block 时最多等待N(默认: 100)毫秒.
+		OverflowMilliseconds int64 `yaml:"overflow_milliseconds" json:"overflow_milliseconds"`
+
+		// 保留级别.
+		// 溢出策略为 drop_level 时不丢弃的最低级别(默认: WARN).
+		OverflowLevel    base.Level    `yaml:"overflow_level" json:"overflow_level"`
+		OverflowLogLevel base.LogLevel `yaml:"-" json:"-"`
+	}
+)
+
+func (o *Bucket) defaults() {
+	if o.Overflow == "" {
+		o.Overflow = OverflowDropNewest
+	}
+	if o.OverflowMilliseconds == 0 {
+		o.OverflowMilliseconds = defaultBucketOverflowMilliseconds
+	}
+	if o.OverflowLevel == "" {
+		o.OverflowLevel = defaultBucketOverflowLevel
+	}
+	o.OverflowLevel, o.OverflowLogLevel = o.OverflowLevel.LogLevel()
+}
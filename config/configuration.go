@@ -16,10 +16,14 @@
 package config
 
 import (
+	"fmt"
 	"github.com/go-wares/log/base"
 	"gopkg.in/yaml.v3"
 	"net"
 	"os"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
@@ -52,10 +56,19 @@ type (
 		// 当包加载完成后, 立即启动监听.
 		AutoStart *bool `yaml:"auto_start" json:"auto_start"`
 
+		// 热加载.
+		// 监听配置文件变更, 运行期间重新加载配置.
+		//
+		// - 默认：false
+		// - 频率：每隔 N(默认: 1000ms) 检查1次文件修改时间
+		Watch             *bool `yaml:"watch" json:"watch"`
+		WatchMilliseconds int   `yaml:"watch_milliseconds" json:"watch_milliseconds"`
+
 		// 日志级别.
 		//
 		// - 默认: INFO
 		// - 支持: DEBUG, INFO, WARN, ERROR, FATAL, OFF
+		// - 说明: 此处为配置值, 运行期间通过 SetLevel 修改后以 GetLevel 为准.
 		Level         base.Level    `yaml:"level" json:"level"`
 		LogLevel      base.LogLevel `yaml:"-" json:"log_level"`
 		LogTimeFormat string        `yaml:"log_time_format" json:"log_time_format"`
@@ -63,20 +76,48 @@ type (
 		// 日志适配器.
 		//
 		// - 默认：term
-		// - 支持：term, file, kafka
-		LogAdapter                                base.LogAdapter  `yaml:"log_adapter" json:"log_adapter"`
-		LogAdapterTerm                            *LogAdapterTerm  `yaml:"log_adapter_term" json:"log_adapter_term"`
-		LogAdapterFile                            *LogAdapterFile  `yaml:"log_adapter_file" json:"log_adapter_file"`
-		LogAdapterKafka                           *LogAdapterKafka `yaml:"log_adapter_kafka" json:"log_adapter_kafka"`
-		debugOn, infoOn, warnOn, errorOn, fatalOn bool
+		// - 支持：term, file, kafka, otlp
+		// - 说明：可同时配置多个, 每行日志发送到全部适配器.
+		LogAdapter      base.LogAdapters `yaml:"log_adapter" json:"log_adapter"`
+		LogAdapterTerm  *LogAdapterTerm  `yaml:"log_adapter_term" json:"log_adapter_term"`
+		LogAdapterFile  *LogAdapterFile  `yaml:"log_adapter_file" json:"log_adapter_file"`
+		LogAdapterKafka *LogAdapterKafka `yaml:"log_adapter_kafka" json:"log_adapter_kafka"`
+		LogAdapterOtlp  *LogAdapterOtlp  `yaml:"log_adapter_otlp" json:"log_adapter_otlp"`
+
+		// 运行级别.
+		// 以原子操作读写, 由 SetLevel/SetLevelFor 修改.
+		logLevel    int32
+		levelMu     sync.Mutex
+		levelRevert base.LogLevel
+		levelSeq    uint64
+		levelTimer  *time.Timer
+
+		// 配置文件.
+		// 热加载时重新读取此文件; 仅全局配置叠加环境变量.
+		changeHandlers []ChangeHandler
+		env            bool
+		path           string
+		reloadMu       sync.Mutex
+
+		// 配置快照.
+		// 热加载时整体发布新的配置, 发布后不再修改; 见 Snapshot.
+		snapshot atomic.Value
+
+		// 日志路由.
+		//
+		// - 默认：无, 发往全部适配器
+		// - 说明：按顺序匹配, 首个命中的规则决定日志发往哪些适配器.
+		LogRoute []*LogRoute `yaml:"log_route" json:"log_route"`
 
 		// 链路适配器.
 		//
 		// - 默认：无
-		// - 支持：jaeger, zipkin
+		// - 支持：jaeger, zipkin, kafka, otlp
 		TraceAdapter        base.TraceAdapter   `yaml:"trace_adapter" json:"trace_adapter"`
 		TraceAdapterSyncLog *bool               `yaml:"trace_adapter_sync_log" json:"trace_adapter_sync_log"`
 		TraceAdapterJaeger  *TraceAdapterJaeger `yaml:"trace_adapter_jaeger" json:"trace_adapter_jaeger"`
+		TraceAdapterKafka   *TraceAdapterKafka  `yaml:"trace_adapter_kafka" json:"trace_adapter_kafka"`
+		TraceAdapterOtlp    *TraceAdapterOtlp   `yaml:"trace_adapter_otlp" json:"trace_adapter_otlp"`
 		TraceAdapterZipkin  *TraceAdapterZipkin `yaml:"trace_adapter_zipkin" json:"trace_adapter_zipkin"`
 	}
 )
@@ -85,11 +126,58 @@ type (
 // | Switch methods                                                            |
 // +---------------------------------------------------------------------------+
 
-func (o *Configuration) DebugOn() bool { return o.debugOn }
-func (o *Configuration) InfoOn() bool  { return o.infoOn }
-func (o *Configuration) WarnOn() bool  { return o.warnOn }
-func (o *Configuration) ErrorOn() bool { return o.errorOn }
-func (o *Configuration) FatalOn() bool { return o.fatalOn }
+func (o *Configuration) DebugOn() bool { return o.levelOn(base.Debug) }
+func (o *Configuration) InfoOn() bool  { return o.levelOn(base.Info) }
+func (o *Configuration) WarnOn() bool  { return o.levelOn(base.Warn) }
+func (o *Configuration) ErrorOn() bool { return o.levelOn(base.Error) }
+func (o *Configuration) FatalOn() bool { return o.levelOn(base.Fatal) }
+
+// LevelOn
+// 是否输出指定级别的日志.
+func (o *Configuration) LevelOn(level base.LogLevel) bool { return o.levelOn(level) }
+
+// LogAdapterOn
+// 适配器是否接收指定级别的日志.
+func (o *Configuration) LogAdapterOn(adapter base.LogAdapter, level base.LogLevel) bool {
+	c := o.Snapshot()
+	switch adapter {
+	case base.LogTerm:
+		return c.LogAdapterTerm.LogLevel >= level
+	case base.LogFile:
+		return c.LogAdapterFile.LogLevel >= level
+	case base.LogKafka:
+		return c.LogAdapterKafka.LogLevel >= level
+	case base.LogOtlp:
+		return c.LogAdapterOtlp.LogLevel >= level
+	}
+	return true
+}
+
+// LogRouteMatch
+// 匹配路由规则.
+//
+// 返回首个命中规则的目标适配器; 返回 nil 时表示未命中, 发往全部适配器.
+func (o *Configuration) LogRouteMatch(level base.LogLevel, fields map[string]interface{}, span string) base.LogAdapters {
+	for _, r := range o.Snapshot().LogRoute {
+		if r.Match(level, fields, span) {
+			return r.Adapter
+		}
+	}
+	return nil
+}
+
+// Snapshot
+// 当前配置.
+//
+// 热加载不修改已发布的配置, 而是整体发布新的快照; 适配器等运行期间的读取
+// 方须经此方法读取, 同一次处理内宜只读取一次. 未热加载时返回自身; 日志级别
+// 仍通过当前实例读取及设置.
+func (o *Configuration) Snapshot() *Configuration {
+	if s, ok := o.snapshot.Load().(*Configuration); ok {
+		return s
+	}
+	return o
+}
 
 // +---------------------------------------------------------------------------+
 // | Access methods                                                            |
@@ -117,13 +205,17 @@ func (o *Configuration) defaults() {
 		o.AutoStart = &defaultAutoStart
 	}
 
+	// 热加载.
+	if o.Watch == nil {
+		o.Watch = &defaultWatch
+	}
+	if o.WatchMilliseconds == 0 {
+		o.WatchMilliseconds = defaultWatchMilliseconds
+	}
+
 	// 日志级别.
 	o.Level, o.LogLevel = o.Level.LogLevel()
-	o.debugOn = o.LogLevel >= base.Debug // 4 >= 5
-	o.infoOn = o.LogLevel >= base.Info   // 4 >= 4
-	o.warnOn = o.LogLevel >= base.Warn   // 4 >= 3
-	o.errorOn = o.LogLevel >= base.Error // 4 >= 2
-	o.fatalOn = o.LogLevel >= base.Fatal // 4 >= 1
+	o.storeLevel(o.LogLevel)
 
 	// 时间格式.
 	if o.LogTimeFormat == "" {
@@ -131,8 +223,8 @@ func (o *Configuration) defaults() {
 	}
 
 	// 适配器.
-	if o.LogAdapter == "" {
-		o.LogAdapter = defaultLogAdapter
+	if len(o.LogAdapter) == 0 {
+		o.LogAdapter = base.LogAdapters{defaultLogAdapter}
 	}
 
 	// 终端适配器.
@@ -154,6 +246,22 @@ func (o *Configuration) defaults() {
 	}
 	o.LogAdapterKafka.defaults(o)
 
+	// OpenTelemetry 适配器.
+	if o.LogAdapterOtlp == nil {
+		o.LogAdapterOtlp = &LogAdapterOtlp{}
+	}
+	o.LogAdapterOtlp.defaults(o)
+
+	// 日志路由.
+	routes := make([]*LogRoute, 0)
+	for _, r := range o.LogRoute {
+		if r != nil {
+			r.defaults(o)
+			routes = append(routes, r)
+		}
+	}
+	o.LogRoute = routes
+
 	// 同步日志.
 	// 当记录链路日志时, 是否同步一份到日志系统.
 	if o.TraceAdapterSyncLog == nil {
@@ -166,6 +274,18 @@ func (o *Configuration) defaults() {
 	}
 	o.TraceAdapterJaeger.defaults(o)
 
+	// Kafka 适配器.
+	if o.TraceAdapterKafka == nil {
+		o.TraceAdapterKafka = &TraceAdapterKafka{}
+	}
+	o.TraceAdapterKafka.defaults(o)
+
+	// OpenTelemetry 适配器.
+	if o.TraceAdapterOtlp == nil {
+		o.TraceAdapterOtlp = &TraceAdapterOtlp{}
+	}
+	o.TraceAdapterOtlp.defaults(o)
+
 	// Zipkin 适配器.
 	if o.TraceAdapterZipkin == nil {
 		o.TraceAdapterZipkin = &TraceAdapterZipkin{}
@@ -174,7 +294,19 @@ func (o *Configuration) defaults() {
 }
 
 func (o *Configuration) init() *Configuration {
-	o.scan().defaults()
+	o.env = true
+	o.scan()
+
+	// 环境变量.
+	if err := o.overlay(); err != nil {
+		_, _ = fmt.Fprintf(base.Stderr(), "invalid log config: %v\n", err)
+	}
+
+	// 默认值及校验.
+	o.defaults()
+	if err := o.Validate(); err != nil {
+		_, _ = fmt.Fprintf(base.Stderr(), "%v\n", err)
+	}
 	return o
 }
 
@@ -199,13 +331,31 @@ func (o *Configuration) ipaddr() {
 }
 
 func (o *Configuration) scan() *Configuration {
-	for _, path := range []string{"config/log.yaml", "../config/log.yaml"} {
+	paths := []string{"config/log.yaml", "../config/log.yaml"}
+
+	// 指定文件.
+	// 环境变量 GO_WARES_LOG_CONFIG 优先.
+	if path := os.Getenv(EnvConfig); path != "" {
+		paths = []string{path}
+	}
+
+	for _, path := range paths {
 		body, err := os.ReadFile(path)
 		if err == nil {
 			if yaml.Unmarshal(body, o) == nil {
+				o.path = path
 				break
 			}
 		}
 	}
 	return o
 }
+
+// 适配器级别.
+// 未配置时不限制, 仅受全局级别约束.
+func adapterLevel(level base.Level) (base.Level, base.LogLevel) {
+	if level == "" {
+		return level, base.Debug
+	}
+	return level.LogLevel()
+}
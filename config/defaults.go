@@ -32,9 +32,13 @@ const (
 )
 
 var (
-	defaultAutoStart           = true
-	defaultLogAdapterTermColor = true
-	defaultTraceAdapterSyncLog = true
+	defaultAutoStart                    = true
+	defaultLogAdapterFileCompress       = false
+	defaultLogAdapterFileReopenOnSighup = false
+	defaultLogAdapterKafkaHeaders       = true
+	defaultLogAdapterTermColor          = true
+	defaultTraceAdapterSyncLog          = true
+	defaultWatch                        = false
 )
 
 const (
@@ -47,14 +51,47 @@ const (
 	defaultLogAdapterFileFolder       = "2006-01"
 	defaultLogAdapterFileName         = "2006-01-02"
 
+	defaultLogAdapterFileSyncMilliseconds = 1000
+
+	defaultLogAdapterFileDirMode  FileMode = "0755"
+	defaultLogAdapterFileFileMode FileMode = "0644"
+
+	defaultLogAdapterFileErrorLevel base.Level = "WARN"
+
+	defaultBucketOverflowLevel        base.Level = "WARN"
+	defaultBucketOverflowMilliseconds            = 100
+
+	defaultSpoolMaxSize         = 512
+	defaultSpoolMilliseconds    = 1000
+	defaultSpoolMaxMilliseconds = 60000
+
 	defaultLogAdapterKafkaBatch        = 100
 	defaultLogAdapterKafkaMilliseconds = 350
 	defaultLogAdapterKafkaHost         = "127.0.0.1:9092"
 	defaultLogAdapterKafkaTopic        = "go-wares-log"
+	defaultLogAdapterKafkaVersion      = "1.0.0"
+
+	defaultLogAdapterOtlpBatch        = 100
+	defaultLogAdapterOtlpMilliseconds = 350
+	defaultLogAdapterOtlpEndpoint     = "http://localhost:4318/v1/logs"
 
 	defaultLogTimeFormat = "2006-01-02 15:04:05.999"
 
 	defaultTraceAdapterJaegerBatch        = 100
 	defaultTraceAdapterJaegerMilliseconds = 350
 	defaultTraceAdapterJaegerTopic        = "logs"
+
+	defaultTraceAdapterKafkaBatch        = 100
+	defaultTraceAdapterKafkaMilliseconds = 350
+	defaultTraceAdapterKafkaTopic        = "go-wares-trace"
+
+	defaultTraceAdapterOtlpBatch        = 100
+	defaultTraceAdapterOtlpMilliseconds = 350
+	defaultTraceAdapterOtlpEndpoint     = "http://localhost:4318/v1/traces"
+
+	defaultTraceAdapterZipkinBatch        = 100
+	defaultTraceAdapterZipkinMilliseconds = 350
+	defaultTraceAdapterZipkinEndpoint     = "http://localhost:9411/api/v2/spans"
+
+	defaultWatchMilliseconds = 1000
 )
@@ -0,0 +1,190 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-24
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// EnvPrefix
+	// 环境变量前缀.
+	//
+	// 环境变量名由前缀和 YAML 配置路径组成, 路径以下划线连接并转大写, 路径以
+	// log_ 开头时省略, 如:
+	//
+	//   level                  -> GO_WARES_LOG_LEVEL
+	//   log_adapter            -> GO_WARES_LOG_ADAPTER
+	//   log_adapter_kafka.host -> GO_WARES_LOG_ADAPTER_KAFKA_HOST
+	//   trace_adapter          -> GO_WARES_LOG_TRACE_ADAPTER
+	//
+	//   log_adapter_kafka.sasl.password -> GO_WARES_LOG_ADAPTER_KAFKA_SASL_PASSWORD
+	//   log_adapter_kafka.capacity      -> GO_WARES_LOG_ADAPTER_KAFKA_CAPACITY
+	EnvPrefix = "GO_WARES_LOG_"
+
+	// EnvConfig
+	// 配置文件路径.
+	//
+	// 包加载时优先读取此环境变量指定的配置文件.
+	EnvConfig = EnvPrefix + "CONFIG"
+)
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+// 环境变量覆盖.
+//
+// 支持字符串、整数、布尔、列表(逗号分隔)和字典(k=v 逗号分隔); 频率配置(如:
+// milliseconds)同时支持时长格式, 如: 500ms, 1s.
+func (o *Configuration) overlay() error {
+	return overlayStruct(reflect.ValueOf(o).Elem(), EnvPrefix, "")
+}
+
+func overlayStruct(v reflect.Value, prefix, path string) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		var (
+			field = t.Field(i)
+			opts  = strings.Split(field.Tag.Get("yaml"), ",")
+			tag   = opts[0]
+		)
+
+		// 1. 内嵌配置.
+		//    如: Bucket, Spool, 变量名与所在配置的字段同级.
+		if field.PkgPath == "" && field.Type.Kind() == reflect.Struct && len(opts) > 1 && opts[1] == "inline" {
+			if err := overlayStruct(v.Field(i), prefix, path); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// 2. 忽略字段.
+		if field.PkgPath != "" || tag == "" || tag == "-" {
+			continue
+		}
+
+		// 3. 变量名称.
+		key := tag
+		if path != "" {
+			key = path + "_" + tag
+		}
+		name := prefix + strings.ToUpper(strings.TrimPrefix(key, "log_"))
+
+		// 4. 子配置.
+		//    含指针及值类型, 如: log_adapter_kafka, log_adapter_kafka.sasl.
+		fv := v.Field(i)
+		if field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct {
+			if fv.IsNil() {
+				fv.Set(reflect.New(field.Type.Elem()))
+			}
+			if err := overlayStruct(fv.Elem(), prefix, key); err != nil {
+				return err
+			}
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct {
+			if err := overlayStruct(fv, prefix, key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// 5. 覆盖配置.
+		if s, ok := os.LookupEnv(name); ok {
+			if err := overlayValue(fv, strings.TrimSpace(s), tag); err != nil {
+				return fmt.Errorf("environment %s=%q: %v", name, s, err)
+			}
+		}
+	}
+	return nil
+}
+
+func overlayValue(v reflect.Value, s, tag string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil && strings.HasSuffix(tag, "milliseconds") {
+			var d time.Duration
+			if d, err = time.ParseDuration(s); err == nil {
+				n = d.Milliseconds()
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("malformed number")
+		}
+		v.SetInt(n)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("malformed boolean")
+		}
+		v.SetBool(b)
+
+	case reflect.Ptr:
+		if v.Type().Elem().Kind() != reflect.Bool {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("malformed boolean")
+		}
+		v.Set(reflect.ValueOf(&b))
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		list := reflect.MakeSlice(v.Type(), 0, 0)
+		for _, x := range strings.Split(s, ",") {
+			if x = strings.TrimSpace(x); x != "" {
+				list = reflect.Append(list, reflect.ValueOf(x).Convert(v.Type().Elem()))
+			}
+		}
+		v.Set(list)
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String || v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		m := reflect.MakeMap(v.Type())
+		for _, x := range strings.Split(s, ",") {
+			if x = strings.TrimSpace(x); x == "" {
+				continue
+			}
+			kv := strings.SplitN(x, "=", 2)
+			if len(kv) != 2 {
+				return fmt.Errorf("malformed pair %q, expect key=value", x)
+			}
+			m.SetMapIndex(reflect.ValueOf(strings.TrimSpace(kv[0])), reflect.ValueOf(strings.TrimSpace(kv[1])))
+		}
+		v.Set(m)
+
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
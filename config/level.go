@@ -0,0 +1,115 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-22
+
+package config
+
+import (
+	"fmt"
+	"github.com/go-wares/log/base"
+	"sync/atomic"
+	"time"
+)
+
+// GetLevel
+// 当前日志级别.
+func (o *Configuration) GetLevel() base.LogLevel {
+	return base.LogLevel(atomic.LoadInt32(&o.logLevel))
+}
+
+// SetLevel
+// 修改日志级别.
+//
+// 运行期间立即生效, 并取消 SetLevelFor 尚未执行的定时还原.
+//
+//	config.Config.SetLevel("DEBUG")
+func (o *Configuration) SetLevel(level base.Level) error {
+	return o.setLevel(level, 0)
+}
+
+// SetLevelFor
+// 临时修改日志级别.
+//
+// 指定时长后还原为修改前的级别; 还原前再次调用时, 重新计时且仍还原为首次修改
+// 前的级别.
+//
+//	config.Config.SetLevelFor("DEBUG", time.Minute * 10)
+func (o *Configuration) SetLevelFor(level base.Level, duration time.Duration) error {
+	if duration <= 0 {
+		return fmt.Errorf("invalid log level duration: %v", duration)
+	}
+	return o.setLevel(level, duration)
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+func (o *Configuration) levelOn(level base.LogLevel) bool {
+	return atomic.LoadInt32(&o.logLevel) >= int32(level)
+}
+
+// 定时还原.
+//
+// 计时器已被取消或替换时忽略.
+func (o *Configuration) revertLevel(seq uint64) {
+	o.levelMu.Lock()
+	defer o.levelMu.Unlock()
+
+	if o.levelTimer == nil || o.levelSeq != seq {
+		return
+	}
+
+	o.levelTimer = nil
+	o.storeLevel(o.levelRevert)
+}
+
+func (o *Configuration) setLevel(level base.Level, duration time.Duration) error {
+	if level == "" || !level.Valid() {
+		return fmt.Errorf("unknown log level: %q", level)
+	}
+
+	_, ll := level.LogLevel()
+
+	o.updateLevel(ll, duration)
+	return nil
+}
+
+func (o *Configuration) storeLevel(level base.LogLevel) {
+	atomic.StoreInt32(&o.logLevel, int32(level))
+}
+
+func (o *Configuration) updateLevel(ll base.LogLevel, duration time.Duration) {
+	o.levelMu.Lock()
+	defer o.levelMu.Unlock()
+
+	// 1. 取消还原.
+	revert := o.GetLevel()
+	if o.levelTimer != nil {
+		o.levelTimer.Stop()
+		o.levelTimer = nil
+		revert = o.levelRevert
+	}
+
+	// 2. 修改级别.
+	o.storeLevel(ll)
+
+	// 3. 定时还原.
+	o.levelSeq++
+	if duration > 0 {
+		seq := o.levelSeq
+		o.levelRevert = revert
+		o.levelTimer = time.AfterFunc(duration, func() { o.revertLevel(seq) })
+	}
+}
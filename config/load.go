@@ -0,0 +1,54 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-24
+
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+// Load
+// 从指定文件加载配置.
+//
+// 叠加环境变量并校验, 成功后将全部配置(含应用名称、版本号)作为快照发布到
+// Config 并通知变更, 此后热加载也读取此文件. 运行期间经 Config.Snapshot()
+// 读取加载后的配置.
+//
+//	if err := config.Load("/etc/app/log.yaml"); err != nil {
+//	    panic(err)
+//	}
+func Load(path string) error {
+	if path == "" {
+		return fmt.Errorf("config file not specified")
+	}
+
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	_, err = Config.reload(body, path, true)
+	return err
+}
+
+// LoadFromBytes
+// 从 YAML 内容加载配置.
+//
+// 叠加环境变量并校验, 成功后发布全部配置并通知变更.
+func LoadFromBytes(body []byte) error {
+	_, err := Config.reload(body, "", true)
+	return err
+}
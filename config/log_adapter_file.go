@@ -15,6 +15,54 @@
 
 package config
 
+import (
+	"github.com/go-wares/log/base"
+	"os"
+	"strconv"
+)
+
+type (
+	// FileFormat
+	// 文件日志格式.
+	FileFormat string
+)
+
+const (
+	FileFormatJson   FileFormat = "json"
+	FileFormatLogfmt FileFormat = "logfmt"
+	FileFormatText   FileFormat = "text"
+)
+
+type (
+	// FileMode
+	// 文件权限, 八进制字符串, 如: 0644.
+	FileMode string
+)
+
+// Perm
+// 转为文件权限.
+//
+// 无效值返回 0, 须先经 Validate 校验.
+func (o FileMode) Perm() os.FileMode {
+	n, err := strconv.ParseUint(string(o), 8, 32)
+	if err != nil || n > 0777 {
+		return 0
+	}
+	return os.FileMode(n)
+}
+
+type (
+	// FileSync
+	// 文件落盘策略.
+	FileSync string
+)
+
+const (
+	FileSyncBatch    FileSync = "batch"
+	FileSyncInterval FileSync = "interval"
+	FileSyncNever    FileSync = "never"
+)
+
 type (
 	// LogAdapterFile
 	// 文件适配器配置.
@@ -27,7 +75,16 @@ type (
 	//     path: ./logs
 	//     folder: 2006-01
 	//     name: 2006-01-02.log
+	//     format: json
+	//     error_name: 2006-01-02.error
 	LogAdapterFile struct {
+		// 最低级别.
+		//
+		// - 默认：不限制, 仅受全局 level 约束
+		// - 支持：DEBUG, INFO, WARN, ERROR, FATAL
+		Level    base.Level    `yaml:"level" json:"level"`
+		LogLevel base.LogLevel `yaml:"-" json:"-"`
+
 		// 批量阈值.
 		// 每次最多批量写入N(默认: 100)条日志.
 		Batch int `yaml:"batch" json:"batch"`
@@ -57,10 +114,88 @@ type (
 		//
 		// - 默认：log
 		Ext string `yaml:"ext" json:"ext"`
+
+		// 日志格式.
+		//
+		// - 默认：text
+		// - 支持：text, json, logfmt
+		// - 说明：json 与 Kafka 消息结构一致, 可由同一管道采集.
+		Format FileFormat `yaml:"format" json:"format"`
+
+		// 按大小切割.
+		//
+		// - 默认：0, 不切割
+		// - 单位：MB
+		// - 说明：文件超过此大小时重命名为带序号的文件, 如: 2023-05-13.1.log
+		MaxSize int `yaml:"max_size" json:"max_size"`
+
+		// 保留天数.
+		//
+		// - 默认：0, 不删除
+		// - 说明：删除修改时间早于N天的日志文件.
+		MaxAge int `yaml:"max_age" json:"max_age"`
+
+		// 保留数量.
+		//
+		// - 默认：0, 不限制
+		// - 说明：除当前写入的文件外, 最多保留N个日志文件, 优先删除最早的.
+		MaxCount int `yaml:"max_count" json:"max_count"`
+
+		// 压缩文件.
+		//
+		// - 默认：false
+		// - 说明：以 gzip 压缩已切割及过期(非当前时间段)的日志文件.
+		Compress *bool `yaml:"compress" json:"compress"`
+
+		// 落盘策略.
+		//
+		// - 默认：never, 由操作系统决定落盘时机
+		// - 支持：never, batch (每批写入后 fsync), interval (每隔N毫秒 fsync)
+		// - 频率：interval 时每隔 N(默认: 1000ms) 执行1次
+		Sync             FileSync `yaml:"sync" json:"sync"`
+		SyncMilliseconds int64    `yaml:"sync_milliseconds" json:"sync_milliseconds"`
+
+		// 信号重开.
+		//
+		// - 默认：false
+		// - 说明：收到 SIGHUP 信号时关闭已打开的文件, 下次写入时重新打开; 用于
+		//   logrotate 移动文件后写入新文件. 开启后进程不再因 SIGHUP 退出.
+		ReopenOnSighup *bool `yaml:"reopen_on_sighup" json:"reopen_on_sighup"`
+
+		// 文件权限.
+		//
+		// - 默认：文件 0644, 目录 0755
+		// - 说明：八进制, 创建文件及目录时使用, 受进程 umask 影响.
+		FileMode FileMode `yaml:"file_mode" json:"file_mode"`
+		DirMode  FileMode `yaml:"dir_mode" json:"dir_mode"`
+
+		// 当前文件链接.
+		//
+		// - 默认：空, 不创建
+		// - 说明：相对 path 的符号链接名称, 如: current.log; 写入文件变更时原子
+		//   地指向新文件, 便于 tail -f 跟踪.
+		Symlink string `yaml:"symlink" json:"symlink"`
+
+		// 错误日志文件.
+		//
+		// - 默认：空, 不写入
+		// - 说明：文件名模板, 与 name 相同按时间格式化, 如: 2006-01-02.error;
+		//   级别不低于 error_level(默认: WARN) 的日志同时写入此文件, 与主文件
+		//   位于同一目录, 各自独立切割.
+		ErrorName     string        `yaml:"error_name" json:"error_name"`
+		ErrorLevel    base.Level    `yaml:"error_level" json:"error_level"`
+		ErrorLogLevel base.LogLevel `yaml:"-" json:"-"`
+
+		// 数据桶容量.
+		// 见 Bucket, 如: capacity, overflow.
+		Bucket `yaml:",inline"`
 	}
 )
 
 func (o *LogAdapterFile) defaults(_ *Configuration) {
+	o.Level, o.LogLevel = adapterLevel(o.Level)
+	o.Bucket.defaults()
+
 	if o.Batch == 0 {
 		o.Batch = defaultLogAdapterFileBatch
 	}
@@ -79,4 +214,38 @@ func (o *LogAdapterFile) defaults(_ *Configuration) {
 	if o.Ext == "" {
 		o.Ext = defaultLogAdapterFileExt
 	}
+	if o.Format == "" {
+		o.Format = FileFormatText
+	}
+	if o.Compress == nil {
+		o.Compress = &defaultLogAdapterFileCompress
+	}
+	if o.Sync == "" {
+		o.Sync = FileSyncNever
+	}
+	if o.SyncMilliseconds == 0 {
+		o.SyncMilliseconds = defaultLogAdapterFileSyncMilliseconds
+	}
+	if o.ReopenOnSighup == nil {
+		o.ReopenOnSighup = &defaultLogAdapterFileReopenOnSighup
+	}
+	if o.FileMode == "" {
+		o.FileMode = defaultLogAdapterFileFileMode
+	}
+	if o.DirMode == "" {
+		o.DirMode = defaultLogAdapterFileDirMode
+	}
+	if o.ErrorLevel == "" {
+		o.ErrorLevel = defaultLogAdapterFileErrorLevel
+	}
+	o.ErrorLevel, o.ErrorLogLevel = o.ErrorLevel.LogLevel()
+}
+
+// 监听参数是否相同.
+// 刷新频率、落盘策略及信号重开变更时须重启适配器.
+func (o *LogAdapterFile) equalListen(n *LogAdapterFile) bool {
+	return o.Milliseconds == n.Milliseconds &&
+		o.Sync == n.Sync &&
+		o.SyncMilliseconds == n.SyncMilliseconds &&
+		*o.ReopenOnSighup == *n.ReopenOnSighup
 }
@@ -15,7 +15,167 @@
 
 package config
 
+import (
+	"github.com/go-wares/log/base"
+	"path"
+	"reflect"
+	"strings"
+)
+
 type (
+	// KafkaAcks
+	// 生产者确认模式.
+	KafkaAcks string
+
+	// KafkaCompression
+	// 消息压缩算法.
+	KafkaCompression string
+
+	// KafkaEncoding
+	// 消息编码.
+	KafkaEncoding string
+
+	// KafkaLayout
+	// 消息结构.
+	KafkaLayout string
+
+	// KafkaMode
+	// 生产者模式.
+	KafkaMode string
+
+	// KafkaPartitionKey
+	// 分区键策略.
+	KafkaPartitionKey string
+
+	// KafkaSaslMechanism
+	// SASL 认证机制.
+	KafkaSaslMechanism string
+)
+
+const (
+	KafkaAcksAll    KafkaAcks = "all"
+	KafkaAcksLeader KafkaAcks = "leader"
+	KafkaAcksNone   KafkaAcks = "none"
+
+	KafkaCompressionGzip   KafkaCompression = "gzip"
+	KafkaCompressionLz4    KafkaCompression = "lz4"
+	KafkaCompressionNone   KafkaCompression = "none"
+	KafkaCompressionSnappy KafkaCompression = "snappy"
+	KafkaCompressionZstd   KafkaCompression = "zstd"
+
+	KafkaEncodingAvro     KafkaEncoding = "avro"
+	KafkaEncodingJson     KafkaEncoding = "json"
+	KafkaEncodingProtobuf KafkaEncoding = "protobuf"
+
+	KafkaLayoutDefault KafkaLayout = "default"
+	KafkaLayoutEcs     KafkaLayout = "ecs"
+	KafkaLayoutGelf    KafkaLayout = "gelf"
+
+	KafkaModeAsync KafkaMode = "async"
+	KafkaModeSync  KafkaMode = "sync"
+
+	KafkaPartitionField      KafkaPartitionKey = "field"
+	KafkaPartitionRoundRobin KafkaPartitionKey = "round_robin"
+	KafkaPartitionService    KafkaPartitionKey = "service"
+	KafkaPartitionTraceId    KafkaPartitionKey = "trace_id"
+
+	KafkaSaslPlain       KafkaSaslMechanism = "PLAIN"
+	KafkaSaslScramSha256 KafkaSaslMechanism = "SCRAM-SHA-256"
+	KafkaSaslScramSha512 KafkaSaslMechanism = "SCRAM-SHA-512"
+)
+
+var (
+	// 消息结构的内置字段.
+	// 重命名的目标字段不可与之同名, 见 KafkaSchema.Rename.
+	kafkaLayoutKeys = map[KafkaLayout][]string{
+		KafkaLayoutDefault: {
+			"content", "fields", "level", "time", "time_ms",
+			"parent_span_id", "span_id", "trace_id", "request_method", "request_url", "user_agent",
+			"pid", "service_addr", "service_name", "service_version",
+		},
+		KafkaLayoutEcs: {
+			"@timestamp", "log.level", "message", "labels", "ecs.version",
+			"service.name", "service.version", "process.pid", "host.ip",
+			"trace.id", "span.id", "http.request.method", "url.path", "user_agent.original",
+		},
+		KafkaLayoutGelf: {
+			"version", "host", "short_message", "full_message", "timestamp", "level",
+			"_service_version", "_pid", "_service_addr", "_trace_id", "_span_id", "_parent_span_id",
+			"_request_method", "_request_url", "_user_agent",
+		},
+	}
+)
+
+// 是否为内置字段.
+func (o KafkaLayout) has(key string) bool {
+	for _, k := range kafkaLayoutKeys[o] {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+type (
+	// KafkaSasl
+	// SASL 认证配置.
+	//
+	// 未配置 mechanism 时不认证.
+	KafkaSasl struct {
+		Mechanism KafkaSaslMechanism `yaml:"mechanism" json:"mechanism"`
+		Username  string             `yaml:"username" json:"username"`
+		Password  string             `yaml:"password" json:"-"`
+	}
+
+	// KafkaSchema
+	// 消息体格式.
+	//
+	//   schema:
+	//     layout: default
+	//     encoding: json
+	//     flatten: true
+	//     rename:
+	//       content: message
+	KafkaSchema struct {
+		// 消息结构.
+		//
+		// - 默认：default, 见 adapters.LogData
+		// - 支持：default, ecs (Elastic Common Schema), gelf (Graylog 1.1)
+		Layout KafkaLayout `yaml:"layout" json:"layout"`
+
+		// 消息编码.
+		//
+		// - 默认：json
+		// - 支持：json, avro, protobuf; avro 及 protobuf 仅支持 default 结构,
+		//   结构见 log_kafka.AvroSchema 及 log_kafka.ProtoSchema
+		Encoding KafkaEncoding `yaml:"encoding" json:"encoding"`
+
+		// 字段展开.
+		// 将 fields 中的字段展开到顶层, 与顶层字段同名的仍保留在原位置; gelf
+		// 结构始终展开.
+		Flatten bool `yaml:"flatten" json:"flatten"`
+
+		// 字段重命名.
+		// 按顶层字段名重命名, 如: {content: message, time_ms: timestamp};
+		// 仅 json 编码有效. 新字段名不可与未重命名的内置字段同名; 与展开的
+		// 日志字段同名时不重命名.
+		Rename map[string]string `yaml:"rename" json:"rename"`
+	}
+
+	// KafkaTls
+	// TLS 连接配置.
+	//
+	// 未配置 ca_file 时使用系统根证书; cert_file 与 key_file 须同时配置, 用于
+	// 双向认证.
+	KafkaTls struct {
+		Enable             bool   `yaml:"enable" json:"enable"`
+		CaFile             string `yaml:"ca_file" json:"ca_file"`
+		CertFile           string `yaml:"cert_file" json:"cert_file"`
+		KeyFile            string `yaml:"key_file" json:"key_file"`
+		ServerName         string `yaml:"server_name" json:"server_name"`
+		InsecureSkipVerify bool   `yaml:"insecure_skip_verify" json:"insecure_skip_verify"`
+	}
+
 	// LogAdapterKafka
 	// 消息适配器配置.
 	//
@@ -26,6 +186,13 @@ type (
 	//     address: 127.0.0.1:9092
 	//     topic: logs
 	LogAdapterKafka struct {
+		// 最低级别.
+		//
+		// - 默认：不限制, 仅受全局 level 约束
+		// - 支持：DEBUG, INFO, WARN, ERROR, FATAL
+		Level    base.Level    `yaml:"level" json:"level"`
+		LogLevel base.LogLevel `yaml:"-" json:"-"`
+
 		// 批量阈值.
 		// 每次最多批量写入N(默认: 100)条日志.
 		Batch int `yaml:"batch" json:"batch"`
@@ -42,16 +209,100 @@ type (
 		// 主题名.
 		//
 		// - 默认：logs
+		// - 说明：未配置主题路由, 或路由结果不在 topic_allow 中时使用
 		Topic string `yaml:"topic" json:"topic"`
 
+		// 主题路由.
+		//
+		// 按顺序选择主题, 首个有效的结果生效, 均无效时使用 topic:
+		//
+		// - topic_field: 字段值, 如: topic_field 为 topic 时, 日志字段
+		//   log.Field{"topic": "audit"} 发往 audit
+		// - topic_level: 按级别, 如: {ERROR: logs-error}
+		// - topic_template: 模板, 如: logs-{service}-{level}, 支持 {service}
+		//   (服务名), {level} (小写级别) 及 {字段名}; 字段不存在时模板无效
+		// - topic_allow: 允许的主题, 支持通配符, 如: logs-*; 为空时不限制
+		TopicField    string                `yaml:"topic_field" json:"topic_field"`
+		TopicLevel    map[base.Level]string `yaml:"topic_level" json:"topic_level"`
+		TopicTemplate string                `yaml:"topic_template" json:"topic_template"`
+		TopicAllow    []string              `yaml:"topic_allow" json:"topic_allow"`
+
 		ProducerMaxRequest int `yaml:"producer_max_request" json:"producer_max_request"`
 		ProducerBufferSize int `yaml:"producer_buffer_size" json:"producer_buffer_size"`
 		ProducerRetry      int `yaml:"producer_retry" json:"producer_retry"`
 		ProducerTimeout    int `yaml:"producer_timeout" json:"producer_timeout"`
+
+		// 生产者模式.
+		//
+		// - 默认：sync
+		// - 支持：sync (逐批发送, 等待结果), async (写入发送队列后返回, 批次
+		//   流水线发送, 失败的消息经回调通知)
+		Mode KafkaMode `yaml:"mode" json:"mode"`
+
+		// 确认模式.
+		//
+		// - 默认：none
+		// - 支持：none (不等待确认), leader (主副本写入后确认), all (全部同步
+		//   副本写入后确认)
+		Acks KafkaAcks `yaml:"acks" json:"acks"`
+
+		// 压缩算法.
+		//
+		// - 默认：none
+		// - 支持：none, gzip, snappy, lz4, zstd (须 version 不低于 2.1.0)
+		Compression KafkaCompression `yaml:"compression" json:"compression"`
+
+		// 客户端标识.
+		//
+		// - 默认：sarama
+		// - 说明：仅支持字母、数字及 . _ -
+		ClientId string `yaml:"client_id" json:"client_id"`
+
+		// 集群版本.
+		//
+		// - 默认：1.0.0
+		// - 说明：如: 2.8.1, 须不高于集群实际版本.
+		Version string `yaml:"version" json:"version"`
+
+		// 分区键.
+		//
+		// - 默认：trace_id, 同一链路的日志写入同一分区, 保持顺序
+		// - 支持：trace_id, service (服务名), field (字段 partition_field 的值,
+		//   日志无此字段时按 trace_id), round_robin (轮询分区, 不设置键)
+		PartitionKey   KafkaPartitionKey `yaml:"partition_key" json:"partition_key"`
+		PartitionField string            `yaml:"partition_field" json:"partition_field"`
+
+		// 消息头.
+		//
+		// - 默认：true
+		// - 说明：附加 level, service, trace_id, span_id 消息头, 消费方无须解析
+		//   消息体即可过滤; 须 version 不低于 0.11.0.0.
+		Headers *bool `yaml:"headers" json:"headers"`
+
+		// 消息体格式.
+		Schema KafkaSchema `yaml:"schema" json:"schema"`
+
+		// 连接加密.
+		Tls KafkaTls `yaml:"tls" json:"tls"`
+
+		// 身份认证.
+		Sasl KafkaSasl `yaml:"sasl" json:"sasl"`
+
+		// 数据桶容量.
+		// 见 Bucket, 如: capacity, overflow.
+		Bucket `yaml:",inline"`
+
+		// 磁盘暂存.
+		// 见 Spool, 如: spool_path, spool_max_size.
+		Spool `yaml:",inline"`
 	}
 )
 
 func (o *LogAdapterKafka) defaults(_ *Configuration) {
+	o.Level, o.LogLevel = adapterLevel(o.Level)
+	o.Bucket.defaults()
+	o.Spool.defaults()
+
 	if o.ProducerBufferSize == 0 {
 		o.ProducerBufferSize = 256
 	}
@@ -77,4 +328,76 @@ func (o *LogAdapterKafka) defaults(_ *Configuration) {
 	if o.Topic == "" {
 		o.Topic = defaultLogAdapterKafkaTopic
 	}
+	if o.Mode == "" {
+		o.Mode = KafkaModeSync
+	}
+	if o.Acks == "" {
+		o.Acks = KafkaAcksNone
+	}
+	if o.Compression == "" {
+		o.Compression = KafkaCompressionNone
+	}
+	if o.Version == "" {
+		o.Version = defaultLogAdapterKafkaVersion
+	}
+	if o.PartitionKey == "" {
+		o.PartitionKey = KafkaPartitionTraceId
+	}
+	if o.Headers == nil {
+		o.Headers = &defaultLogAdapterKafkaHeaders
+	}
+	if o.Schema.Layout == "" {
+		o.Schema.Layout = KafkaLayoutDefault
+	}
+	if o.Schema.Encoding == "" {
+		o.Schema.Encoding = KafkaEncodingJson
+	}
+	o.Sasl.Mechanism = KafkaSaslMechanism(strings.ToUpper(string(o.Sasl.Mechanism)))
+
+	// 级别大写.
+	if len(o.TopicLevel) > 0 {
+		levels := make(map[base.Level]string, len(o.TopicLevel))
+		for l, topic := range o.TopicLevel {
+			levels[base.Level(strings.ToUpper(string(l)))] = topic
+		}
+		o.TopicLevel = levels
+	}
+}
+
+// AllowTopic
+// 是否允许发往主题.
+//
+// 主题名须合法(字母、数字及 . _ -, 不超过249个字符), 且配置 topic_allow
+// 时须匹配其中之一.
+func (o *LogAdapterKafka) AllowTopic(topic string) bool {
+	if !kafkaTopic.MatchString(topic) || topic == "." || topic == ".." {
+		return false
+	}
+	if len(o.TopicAllow) == 0 {
+		return true
+	}
+	for _, pattern := range o.TopicAllow {
+		if ok, _ := path.Match(pattern, topic); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// 生产者参数是否一致.
+// 不一致时须重建生产者.
+func (o *LogAdapterKafka) equalProducer(n *LogAdapterKafka) bool {
+	return reflect.DeepEqual(o.Host, n.Host) &&
+		o.ProducerMaxRequest == n.ProducerMaxRequest &&
+		o.ProducerBufferSize == n.ProducerBufferSize &&
+		o.ProducerRetry == n.ProducerRetry &&
+		o.ProducerTimeout == n.ProducerTimeout &&
+		o.Mode == n.Mode &&
+		o.Acks == n.Acks &&
+		o.Compression == n.Compression &&
+		o.ClientId == n.ClientId &&
+		o.Version == n.Version &&
+		o.PartitionKey == n.PartitionKey &&
+		o.Tls == n.Tls &&
+		o.Sasl == n.Sasl
 }
@@ -0,0 +1,100 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-19
+
+package config
+
+import (
+	"github.com/go-wares/log/base"
+)
+
+type (
+	// OtlpEncoding
+	// OTLP/HTTP 消息编码.
+	OtlpEncoding string
+)
+
+const (
+	OtlpEncodingJson     OtlpEncoding = "json"
+	OtlpEncodingProtobuf OtlpEncoding = "protobuf"
+)
+
+// ContentType
+// 请求头 Content-Type.
+func (o OtlpEncoding) ContentType() string {
+	if o == OtlpEncodingJson {
+		return "application/json"
+	}
+	return "application/x-protobuf"
+}
+
+type (
+	// LogAdapterOtlp
+	// OpenTelemetry 日志适配器配置.
+	//
+	//   # config/log.yaml
+	//
+	//   log_adapter: otlp
+	//   log_adapter_otlp:
+	//     endpoint: http://localhost:4318/v1/logs
+	//     encoding: protobuf
+	LogAdapterOtlp struct {
+		// 最低级别.
+		//
+		// - 默认：不限制, 仅受全局 level 约束
+		// - 支持：DEBUG, INFO, WARN, ERROR, FATAL
+		Level    base.Level    `yaml:"level" json:"level"`
+		LogLevel base.LogLevel `yaml:"-" json:"-"`
+
+		// 批量阈值.
+		// 每次最多批量上报N(默认: 100)条日志.
+		Batch int `yaml:"batch" json:"batch"`
+
+		// 上报频率.
+		// 每隔固定时长(默认: 350ms)上报一次日志.
+		Milliseconds int64 `yaml:"milliseconds" json:"milliseconds"`
+
+		// 上报位置.
+		//
+		// - 默认：http://localhost:4318/v1/logs
+		Endpoint string `yaml:"endpoint" json:"endpoint"`
+
+		// 消息编码.
+		//
+		// - 默认：protobuf
+		// - 支持：protobuf, json
+		Encoding OtlpEncoding `yaml:"encoding" json:"encoding"`
+
+		// 请求头.
+		// 例如鉴权: Authorization: Bearer xxx
+		Headers map[string]string `yaml:"headers" json:"headers"`
+	}
+)
+
+func (o *LogAdapterOtlp) defaults(_ *Configuration) {
+	o.Level, o.LogLevel = adapterLevel(o.Level)
+
+	if o.Batch == 0 {
+		o.Batch = defaultLogAdapterOtlpBatch
+	}
+	if o.Milliseconds == 0 {
+		o.Milliseconds = defaultLogAdapterOtlpMilliseconds
+	}
+	if o.Endpoint == "" {
+		o.Endpoint = defaultLogAdapterOtlpEndpoint
+	}
+	if o.Encoding == "" {
+		o.Encoding = OtlpEncodingProtobuf
+	}
+}
@@ -15,6 +15,10 @@
 
 package config
 
+import (
+	"github.com/go-wares/log/base"
+)
+
 type (
 	// LogAdapterTerm
 	// 终端适配器配置.
@@ -27,11 +31,20 @@ type (
 	LogAdapterTerm struct {
 		// 着色.
 		// 打印到终端上的日志是否包含颜色.
-		Color *bool
+		Color *bool `yaml:"color" json:"color"`
+
+		// 最低级别.
+		//
+		// - 默认：不限制, 仅受全局 level 约束
+		// - 支持：DEBUG, INFO, WARN, ERROR, FATAL
+		Level    base.Level    `yaml:"level" json:"level"`
+		LogLevel base.LogLevel `yaml:"-" json:"-"`
 	}
 )
 
 func (o *LogAdapterTerm) defaults(_ *Configuration) {
+	o.Level, o.LogLevel = adapterLevel(o.Level)
+
 	if o.Color == nil {
 		o.Color = &defaultLogAdapterTermColor
 	}
@@ -0,0 +1,112 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-21
+
+package config
+
+import (
+	"fmt"
+	"github.com/go-wares/log/base"
+	"path"
+	"strings"
+)
+
+type (
+	// LogRoute
+	// 日志路由规则.
+	//
+	// 按顺序匹配, 首个命中的规则决定日志发往哪些适配器; 全部未命中时发往
+	// 全部适配器. 规则内的各条件须同时满足, 未配置的条件不参与匹配.
+	//
+	//   # config/log.yaml
+	//
+	//   log_route:
+	//     - level: [ERROR, FATAL]
+	//       adapter: kafka, file
+	//     - field:
+	//         order_id: "*"
+	//       adapter: kafka
+	//     - span: "http.*"
+	//       adapter: term
+	LogRoute struct {
+		// 目标适配器.
+		// 命中后发往的适配器, 须为 log_adapter 中已配置的适配器.
+		Adapter base.LogAdapters `yaml:"adapter" json:"adapter"`
+
+		// 匹配级别.
+		// 日志级别为其中之一时命中.
+		Level []base.Level `yaml:"level" json:"level"`
+
+		// 匹配字段.
+		// 值为空或 * 时仅要求字段存在, 否则要求字段值相等.
+		Field map[string]string `yaml:"field" json:"field"`
+
+		// 匹配跨度名.
+		// 支持通配符, 如: http.*
+		Span string `yaml:"span" json:"span"`
+	}
+)
+
+// Match
+// 是否命中.
+func (o *LogRoute) Match(level base.LogLevel, fields map[string]interface{}, span string) bool {
+	// 1. 级别.
+	if len(o.Level) > 0 && !o.matchLevel(level) {
+		return false
+	}
+
+	// 2. 字段.
+	for k, s := range o.Field {
+		v, ok := fields[k]
+		if !ok {
+			return false
+		}
+		if s != "" && s != "*" && fmt.Sprintf("%v", v) != s {
+			return false
+		}
+	}
+
+	// 3. 跨度.
+	if o.Span != "" {
+		if span == "" {
+			return false
+		}
+		if ok, _ := path.Match(o.Span, span); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+// 统一级别名称为大写.
+func (o *LogRoute) defaults(_ *Configuration) {
+	for i, l := range o.Level {
+		o.Level[i] = base.Level(strings.ToUpper(string(l)))
+	}
+}
+
+func (o *LogRoute) matchLevel(level base.LogLevel) bool {
+	s := base.Level(level.String())
+	for _, l := range o.Level {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
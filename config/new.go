@@ -0,0 +1,58 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-25
+
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+// New
+// 创建默认配置.
+//
+// 不读取配置文件及环境变量, 用于非全局的日志实例.
+func New() *Configuration {
+	o := &Configuration{}
+	o.defaults()
+	return o
+}
+
+// NewFromBytes
+// 基于 YAML 内容创建配置.
+//
+// 不叠加环境变量; 校验失败时返回错误.
+func NewFromBytes(body []byte) (*Configuration, error) {
+	return (&Configuration{}).parse(body, nil)
+}
+
+// NewFromFile
+// 基于配置文件创建配置.
+//
+// 不叠加环境变量; 开启热加载(watch)时监听此文件.
+func NewFromFile(path string) (*Configuration, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := NewFromBytes(body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	o.path = path
+	return o, nil
+}
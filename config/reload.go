@@ -0,0 +1,221 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-23
+
+package config
+
+import (
+	"fmt"
+	"github.com/go-wares/log/base"
+	"gopkg.in/yaml.v3"
+	"os"
+	"reflect"
+)
+
+type (
+	// Change
+	// 配置变更.
+	//
+	// 热加载后通知 ChangeHandler, 由管理器重建或重启受影响的适配器.
+	Change struct {
+		// 日志适配器.
+		//
+		// - LogAdapter: log_adapter 列表变更
+		// - LogRebuild: 连接参数变更, 须重建适配器
+		// - LogRestart: 刷新频率变更, 须重启适配器的 Keeper
+		LogAdapter             bool
+		LogRebuild, LogRestart base.LogAdapters
+
+		// 链路适配器.
+		//
+		// - TraceAdapter: trace_adapter 变更或连接参数变更, 须重建适配器
+		// - TraceRestart: 刷新频率变更, 须重启适配器的 Keeper
+		TraceAdapter, TraceRestart bool
+
+		// 应用名称或版本号变更.
+		// 仅 Load 时可能变更, 须刷新 adapters.Resource 中的服务字段.
+		Service bool
+	}
+
+	// ChangeHandler
+	// 配置变更执行器.
+	ChangeHandler func(change *Change)
+)
+
+// OnChange
+// 注册配置变更执行器.
+func (o *Configuration) OnChange(handlers ...ChangeHandler) {
+	o.reloadMu.Lock()
+	defer o.reloadMu.Unlock()
+	o.changeHandlers = append(o.changeHandlers, handlers...)
+}
+
+// Path
+// 配置文件路径.
+//
+// 未加载配置文件时返回空字符串.
+func (o *Configuration) Path() string {
+	o.reloadMu.Lock()
+	defer o.reloadMu.Unlock()
+	return o.path
+}
+
+// Reload
+// 重新加载配置文件.
+func (o *Configuration) Reload() (*Change, error) {
+	return o.ReloadFile(o.Path())
+}
+
+// ReloadFile
+// 从指定文件重新加载配置.
+//
+// 仅应用运行期间可安全修改的配置, 如: 日志级别、时间格式、终端着色、文件路径
+// 和文件名、批量阈值、刷新频率等; 应用名称、版本号、自动启动等仅在启动时生效.
+// 新配置作为快照整体发布, 不修改当前实例的字段, 运行期间经 Snapshot 读取.
+func (o *Configuration) ReloadFile(path string) (*Change, error) {
+	if path == "" {
+		return nil, fmt.Errorf("config file not specified")
+	}
+
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return o.reload(body, path, false)
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+// 解析配置.
+//
+// 依次: 解析 YAML、覆盖环境变量(仅全局配置)、保留启动配置、填充默认值、
+// 校验. keep 不为 nil 时保留其应用名称、版本号及自动启动, 由应用名称派生
+// 的默认值(如链路服务名)随之保持不变.
+func (o *Configuration) parse(body []byte, keep *Configuration) (*Configuration, error) {
+	n := &Configuration{env: o.env}
+
+	if err := yaml.Unmarshal(body, n); err != nil {
+		return nil, fmt.Errorf("invalid log config: %v", err)
+	}
+	if n.env {
+		if err := n.overlay(); err != nil {
+			return nil, fmt.Errorf("invalid log config: %v", err)
+		}
+	}
+	if keep != nil {
+		n.Name, n.Version, n.AutoStart = keep.Name, keep.Version, keep.AutoStart
+	}
+
+	n.defaults()
+	if err := n.Validate(); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// 重新加载.
+//
+// 校验失败时保留当前配置; 成功后应用配置并通知 ChangeHandler. full 为 true
+// 时应用全部配置, 含仅在启动时生效的应用名称、版本号等.
+func (o *Configuration) reload(body []byte, path string, full bool) (change *Change, err error) {
+	handlers := func() []ChangeHandler {
+		o.reloadMu.Lock()
+		defer o.reloadMu.Unlock()
+
+		// 1. 解析配置.
+		var keep, n *Configuration
+		if !full {
+			keep = o.Snapshot()
+		}
+		if n, err = o.parse(body, keep); err != nil {
+			if path != "" {
+				err = fmt.Errorf("%s: %v", path, err)
+			}
+			return nil
+		}
+
+		// 2. 应用配置.
+		if path != "" {
+			o.path = path
+		}
+		change = o.apply(n)
+		return o.changeHandlers
+	}()
+
+	// 3. 变更通知.
+	for _, handler := range handlers {
+		handler(change)
+	}
+	return
+}
+
+// 应用配置.
+//
+// 与当前快照比较得出变更, 然后发布 n 作为新的快照; 已发布的快照不再修改.
+func (o *Configuration) apply(n *Configuration) *Change {
+	var (
+		c      = o.Snapshot()
+		change = &Change{
+			LogRebuild: base.LogAdapters{},
+			LogRestart: base.LogAdapters{},
+		}
+	)
+
+	// 1. 服务配置.
+	n.Addr, n.Pid = c.Addr, c.Pid
+	change.Service = c.Name != n.Name || c.Version != n.Version
+
+	// 2. 日志级别.
+	//    仅在配置值变更时修改, 否则保留 SetLevel 设置的运行级别.
+	if n.Level != c.Level {
+		o.updateLevel(n.LogLevel, 0)
+	}
+
+	// 3. 日志适配器.
+	change.LogAdapter = !reflect.DeepEqual(c.LogAdapter, n.LogAdapter)
+	if !c.LogAdapterFile.equalListen(n.LogAdapterFile) {
+		change.LogRestart = append(change.LogRestart, base.LogFile)
+	}
+	if c.LogAdapterOtlp.Milliseconds != n.LogAdapterOtlp.Milliseconds {
+		change.LogRestart = append(change.LogRestart, base.LogOtlp)
+	}
+	if !c.LogAdapterKafka.equalProducer(n.LogAdapterKafka) {
+		change.LogRebuild = append(change.LogRebuild, base.LogKafka)
+	} else if c.LogAdapterKafka.Milliseconds != n.LogAdapterKafka.Milliseconds {
+		change.LogRestart = append(change.LogRestart, base.LogKafka)
+	}
+
+	// 4. 链路适配器.
+	if c.TraceAdapter != n.TraceAdapter {
+		change.TraceAdapter = true
+	} else {
+		switch c.TraceAdapter {
+		case base.TraceJaeger:
+			change.TraceRestart = c.TraceAdapterJaeger.Milliseconds != n.TraceAdapterJaeger.Milliseconds
+		case base.TraceZipkin:
+			change.TraceRestart = c.TraceAdapterZipkin.Milliseconds != n.TraceAdapterZipkin.Milliseconds
+		case base.TraceOtlp:
+			change.TraceRestart = c.TraceAdapterOtlp.Milliseconds != n.TraceAdapterOtlp.Milliseconds
+		case base.TraceKafka:
+			change.TraceAdapter = !c.TraceAdapterKafka.equalProducer(n.TraceAdapterKafka)
+			change.TraceRestart = c.TraceAdapterKafka.Milliseconds != n.TraceAdapterKafka.Milliseconds
+		}
+	}
+
+	// 5. 发布快照.
+	o.snapshot.Store(n)
+	return change
+}
@@ -0,0 +1,61 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-06-04
+
+package config
+
+type (
+	// Spool
+	// 磁盘暂存配置.
+	//
+	// 内嵌于消息及 Jaeger 适配器配置; 上报失败的批次写入暂存目录, 恢复后按
+	// 写入顺序重放.
+	//
+	//   log_adapter_kafka:
+	//     spool_path: ./spool/kafka
+	//     spool_max_size: 512
+	Spool struct {
+		// 暂存目录.
+		//
+		// - 默认：空, 不启用
+		// - 说明：各适配器须使用不同的目录, 不支持多个进程共用.
+		SpoolPath string `yaml:"spool_path" json:"spool_path"`
+
+		// 最大容量.
+		//
+		// - 默认：512
+		// - 单位：MB
+		// - 说明：超过时不再暂存新的批次, 打印到标准错误.
+		SpoolMaxSize int `yaml:"spool_max_size" json:"spool_max_size"`
+
+		// 重放间隔.
+		//
+		// - 默认：1000ms, 最大 60000ms
+		// - 说明：重放失败时间隔按指数递增至最大值, 成功后还原.
+		SpoolMilliseconds    int64 `yaml:"spool_milliseconds" json:"spool_milliseconds"`
+		SpoolMaxMilliseconds int64 `yaml:"spool_max_milliseconds" json:"spool_max_milliseconds"`
+	}
+)
+
+func (o *Spool) defaults() {
+	if o.SpoolMaxSize == 0 {
+		o.SpoolMaxSize = defaultSpoolMaxSize
+	}
+	if o.SpoolMilliseconds == 0 {
+		o.SpoolMilliseconds = defaultSpoolMilliseconds
+	}
+	if o.SpoolMaxMilliseconds == 0 {
+		o.SpoolMaxMilliseconds = defaultSpoolMaxMilliseconds
+	}
+}
@@ -33,10 +33,21 @@ type (
 
 		// 上报主题.
 		Topic string `yaml:"topic" json:"topic"`
+
+		// 数据桶容量.
+		// 见 Bucket, 如: capacity, overflow.
+		Bucket `yaml:",inline"`
+
+		// 磁盘暂存.
+		// 见 Spool, 如: spool_path, spool_max_size.
+		Spool `yaml:",inline"`
 	}
 )
 
 func (o *TraceAdapterJaeger) defaults(c *Configuration) {
+	o.Bucket.defaults()
+	o.Spool.defaults()
+
 	if o.Batch == 0 {
 		o.Batch = defaultTraceAdapterJaegerBatch
 	}
@@ -0,0 +1,116 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-18
+
+package config
+
+import (
+	"reflect"
+)
+
+type (
+	// TraceEncoding
+	// 跨度编码.
+	TraceEncoding string
+)
+
+const (
+	TraceEncodingJson   TraceEncoding = "json"
+	TraceEncodingThrift TraceEncoding = "thrift"
+)
+
+type (
+	// TraceAdapterKafka
+	// Kafka 链路配置.
+	//
+	//   # config/log.yaml
+	//
+	//   trace_adapter: kafka
+	//   trace_adapter_kafka:
+	//     host:
+	//       - 127.0.0.1:9092
+	//     topic: go-wares-trace
+	//     encoding: json
+	TraceAdapterKafka struct {
+		// 批量阈值.
+		// 每次最多批量写入N(默认: 100)条跨度.
+		Batch int `yaml:"batch" json:"batch"`
+
+		// 上报频率.
+		// 每隔固定时长(默认: 350ms)上报一次跨度.
+		Milliseconds int `yaml:"milliseconds" json:"milliseconds"`
+
+		// 主机名.
+		//
+		// - 默认：127.0.0.1:9092
+		Host []string `yaml:"host" json:"host"`
+
+		// 主题名.
+		//
+		// - 默认：go-wares-trace
+		Topic string `yaml:"topic" json:"topic"`
+
+		// 跨度编码.
+		//
+		// - 默认：json
+		// - 支持：json (Zipkin v2 JSON), thrift (Jaeger thrift)
+		Encoding TraceEncoding `yaml:"encoding" json:"encoding"`
+
+		ProducerMaxRequest int `yaml:"producer_max_request" json:"producer_max_request"`
+		ProducerBufferSize int `yaml:"producer_buffer_size" json:"producer_buffer_size"`
+		ProducerRetry      int `yaml:"producer_retry" json:"producer_retry"`
+		ProducerTimeout    int `yaml:"producer_timeout" json:"producer_timeout"`
+	}
+)
+
+func (o *TraceAdapterKafka) defaults(_ *Configuration) {
+	if o.ProducerBufferSize == 0 {
+		o.ProducerBufferSize = 256
+	}
+	if o.ProducerMaxRequest == 0 {
+		o.ProducerMaxRequest = 10
+	}
+	if o.ProducerRetry == 0 {
+		o.ProducerRetry = 3
+	}
+	if o.ProducerTimeout == 0 {
+		o.ProducerTimeout = 2
+	}
+
+	if o.Batch == 0 {
+		o.Batch = defaultTraceAdapterKafkaBatch
+	}
+	if o.Milliseconds == 0 {
+		o.Milliseconds = defaultTraceAdapterKafkaMilliseconds
+	}
+	if len(o.Host) == 0 {
+		o.Host = []string{defaultLogAdapterKafkaHost}
+	}
+	if o.Topic == "" {
+		o.Topic = defaultTraceAdapterKafkaTopic
+	}
+	if o.Encoding == "" {
+		o.Encoding = TraceEncodingJson
+	}
+}
+
+// 生产者参数是否一致.
+// 不一致时须重建生产者.
+func (o *TraceAdapterKafka) equalProducer(n *TraceAdapterKafka) bool {
+	return reflect.DeepEqual(o.Host, n.Host) &&
+		o.ProducerMaxRequest == n.ProducerMaxRequest &&
+		o.ProducerBufferSize == n.ProducerBufferSize &&
+		o.ProducerRetry == n.ProducerRetry &&
+		o.ProducerTimeout == n.ProducerTimeout
+}
@@ -0,0 +1,66 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-19
+
+package config
+
+type (
+	// TraceAdapterOtlp
+	// OpenTelemetry 链路配置.
+	//
+	//   # config/log.yaml
+	//
+	//   trace_adapter: otlp
+	//   trace_adapter_otlp:
+	//     endpoint: http://localhost:4318/v1/traces
+	//     encoding: protobuf
+	TraceAdapterOtlp struct {
+		// 批量阈值.
+		// 每次最多批量上报N(默认: 100)条跨度.
+		Batch int `yaml:"batch" json:"batch"`
+
+		// 上报频率.
+		// 每隔固定时长(默认: 350ms)上报一次跨度.
+		Milliseconds int `yaml:"milliseconds" json:"milliseconds"`
+
+		// 上报位置.
+		//
+		// - 默认：http://localhost:4318/v1/traces
+		Endpoint string `yaml:"endpoint" json:"endpoint"`
+
+		// 消息编码.
+		//
+		// - 默认：protobuf
+		// - 支持：protobuf, json
+		Encoding OtlpEncoding `yaml:"encoding" json:"encoding"`
+
+		// 请求头.
+		Headers map[string]string `yaml:"headers" json:"headers"`
+	}
+)
+
+func (o *TraceAdapterOtlp) defaults(_ *Configuration) {
+	if o.Batch == 0 {
+		o.Batch = defaultTraceAdapterOtlpBatch
+	}
+	if o.Milliseconds == 0 {
+		o.Milliseconds = defaultTraceAdapterOtlpMilliseconds
+	}
+	if o.Endpoint == "" {
+		o.Endpoint = defaultTraceAdapterOtlpEndpoint
+	}
+	if o.Encoding == "" {
+		o.Encoding = OtlpEncodingProtobuf
+	}
+}
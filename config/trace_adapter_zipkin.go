@@ -18,9 +18,46 @@ package config
 type (
 	// TraceAdapterZipkin
 	// Zipkin 链路配置.
+	//
+	//   # config/log.yaml
+	//
+	//   trace_adapter: zipkin
+	//   trace_adapter_zipkin:
+	//     endpoint: http://localhost:9411/api/v2/spans
 	TraceAdapterZipkin struct {
+		Endpoint string `yaml:"endpoint" json:"endpoint"`
+		Username string `yaml:"username" json:"username"`
+		Password string `yaml:"password" json:"password"`
+
+		// 批量阈值.
+		// 每次最多批量写入N(默认: 100)条跨度.
+		Batch int `yaml:"batch" json:"batch"`
+
+		// 上报频率.
+		// 每隔固定时长(默认: 350ms)上报一次跨度.
+		Milliseconds int `yaml:"milliseconds" json:"milliseconds"`
+
+		// 服务名称.
+		// 上报到 Zipkin 的 localEndpoint.serviceName.
+		Topic string `yaml:"topic" json:"topic"`
 	}
 )
 
-func (o *TraceAdapterZipkin) defaults(_ *Configuration) {
+func (o *TraceAdapterZipkin) defaults(c *Configuration) {
+	if o.Endpoint == "" {
+		o.Endpoint = defaultTraceAdapterZipkinEndpoint
+	}
+	if o.Batch == 0 {
+		o.Batch = defaultTraceAdapterZipkinBatch
+	}
+	if o.Milliseconds == 0 {
+		o.Milliseconds = defaultTraceAdapterZipkinMilliseconds
+	}
+	if o.Topic == "" {
+		if c.Name == "" {
+			o.Topic = defaultTraceAdapterJaegerTopic
+		} else {
+			o.Topic = c.Name
+		}
+	}
 }
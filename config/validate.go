@@ -0,0 +1,433 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-24
+
+package config
+
+import (
+	"fmt"
+	"github.com/go-wares/log/base"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	kafkaClientId = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+	kafkaTopic    = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,249}$`)
+	kafkaVersion  = regexp.MustCompile(`^(0\.\d+\.\d+\.\d+|[1-9]\d*\.\d+\.\d+)$`)
+)
+
+type (
+	// 校验结果.
+	validator struct {
+		errs []string
+	}
+)
+
+// Validate
+// 校验配置.
+//
+// 返回全部错误, 如: 无效级别、未知适配器、无效频率等; 须在默认值填充后调用.
+func (o *Configuration) Validate() error {
+	v := &validator{}
+
+	// 1. 基础配置.
+	v.level("level", o.Level, false)
+	v.positive("watch_milliseconds", int64(o.WatchMilliseconds))
+	if o.LogTimeFormat == "" {
+		v.add("log_time_format: must not be empty")
+	}
+
+	// 2. 日志适配器.
+	for _, name := range o.LogAdapter {
+		v.logAdapter("log_adapter", name)
+	}
+	if o.LogAdapterTerm != nil {
+		v.level("log_adapter_term.level", o.LogAdapterTerm.Level, true)
+	}
+	if c := o.LogAdapterFile; c != nil {
+		v.level("log_adapter_file.level", c.Level, true)
+		v.positive("log_adapter_file.batch", int64(c.Batch))
+		v.positive("log_adapter_file.milliseconds", c.Milliseconds)
+		v.nonNegative("log_adapter_file.max_size", int64(c.MaxSize))
+		v.nonNegative("log_adapter_file.max_age", int64(c.MaxAge))
+		v.nonNegative("log_adapter_file.max_count", int64(c.MaxCount))
+		switch c.Format {
+		case FileFormatText, FileFormatJson, FileFormatLogfmt:
+		default:
+			v.add("log_adapter_file.format: unknown format %q, expect one of text, json, logfmt", c.Format)
+		}
+		switch c.Sync {
+		case FileSyncNever, FileSyncBatch, FileSyncInterval:
+		default:
+			v.add("log_adapter_file.sync: unknown policy %q, expect one of never, batch, interval", c.Sync)
+		}
+		v.positive("log_adapter_file.sync_milliseconds", c.SyncMilliseconds)
+		v.level("log_adapter_file.error_level", c.ErrorLevel, true)
+		if c.ErrorName != "" && c.ErrorName == c.Name {
+			v.add("log_adapter_file.error_name: must differ from name %q", c.Name)
+		}
+		v.bucket("log_adapter_file", &c.Bucket)
+		v.fileMode("log_adapter_file.file_mode", c.FileMode)
+		v.fileMode("log_adapter_file.dir_mode", c.DirMode)
+		if c.Symlink != "" && (path.IsAbs(c.Symlink) || strings.ContainsAny(c.Symlink, `/\`)) {
+			v.add("log_adapter_file.symlink: must be a file name, got %q", c.Symlink)
+		}
+	}
+	if c := o.LogAdapterKafka; c != nil {
+		v.level("log_adapter_kafka.level", c.Level, true)
+		v.positive("log_adapter_kafka.batch", int64(c.Batch))
+		v.positive("log_adapter_kafka.milliseconds", c.Milliseconds)
+		v.hosts("log_adapter_kafka.host", c.Host)
+		v.bucket("log_adapter_kafka", &c.Bucket)
+		v.spool("log_adapter_kafka", &c.Spool)
+		v.kafka("log_adapter_kafka", c)
+	}
+	if c := o.LogAdapterOtlp; c != nil {
+		v.level("log_adapter_otlp.level", c.Level, true)
+		v.positive("log_adapter_otlp.batch", int64(c.Batch))
+		v.positive("log_adapter_otlp.milliseconds", c.Milliseconds)
+		v.otlpEncoding("log_adapter_otlp.encoding", c.Encoding)
+	}
+
+	// 3. 阻塞溢出.
+	//    多个日志适配器依次发送, 阻塞的适配器会拖慢其它适配器, 仅单个日志适配器
+	//    时支持 block.
+	if len(o.LogAdapter) > 1 {
+		if c := o.LogAdapterFile; c != nil && o.LogAdapter.Contains(base.LogFile) && c.Overflow == OverflowBlock {
+			v.add("log_adapter_file.overflow: block is not supported with multiple log adapters %v", o.LogAdapter)
+		}
+		if c := o.LogAdapterKafka; c != nil && o.LogAdapter.Contains(base.LogKafka) && c.Overflow == OverflowBlock {
+			v.add("log_adapter_kafka.overflow: block is not supported with multiple log adapters %v", o.LogAdapter)
+		}
+	}
+
+	// 4. 日志路由.
+	for i, r := range o.LogRoute {
+		key := fmt.Sprintf("log_route[%d]", i)
+		if len(r.Adapter) == 0 {
+			v.add("%s.adapter: must not be empty", key)
+		}
+		for _, name := range r.Adapter {
+			if v.logAdapter(key+".adapter", name) && !o.LogAdapter.Contains(name) {
+				v.add("%s.adapter: %q is not enabled in log_adapter", key, name)
+			}
+		}
+		for _, l := range r.Level {
+			v.level(key+".level", l, false)
+		}
+		if _, err := path.Match(r.Span, ""); err != nil {
+			v.add("%s.span: malformed pattern %q", key, r.Span)
+		}
+	}
+
+	// 5. 链路适配器.
+	switch o.TraceAdapter {
+	case "", base.TraceJaeger, base.TraceZipkin, base.TraceKafka, base.TraceOtlp:
+	default:
+		v.add("trace_adapter: unknown adapter %q, expect one of jaeger, zipkin, kafka, otlp", o.TraceAdapter)
+	}
+	if c := o.TraceAdapterJaeger; c != nil {
+		v.positive("trace_adapter_jaeger.batch", int64(c.Batch))
+		v.positive("trace_adapter_jaeger.milliseconds", int64(c.Milliseconds))
+		v.bucket("trace_adapter_jaeger", &c.Bucket)
+		v.spool("trace_adapter_jaeger", &c.Spool)
+		if k := o.LogAdapterKafka; k != nil && c.SpoolPath != "" && path.Clean(c.SpoolPath) == path.Clean(k.SpoolPath) {
+			v.add("trace_adapter_jaeger.spool_path: must differ from log_adapter_kafka.spool_path")
+		}
+	}
+	if c := o.TraceAdapterKafka; c != nil {
+		v.positive("trace_adapter_kafka.batch", int64(c.Batch))
+		v.positive("trace_adapter_kafka.milliseconds", int64(c.Milliseconds))
+		v.hosts("trace_adapter_kafka.host", c.Host)
+		if c.Encoding != TraceEncodingJson && c.Encoding != TraceEncodingThrift {
+			v.add("trace_adapter_kafka.encoding: unknown encoding %q, expect json or thrift", c.Encoding)
+		}
+	}
+	if c := o.TraceAdapterOtlp; c != nil {
+		v.positive("trace_adapter_otlp.batch", int64(c.Batch))
+		v.positive("trace_adapter_otlp.milliseconds", int64(c.Milliseconds))
+		v.otlpEncoding("trace_adapter_otlp.encoding", c.Encoding)
+	}
+	if c := o.TraceAdapterZipkin; c != nil {
+		v.positive("trace_adapter_zipkin.batch", int64(c.Batch))
+		v.positive("trace_adapter_zipkin.milliseconds", int64(c.Milliseconds))
+	}
+
+	return v.err()
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+func (o *validator) add(format string, args ...interface{}) {
+	o.errs = append(o.errs, fmt.Sprintf(format, args...))
+}
+
+func (o *validator) err() error {
+	if len(o.errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid log config: %s", strings.Join(o.errs, "; "))
+}
+
+func (o *validator) bucket(key string, b *Bucket) {
+	switch b.Overflow {
+	case OverflowBlock, OverflowDropLevel, OverflowDropNewest, OverflowDropOldest:
+	default:
+		o.add("%s.overflow: unknown policy %q, expect one of block, drop_newest, drop_oldest, drop_level", key, b.Overflow)
+	}
+	o.positive(key+".overflow_milliseconds", b.OverflowMilliseconds)
+	o.level(key+".overflow_level", b.OverflowLevel, true)
+}
+
+func (o *validator) spool(key string, s *Spool) {
+	o.positive(key+".spool_max_size", int64(s.SpoolMaxSize))
+	o.positive(key+".spool_milliseconds", s.SpoolMilliseconds)
+	if s.SpoolMaxMilliseconds < s.SpoolMilliseconds {
+		o.add("%s.spool_max_milliseconds: must not be less than spool_milliseconds, got %d", key, s.SpoolMaxMilliseconds)
+	}
+}
+
+func (o *validator) fileMode(key string, mode FileMode) {
+	if mode.Perm() == 0 {
+		o.add("%s: invalid mode %q, expect octal such as 0644", key, mode)
+	}
+}
+
+func (o *validator) hosts(key string, hosts []string) {
+	if len(hosts) == 0 {
+		o.add("%s: must not be empty", key)
+	}
+	for _, h := range hosts {
+		if strings.TrimSpace(h) == "" {
+			o.add("%s: must not contain empty host", key)
+		}
+	}
+}
+
+func (o *validator) kafka(key string, c *LogAdapterKafka) {
+	// 1. 生产者模式.
+	if c.Mode != KafkaModeSync && c.Mode != KafkaModeAsync {
+		o.add("%s.mode: unknown mode %q, expect sync or async", key, c.Mode)
+	}
+
+	// 2. 确认模式.
+	switch c.Acks {
+	case KafkaAcksNone, KafkaAcksLeader, KafkaAcksAll:
+	default:
+		o.add("%s.acks: unknown mode %q, expect one of none, leader, all", key, c.Acks)
+	}
+
+	// 3. 集群版本.
+	version, ok := parseKafkaVersion(c.Version)
+	if !ok {
+		o.add("%s.version: malformed version %q, expect such as 2.8.1 or 0.10.2.1", key, c.Version)
+	}
+
+	// 4. 压缩算法.
+	switch c.Compression {
+	case KafkaCompressionNone, KafkaCompressionGzip, KafkaCompressionSnappy, KafkaCompressionLz4:
+	case KafkaCompressionZstd:
+		if ok && (version[0] < 2 || (version[0] == 2 && version[1] < 1)) {
+			o.add("%s.compression: zstd requires version 2.1.0 or later, got %q", key, c.Version)
+		}
+	default:
+		o.add("%s.compression: unknown codec %q, expect one of none, gzip, snappy, lz4, zstd", key, c.Compression)
+	}
+
+	// 5. 分区键.
+	switch c.PartitionKey {
+	case KafkaPartitionTraceId, KafkaPartitionService, KafkaPartitionRoundRobin:
+	case KafkaPartitionField:
+		if c.PartitionField == "" {
+			o.add("%s.partition_field: must not be empty when partition_key is field", key)
+		}
+	default:
+		o.add("%s.partition_key: unknown strategy %q, expect one of trace_id, service, field, round_robin", key, c.PartitionKey)
+	}
+
+	// 6. 消息头.
+	if *c.Headers && ok && version[0] == 0 && version[1] < 11 {
+		o.add("%s.headers: requires version 0.11.0.0 or later, got %q", key, c.Version)
+	}
+
+	// 7. 客户端标识.
+	if c.ClientId != "" && !kafkaClientId.MatchString(c.ClientId) {
+		o.add("%s.client_id: invalid id %q, expect letters, digits, '.', '_' or '-'", key, c.ClientId)
+	}
+
+	// 8. 连接加密.
+	if c.Tls.Enable {
+		if (c.Tls.CertFile == "") != (c.Tls.KeyFile == "") {
+			o.add("%s.tls: cert_file and key_file must be set together", key)
+		}
+		for k, f := range map[string]string{"ca_file": c.Tls.CaFile, "cert_file": c.Tls.CertFile, "key_file": c.Tls.KeyFile} {
+			if f == "" {
+				continue
+			}
+			if _, err := os.Stat(f); err != nil {
+				o.add("%s.tls.%s: %v", key, k, err)
+			}
+		}
+	}
+
+	// 9. 身份认证.
+	switch c.Sasl.Mechanism {
+	case "":
+	case KafkaSaslPlain, KafkaSaslScramSha256, KafkaSaslScramSha512:
+		if c.Sasl.Username == "" {
+			o.add("%s.sasl.username: must not be empty", key)
+		}
+	default:
+		o.add("%s.sasl.mechanism: unknown mechanism %q, expect one of PLAIN, SCRAM-SHA-256, SCRAM-SHA-512", key, c.Sasl.Mechanism)
+	}
+
+	// 10. 主题路由.
+	o.kafkaTopic(key, c)
+
+	// 11. 消息体格式.
+	o.kafkaSchema(key+".schema", &c.Schema)
+}
+
+func (o *validator) kafkaSchema(key string, c *KafkaSchema) {
+	// 1. 消息结构.
+	switch c.Layout {
+	case KafkaLayoutDefault, KafkaLayoutEcs, KafkaLayoutGelf:
+	default:
+		o.add("%s.layout: unknown layout %q, expect one of default, ecs, gelf", key, c.Layout)
+	}
+
+	// 2. 消息编码.
+	switch c.Encoding {
+	case KafkaEncodingJson:
+	case KafkaEncodingAvro, KafkaEncodingProtobuf:
+		if c.Layout != KafkaLayoutDefault {
+			o.add("%s.encoding: %s requires layout default, got %q", key, c.Encoding, c.Layout)
+		}
+		if c.Flatten || len(c.Rename) > 0 {
+			o.add("%s.encoding: %s does not support flatten or rename", key, c.Encoding)
+		}
+	default:
+		o.add("%s.encoding: unknown encoding %q, expect one of json, avro, protobuf", key, c.Encoding)
+	}
+
+	// 3. 字段重命名.
+	//    新字段名不可为空或重复, 不可与未重命名的内置字段同名.
+	names := make(map[string]string, len(c.Rename))
+	for from, to := range c.Rename {
+		if to == "" {
+			o.add("%s.rename.%s: must not be empty", key, from)
+			continue
+		}
+		if prev, ok := names[to]; ok {
+			o.add("%s.rename: %s and %s both renamed to %q", key, prev, from, to)
+		}
+		if _, renamed := c.Rename[to]; !renamed && c.Layout.has(to) {
+			o.add("%s.rename.%s: %q conflicts with built-in field of layout %s", key, from, to, c.Layout)
+		}
+		names[to] = from
+	}
+}
+
+func (o *validator) kafkaTopic(key string, c *LogAdapterKafka) {
+	// 1. 允许列表.
+	for _, pattern := range c.TopicAllow {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			o.add("%s.topic_allow: malformed pattern %q", key, pattern)
+		}
+	}
+
+	// 2. 默认主题.
+	if !c.AllowTopic(c.Topic) {
+		o.add("%s.topic: invalid or not allowed topic %q", key, c.Topic)
+	}
+
+	// 3. 级别主题.
+	for l, topic := range c.TopicLevel {
+		o.level(fmt.Sprintf("%s.topic_level", key), l, false)
+		if !c.AllowTopic(topic) {
+			o.add("%s.topic_level.%s: invalid or not allowed topic %q", key, l, topic)
+		}
+	}
+
+	// 4. 主题模板.
+	//    占位符须成对且非空, 如: {service}.
+	for s := c.TopicTemplate; s != ""; {
+		i := strings.IndexAny(s, "{}")
+		if i < 0 {
+			break
+		}
+		j := strings.IndexByte(s[i+1:], '}')
+		if s[i] == '}' || j <= 0 || strings.IndexByte(s[i+1:i+1+j], '{') >= 0 {
+			o.add("%s.topic_template: malformed template %q", key, c.TopicTemplate)
+			break
+		}
+		s = s[i+j+2:]
+	}
+}
+
+func (o *validator) level(key string, level base.Level, optional bool) {
+	if level == "" && optional {
+		return
+	}
+	if !level.Valid() {
+		o.add("%s: unknown level %q, expect one of DEBUG, INFO, WARN, ERROR, FATAL, OFF", key, level)
+	}
+}
+
+func (o *validator) logAdapter(key string, name base.LogAdapter) bool {
+	switch name {
+	case base.LogTerm, base.LogFile, base.LogKafka, base.LogOtlp:
+		return true
+	}
+	o.add("%s: unknown adapter %q, expect one of term, file, kafka, otlp", key, name)
+	return false
+}
+
+func (o *validator) nonNegative(key string, n int64) {
+	if n < 0 {
+		o.add("%s: must not be negative, got %d", key, n)
+	}
+}
+
+func (o *validator) otlpEncoding(key string, encoding OtlpEncoding) {
+	if encoding != OtlpEncodingJson && encoding != OtlpEncodingProtobuf {
+		o.add("%s: unknown encoding %q, expect protobuf or json", key, encoding)
+	}
+}
+
+func (o *validator) positive(key string, n int64) {
+	if n <= 0 {
+		o.add("%s: must be positive, got %d", key, n)
+	}
+}
+
+// 解析 Kafka 版本.
+//
+//	2.8.1    -> [2 8 1], true
+//	0.10.2.1 -> [0 10 2], true
+func parseKafkaVersion(s string) (v [3]int, ok bool) {
+	if !kafkaVersion.MatchString(s) {
+		return
+	}
+	for i, n := range strings.SplitN(s, ".", 4)[:3] {
+		v[i], _ = strconv.Atoi(n)
+	}
+	return v, true
+}
@@ -18,8 +18,6 @@ package log
 import (
 	"context"
 	"github.com/go-wares/log/base"
-	"github.com/go-wares/log/config"
-	"github.com/go-wares/log/managers"
 )
 
 type (
@@ -35,32 +33,32 @@ type (
 // +---------------------------------------------------------------------------+
 
 func (o Field) Debug(text string) {
-	if config.Config.DebugOn() {
-		managers.Manager.Log(nil, o, base.Debug, text)
+	if std.config.DebugOn() {
+		std.manager.Log(nil, o, base.Debug, text)
 	}
 }
 
 func (o Field) Info(text string) {
-	if config.Config.InfoOn() {
-		managers.Manager.Log(nil, o, base.Info, text)
+	if std.config.InfoOn() {
+		std.manager.Log(nil, o, base.Info, text)
 	}
 }
 
 func (o Field) Warn(text string) {
-	if config.Config.WarnOn() {
-		managers.Manager.Log(nil, o, base.Warn, text)
+	if std.config.WarnOn() {
+		std.manager.Log(nil, o, base.Warn, text)
 	}
 }
 
 func (o Field) Error(text string) {
-	if config.Config.ErrorOn() {
-		managers.Manager.Log(nil, o, base.Error, text)
+	if std.config.ErrorOn() {
+		std.manager.Log(nil, o, base.Error, text)
 	}
 }
 
 func (o Field) Fatal(text string) {
-	if config.Config.FatalOn() {
-		managers.Manager.Log(nil, o, base.Fatal, text)
+	if std.config.FatalOn() {
+		std.manager.Log(nil, o, base.Fatal, text)
 	}
 }
 
@@ -69,32 +67,32 @@ func (o Field) Fatal(text string) {
 // +---------------------------------------------------------------------------+
 
 func (o Field) Debugf(format string, args ...interface{}) {
-	if config.Config.DebugOn() {
-		managers.Manager.Log(nil, o, base.Debug, format, args...)
+	if std.config.DebugOn() {
+		std.manager.Log(nil, o, base.Debug, format, args...)
 	}
 }
 
 func (o Field) Infof(format string, args ...interface{}) {
-	if config.Config.InfoOn() {
-		managers.Manager.Log(nil, o, base.Info, format, args...)
+	if std.config.InfoOn() {
+		std.manager.Log(nil, o, base.Info, format, args...)
 	}
 }
 
 func (o Field) Warnf(format string, args ...interface{}) {
-	if config.Config.WarnOn() {
-		managers.Manager.Log(nil, o, base.Warn, format, args...)
+	if std.config.WarnOn() {
+		std.manager.Log(nil, o, base.Warn, format, args...)
 	}
 }
 
 func (o Field) Errorf(format string, args ...interface{}) {
-	if config.Config.ErrorOn() {
-		managers.Manager.Log(nil, o, base.Error, format, args...)
+	if std.config.ErrorOn() {
+		std.manager.Log(nil, o, base.Error, format, args...)
 	}
 }
 
 func (o Field) Fatalf(format string, args ...interface{}) {
-	if config.Config.FatalOn() {
-		managers.Manager.Log(nil, o, base.Fatal, format, args...)
+	if std.config.FatalOn() {
+		std.manager.Log(nil, o, base.Fatal, format, args...)
 	}
 }
 
@@ -103,31 +101,31 @@ func (o Field) Fatalf(format string, args ...interface{}) {
 // +---------------------------------------------------------------------------+
 
 func (o Field) Debugfc(ctx context.Context, format string, args ...interface{}) {
-	if config.Config.DebugOn() {
-		managers.Manager.Log(ctx, o, base.Debug, format, args...)
+	if std.config.DebugOn() {
+		std.manager.Log(ctx, o, base.Debug, format, args...)
 	}
 }
 
 func (o Field) Infofc(ctx context.Context, format string, args ...interface{}) {
-	if config.Config.InfoOn() {
-		managers.Manager.Log(ctx, o, base.Info, format, args...)
+	if std.config.InfoOn() {
+		std.manager.Log(ctx, o, base.Info, format, args...)
 	}
 }
 
 func (o Field) Warnfc(ctx context.Context, format string, args ...interface{}) {
-	if config.Config.WarnOn() {
-		managers.Manager.Log(ctx, o, base.Warn, format, args...)
+	if std.config.WarnOn() {
+		std.manager.Log(ctx, o, base.Warn, format, args...)
 	}
 }
 
 func (o Field) Errorfc(ctx context.Context, format string, args ...interface{}) {
-	if config.Config.ErrorOn() {
-		managers.Manager.Log(ctx, o, base.Error, format, args...)
+	if std.config.ErrorOn() {
+		std.manager.Log(ctx, o, base.Error, format, args...)
 	}
 }
 
 func (o Field) Fatalfc(ctx context.Context, format string, args ...interface{}) {
-	if config.Config.FatalOn() {
-		managers.Manager.Log(ctx, o, base.Fatal, format, args...)
+	if std.config.FatalOn() {
+		std.manager.Log(ctx, o, base.Fatal, format, args...)
 	}
 }
@@ -0,0 +1,78 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-29
+
+package log
+
+import (
+	"github.com/go-wares/log/adapters"
+	"math"
+	"time"
+)
+
+// 类型字段.
+//
+// 与 Field 字典相比, 类型字段不分配内存, 编码时无须反射; 用于高频调用的场景.
+//
+//	log.Info("paid",
+//	    log.String("order", id),
+//	    log.Int("amount", 100),
+//	    log.Duration("cost", time.Since(begin)),
+//	)
+
+func Any(key string, value interface{}) adapters.Field {
+	return adapters.Field{Key: key, Kind: adapters.FieldAny, Any: value}
+}
+
+func Bool(key string, value bool) adapters.Field {
+	f := adapters.Field{Key: key, Kind: adapters.FieldBool}
+	if value {
+		f.Int = 1
+	}
+	return f
+}
+
+func Duration(key string, value time.Duration) adapters.Field {
+	return adapters.Field{Key: key, Kind: adapters.FieldDuration, Int: int64(value)}
+}
+
+// Err
+// 错误字段, 字段名为 error.
+func Err(err error) adapters.Field {
+	return adapters.Field{Key: "error", Kind: adapters.FieldError, Any: err}
+}
+
+func Float64(key string, value float64) adapters.Field {
+	return adapters.Field{Key: key, Kind: adapters.FieldFloat, Int: int64(math.Float64bits(value))}
+}
+
+func Int(key string, value int) adapters.Field {
+	return adapters.Field{Key: key, Kind: adapters.FieldInt, Int: int64(value)}
+}
+
+func Int64(key string, value int64) adapters.Field {
+	return adapters.Field{Key: key, Kind: adapters.FieldInt, Int: value}
+}
+
+func String(key, value string) adapters.Field {
+	return adapters.Field{Key: key, Kind: adapters.FieldString, Str: value}
+}
+
+func Time(key string, value time.Time) adapters.Field {
+	return adapters.Field{Key: key, Kind: adapters.FieldTime, Int: value.UnixNano()}
+}
+
+func Uint64(key string, value uint64) adapters.Field {
+	return adapters.Field{Key: key, Kind: adapters.FieldUint, Int: int64(value)}
+}
@@ -17,47 +17,43 @@ package log
 
 import (
 	"context"
+	"github.com/go-wares/log/adapters"
 	"github.com/go-wares/log/base"
-	"github.com/go-wares/log/config"
-	"github.com/go-wares/log/managers"
+	"time"
 )
 
 func Stop() {
-	managers.Manager.Stop()
+	std.Stop()
+}
+
+// Dropped
+// 各适配器因数据桶溢出丢弃的数量.
+func Dropped() map[string]uint64 {
+	return std.Dropped()
 }
 
 // +---------------------------------------------------------------------------+
 // | Logger methods                                                            |
 // +---------------------------------------------------------------------------+
 
-func Debug(text string) {
-	if config.Config.DebugOn() {
-		managers.Manager.Log(nil, nil, base.Debug, text)
-	}
+func Debug(text string, fields ...adapters.Field) {
+	std.Debug(text, fields...)
 }
 
-func Info(text string) {
-	if config.Config.InfoOn() {
-		managers.Manager.Log(nil, nil, base.Info, text)
-	}
+func Info(text string, fields ...adapters.Field) {
+	std.Info(text, fields...)
 }
 
-func Warn(text string) {
-	if config.Config.WarnOn() {
-		managers.Manager.Log(nil, nil, base.Warn, text)
-	}
+func Warn(text string, fields ...adapters.Field) {
+	std.Warn(text, fields...)
 }
 
-func Error(text string) {
-	if config.Config.ErrorOn() {
-		managers.Manager.Log(nil, nil, base.Error, text)
-	}
+func Error(text string, fields ...adapters.Field) {
+	std.Error(text, fields...)
 }
 
-func Fatal(text string) {
-	if config.Config.FatalOn() {
-		managers.Manager.Log(nil, nil, base.Fatal, text)
-	}
+func Fatal(text string, fields ...adapters.Field) {
+	std.Fatal(text, fields...)
 }
 
 // +---------------------------------------------------------------------------+
@@ -65,33 +61,23 @@ func Fatal(text string) {
 // +---------------------------------------------------------------------------+
 
 func Debugf(format string, args ...interface{}) {
-	if config.Config.DebugOn() {
-		managers.Manager.Log(nil, nil, base.Debug, format, args...)
-	}
+	std.Debugf(format, args...)
 }
 
 func Infof(format string, args ...interface{}) {
-	if config.Config.InfoOn() {
-		managers.Manager.Log(nil, nil, base.Info, format, args...)
-	}
+	std.Infof(format, args...)
 }
 
 func Warnf(format string, args ...interface{}) {
-	if config.Config.WarnOn() {
-		managers.Manager.Log(nil, nil, base.Warn, format, args...)
-	}
+	std.Warnf(format, args...)
 }
 
 func Errorf(format string, args ...interface{}) {
-	if config.Config.ErrorOn() {
-		managers.Manager.Log(nil, nil, base.Error, format, args...)
-	}
+	std.Errorf(format, args...)
 }
 
 func Fatalf(format string, args ...interface{}) {
-	if config.Config.FatalOn() {
-		managers.Manager.Log(nil, nil, base.Fatal, format, args...)
-	}
+	std.Fatalf(format, args...)
 }
 
 // +---------------------------------------------------------------------------+
@@ -99,31 +85,46 @@ func Fatalf(format string, args ...interface{}) {
 // +---------------------------------------------------------------------------+
 
 func Debugfc(ctx context.Context, format string, args ...interface{}) {
-	if config.Config.DebugOn() {
-		managers.Manager.Log(ctx, nil, base.Debug, format, args...)
-	}
+	std.Debugfc(ctx, format, args...)
 }
 
 func Infofc(ctx context.Context, format string, args ...interface{}) {
-	if config.Config.InfoOn() {
-		managers.Manager.Log(ctx, nil, base.Info, format, args...)
-	}
+	std.Infofc(ctx, format, args...)
 }
 
 func Warnfc(ctx context.Context, format string, args ...interface{}) {
-	if config.Config.WarnOn() {
-		managers.Manager.Log(ctx, nil, base.Warn, format, args...)
-	}
+	std.Warnfc(ctx, format, args...)
 }
 
 func Errorfc(ctx context.Context, format string, args ...interface{}) {
-	if config.Config.ErrorOn() {
-		managers.Manager.Log(ctx, nil, base.Error, format, args...)
-	}
+	std.Errorfc(ctx, format, args...)
 }
 
 func Fatalfc(ctx context.Context, format string, args ...interface{}) {
-	if config.Config.FatalOn() {
-		managers.Manager.Log(ctx, nil, base.Fatal, format, args...)
-	}
+	std.Fatalfc(ctx, format, args...)
+}
+
+// +---------------------------------------------------------------------------+
+// | Level methods                                                             |
+// +---------------------------------------------------------------------------+
+
+// GetLevel
+// 当前日志级别.
+func GetLevel() base.LogLevel {
+	return std.GetLevel()
+}
+
+// SetLevel
+// 修改日志级别, 运行期间立即生效.
+func SetLevel(level base.Level) error {
+	return std.SetLevel(level)
+}
+
+// SetLevelFor
+// 临时修改日志级别, 指定时长后还原.
+//
+//	// 开启 DEBUG 日志10分钟.
+//	log.SetLevelFor("DEBUG", time.Minute * 10)
+func SetLevelFor(level base.Level, duration time.Duration) error {
+	return std.SetLevelFor(level, duration)
 }
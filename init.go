@@ -16,16 +16,14 @@
 package log
 
 import (
-	"github.com/go-wares/log/config"
-	"github.com/go-wares/log/managers"
 	"sync"
 	"time"
 )
 
 func init() {
 	new(sync.Once).Do(func() {
-		if *config.Config.AutoStart {
-			go managers.Manager.Start()
+		if *std.config.Snapshot().AutoStart {
+			go std.Start()
 			time.Sleep(time.Millisecond)
 		}
 	})
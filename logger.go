@@ -0,0 +1,273 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-25
+
+package log
+
+import (
+	"context"
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+	"github.com/go-wares/log/managers"
+	"github.com/go-wares/log/trace"
+	"net/http"
+	"time"
+)
+
+var (
+	// 默认实例.
+	// 包函数均以此实例输出, 使用全局配置和全局管理器.
+	std = &Logger{
+		config:  config.Config,
+		manager: managers.Manager,
+		tracer:  trace.NewTracer(nil),
+	}
+)
+
+type (
+	// Options
+	// 实例选项.
+	Options struct {
+		// 实例配置.
+		// 未指定时使用默认配置(config.New()), 不读取配置文件及环境变量.
+		Config *config.Configuration
+	}
+
+	// Logger
+	// 日志实例.
+	//
+	// 持有独立的配置、日志适配器、链路适配器和 Keeper, 用于同一进程内的类库与
+	// 应用分别输出日志, 或在测试中隔离日志.
+	//
+	//	c, _ := config.NewFromFile("/etc/lib/log.yaml")
+	//	logger := log.New(log.Options{Config: c})
+	//	defer logger.Stop()
+	//
+	//	logger.Info("info")
+	Logger struct {
+		config  *config.Configuration
+		manager managers.Management
+		tracer  *trace.Tracer
+
+		// 绑定字段及上下文.
+		// 由 With 系列方法创建副本时设置, 创建后不再修改.
+		ctx    context.Context
+		fields Field
+	}
+)
+
+// New
+// 创建日志实例.
+//
+// 配置开启自动启动(auto_start)时立即启动, 否则须调用 Start 方法.
+func New(opts Options) *Logger {
+	o := &Logger{config: opts.Config}
+
+	if o.config == nil {
+		o.config = config.New()
+	}
+
+	o.manager = managers.New(o.config)
+	o.tracer = trace.NewTracer(o.manager)
+
+	if *o.config.Snapshot().AutoStart {
+		go o.manager.Start()
+		time.Sleep(time.Millisecond)
+	}
+	return o
+}
+
+// Config
+// 实例配置.
+func (o *Logger) Config() *config.Configuration { return o.config }
+
+// Dropped
+// 各适配器因数据桶溢出丢弃的数量.
+//
+// 键为适配器类型, 如: log_file, log_kafka, trace_jaeger; 用于监控告警.
+func (o *Logger) Dropped() map[string]uint64 { return o.manager.Dropped() }
+
+// Start
+// 启动实例, 阻塞至 Stop 调用.
+func (o *Logger) Start() { o.manager.Start() }
+
+// Stop
+// 退出实例, 等待全部适配器完成刷新.
+func (o *Logger) Stop() { o.manager.Stop() }
+
+// +---------------------------------------------------------------------------+
+// | Logger methods                                                            |
+// +---------------------------------------------------------------------------+
+
+func (o *Logger) Debug(text string, fields ...adapters.Field) {
+	if o.config.DebugOn() {
+		o.send(base.Debug, text, fields)
+	}
+}
+
+func (o *Logger) Info(text string, fields ...adapters.Field) {
+	if o.config.InfoOn() {
+		o.send(base.Info, text, fields)
+	}
+}
+
+func (o *Logger) Warn(text string, fields ...adapters.Field) {
+	if o.config.WarnOn() {
+		o.send(base.Warn, text, fields)
+	}
+}
+
+func (o *Logger) Error(text string, fields ...adapters.Field) {
+	if o.config.ErrorOn() {
+		o.send(base.Error, text, fields)
+	}
+}
+
+func (o *Logger) Fatal(text string, fields ...adapters.Field) {
+	if o.config.FatalOn() {
+		o.send(base.Fatal, text, fields)
+	}
+}
+
+// +---------------------------------------------------------------------------+
+// | Logger methods with formatter                                             |
+// +---------------------------------------------------------------------------+
+
+func (o *Logger) Debugf(format string, args ...interface{}) {
+	if o.config.DebugOn() {
+		o.manager.Log(o.ctx, o.fields, base.Debug, format, args...)
+	}
+}
+
+func (o *Logger) Infof(format string, args ...interface{}) {
+	if o.config.InfoOn() {
+		o.manager.Log(o.ctx, o.fields, base.Info, format, args...)
+	}
+}
+
+func (o *Logger) Warnf(format string, args ...interface{}) {
+	if o.config.WarnOn() {
+		o.manager.Log(o.ctx, o.fields, base.Warn, format, args...)
+	}
+}
+
+func (o *Logger) Errorf(format string, args ...interface{}) {
+	if o.config.ErrorOn() {
+		o.manager.Log(o.ctx, o.fields, base.Error, format, args...)
+	}
+}
+
+func (o *Logger) Fatalf(format string, args ...interface{}) {
+	if o.config.FatalOn() {
+		o.manager.Log(o.ctx, o.fields, base.Fatal, format, args...)
+	}
+}
+
+// +---------------------------------------------------------------------------+
+// | Logger methods with formatter and context                                 |
+// +---------------------------------------------------------------------------+
+
+func (o *Logger) Debugfc(ctx context.Context, format string, args ...interface{}) {
+	if o.config.DebugOn() {
+		o.manager.Log(o.context(ctx), o.fields, base.Debug, format, args...)
+	}
+}
+
+func (o *Logger) Infofc(ctx context.Context, format string, args ...interface{}) {
+	if o.config.InfoOn() {
+		o.manager.Log(o.context(ctx), o.fields, base.Info, format, args...)
+	}
+}
+
+func (o *Logger) Warnfc(ctx context.Context, format string, args ...interface{}) {
+	if o.config.WarnOn() {
+		o.manager.Log(o.context(ctx), o.fields, base.Warn, format, args...)
+	}
+}
+
+func (o *Logger) Errorfc(ctx context.Context, format string, args ...interface{}) {
+	if o.config.ErrorOn() {
+		o.manager.Log(o.context(ctx), o.fields, base.Error, format, args...)
+	}
+}
+
+func (o *Logger) Fatalfc(ctx context.Context, format string, args ...interface{}) {
+	if o.config.FatalOn() {
+		o.manager.Log(o.context(ctx), o.fields, base.Fatal, format, args...)
+	}
+}
+
+// +---------------------------------------------------------------------------+
+// | Level methods                                                             |
+// +---------------------------------------------------------------------------+
+
+// GetLevel
+// 当前日志级别.
+func (o *Logger) GetLevel() base.LogLevel {
+	return o.config.GetLevel()
+}
+
+// SetLevel
+// 修改日志级别, 运行期间立即生效.
+func (o *Logger) SetLevel(level base.Level) error {
+	return o.config.SetLevel(level)
+}
+
+// SetLevelFor
+// 临时修改日志级别, 指定时长后还原.
+func (o *Logger) SetLevelFor(level base.Level, duration time.Duration) error {
+	return o.config.SetLevelFor(level, duration)
+}
+
+// +---------------------------------------------------------------------------+
+// | Trace methods                                                             |
+// +---------------------------------------------------------------------------+
+
+func (o *Logger) NewSpan(name string) adapters.Span {
+	return o.tracer.NewSpan(name)
+}
+
+func (o *Logger) NewSpanFromContext(ctx context.Context, name string) adapters.Span {
+	return o.tracer.NewSpanFromContext(ctx, name)
+}
+
+func (o *Logger) NewSpanFromRequest(req *http.Request, name string) adapters.Span {
+	return o.tracer.NewSpanFromRequest(req, name)
+}
+
+func (o *Logger) NewTrace(name string) adapters.Trace {
+	return o.tracer.NewTrace(name)
+}
+
+func (o *Logger) NewTraceFromContext(ctx context.Context, name string) adapters.Trace {
+	return o.tracer.NewTraceFromContext(ctx, name)
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+// 发送日志.
+// 正文不格式化, 类型字段复制到日志的字段列表.
+func (o *Logger) send(level base.LogLevel, text string, fields []adapters.Field) {
+	line := adapters.NewLine(o.ctx, level, text)
+	line.Fields = append(line.Fields, fields...)
+
+	if o.fields != nil {
+		line.Attr = adapters.Attr(o.fields)
+	}
+
+	o.manager.Send(line)
+}
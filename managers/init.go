@@ -16,11 +16,12 @@
 package managers
 
 import (
+	"github.com/go-wares/log/config"
 	"sync"
 )
 
 func init() {
 	new(sync.Once).Do(func() {
-		Manager = (&manager{}).init()
+		Manager = (&manager{config: config.Config, global: true}).init()
 	})
 }
@@ -21,8 +21,13 @@ import (
 	"github.com/go-wares/log/adapters"
 	"github.com/go-wares/log/adapters/log_file"
 	"github.com/go-wares/log/adapters/log_kafka"
+	"github.com/go-wares/log/adapters/log_multi"
+	"github.com/go-wares/log/adapters/log_otlp"
 	"github.com/go-wares/log/adapters/log_term"
 	"github.com/go-wares/log/adapters/trace_jaeger"
+	"github.com/go-wares/log/adapters/trace_kafka"
+	"github.com/go-wares/log/adapters/trace_otlp"
+	"github.com/go-wares/log/adapters/trace_zipkin"
 	"github.com/go-wares/log/base"
 	"github.com/go-wares/log/config"
 	"github.com/go-wares/log/trace"
@@ -44,38 +49,87 @@ type (
 	// Management
 	// 基础管理器.
 	Management interface {
+		Dropped() map[string]uint64
+		GetConfig() *config.Configuration
 		GetLogAdapter() adapters.LogAdapter
 		GetTraceAdapter() adapters.TraceAdapter
 		Log(ctx context.Context, fields map[string]interface{}, level base.LogLevel, format string, args ...interface{})
+		Send(line *adapters.Line)
 		Start()
 		Stop()
 	}
 
 	manager struct {
 		cancel context.CancelFunc
+		config *config.Configuration
 		ctx    context.Context
+		global bool
 		keeper base.Keeper
 		mu     sync.RWMutex
 		name   string
+		reload sync.Mutex
 
 		logAdapter   adapters.LogAdapter
+		logMulti     *log_multi.Manager
 		traceAdapter adapters.TraceAdapter
 	}
 )
 
-func (o *manager) GetLogAdapter() adapters.LogAdapter     { return o.logAdapter }
-func (o *manager) GetTraceAdapter() adapters.TraceAdapter { return o.traceAdapter }
+// New
+// 基于指定配置创建管理器.
+//
+// 管理器持有独立的日志适配器、链路适配器和 Keeper, 不修改全局的 Manager 及
+// trace.LogManager, trace.TraceManager.
+func New(c *config.Configuration) Management {
+	return (&manager{config: c}).init()
+}
 
-func (o *manager) Log(ctx context.Context, fields map[string]interface{}, level base.LogLevel, format string, args ...interface{}) {
-	if o.logAdapter != nil {
-		line := adapters.NewLine(ctx, level, format, args...)
+// Dropped
+// 各适配器因数据桶溢出丢弃的数量.
+//
+// 仅包含使用有界数据桶的适配器, 键为 log_ 或 trace_ 加适配器名称.
+func (o *manager) Dropped() map[string]uint64 {
+	res := make(map[string]uint64)
 
-		if fields != nil {
-			line.Attr = fields
+	// 1. 日志适配器.
+	for _, name := range o.logMulti.Names() {
+		if dc, ok := o.logMulti.Get(name).(adapters.DropCounter); ok {
+			res["log_"+string(name)] = dc.Dropped()
 		}
+	}
+
+	// 2. 链路适配器.
+	if dc, ok := o.GetTraceAdapter().(adapters.DropCounter); ok {
+		res["trace_"+string(o.config.Snapshot().TraceAdapter)] = dc.Dropped()
+	}
+	return res
+}
+
+func (o *manager) GetConfig() *config.Configuration   { return o.config }
+func (o *manager) GetLogAdapter() adapters.LogAdapter { return o.logAdapter }
+
+func (o *manager) GetTraceAdapter() adapters.TraceAdapter {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.traceAdapter
+}
+
+func (o *manager) Log(ctx context.Context, fields map[string]interface{}, level base.LogLevel, format string, args ...interface{}) {
+	line := adapters.NewLine(ctx, level, format, args...)
 
-		o.logAdapter.Send(line)
+	if fields != nil {
+		line.Attr = fields
 	}
+
+	o.Send(line)
+}
+
+// Send
+// 发送日志.
+//
+// 按路由规则决定日志发往哪些适配器, 规则仅匹配1次.
+func (o *manager) Send(line *adapters.Line) {
+	o.logMulti.Send(line)
 }
 
 func (o *manager) Start() {
@@ -102,7 +156,7 @@ func (o *manager) Start() {
 	}()
 
 	if err := o.keeper.Start(o.ctx); err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "%v", err)
+		_, _ = fmt.Fprintf(base.Stderr(), "%v", err)
 	}
 }
 
@@ -126,6 +180,17 @@ func (o *manager) Stop() {
 // | Event methods                                                             |
 // +---------------------------------------------------------------------------+
 
+// 配置变更.
+// 重建或重启受影响的适配器.
+func (o *manager) onChange(change *config.Change) {
+	o.reload.Lock()
+	defer o.reload.Unlock()
+
+	o.reloadAdapterResource(change)
+	o.reloadLogAdapter(change)
+	o.reloadTraceAdapter(change)
+}
+
 func (o *manager) onListen(ctx context.Context) (ignored bool) {
 	for {
 		select {
@@ -145,15 +210,23 @@ func (o *manager) init() *manager {
 
 	o.initLogAdapter()
 	o.initTraceAdapter()
-	o.initAdapterResource()
+	o.initWatcher()
+
+	// 全局资源.
+	// 仅全局管理器设置, 非全局实例共用.
+	if o.global {
+		o.initAdapterResource()
+	}
 	return o
 }
 
 func (o *manager) initAdapterResource() {
+	c := o.config.Snapshot()
+
 	// 架构名称.
 	adapters.Resource.
-		Set("service.name", config.Config.Name).
-		Set("service.version", config.Config.Version).
+		Set("service.name", c.Name).
+		Set("service.version", c.Version).
 		Set("deploy.arch", fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH)).
 		Set("deploy.go", runtime.Version()).
 		Set("deploy.pid", os.Getpid())
@@ -174,39 +247,171 @@ func (o *manager) initAdapterResource() {
 			}
 		}
 	}
-	adapters.Resource.Set("deploy.addr", strings.Join(config.Config.Addr, ", "))
+	adapters.Resource.Set("deploy.addr", strings.Join(c.Addr, ", "))
 }
 
 func (o *manager) initLogAdapter() {
+	o.logMulti = log_multi.NewWithConfig(o.config)
+	o.logAdapter = o.logMulti
+
 	// 1. 日志适配器.
-	switch config.Config.LogAdapter {
-	case base.LogFile:
-		o.logAdapter = log_file.New()
-	case base.LogTerm:
-		o.logAdapter = log_term.New()
-	case base.LogKafka:
-		o.logAdapter = log_kafka.New()
+	//    按配置顺序加入组合管理器, 每行日志按路由规则发送到适配器.
+	for _, name := range o.config.Snapshot().LogAdapter {
+		if adapter := o.newLogAdapter(name); adapter != nil {
+			o.logMulti.Add(name, adapter)
+		}
 	}
 
 	// 2. 加为子 Keeper.
-	if o.logAdapter != nil {
-		o.keeper.Add(o.logAdapter.Keeper())
-	} else {
-	}
+	o.keeper.Add(o.logAdapter.Keeper())
 }
 
 func (o *manager) initTraceAdapter() {
 	// 1. 链路适配器.
-	switch config.Config.TraceAdapter {
-	case base.TraceJaeger:
-		o.traceAdapter = trace_jaeger.New()
-	}
+	o.traceAdapter = o.newTraceAdapter(o.config.Snapshot().TraceAdapter)
 
-	// 2. 加为子 Keeper.
-	if o.traceAdapter != nil {
+	// 2. 全局链路.
+	if o.global {
 		trace.LogManager = o.logAdapter
-		trace.TraceManager = o.traceAdapter
+		trace.SetTraceManager(o.traceAdapter)
+	}
 
+	// 3. 加为子 Keeper.
+	if o.traceAdapter != nil {
 		o.keeper.Add(o.traceAdapter.Keeper())
 	}
 }
+
+func (o *manager) initWatcher() {
+	o.config.OnChange(o.onChange)
+
+	if *o.config.Snapshot().Watch {
+		o.keeper.Add(newWatcher(o.config).keeper)
+	}
+}
+
+func (o *manager) newLogAdapter(name base.LogAdapter) adapters.LogAdapter {
+	switch name {
+	case base.LogFile:
+		return log_file.NewWithConfig(o.config)
+	case base.LogTerm:
+		return log_term.NewWithConfig(o.config)
+	case base.LogKafka:
+		return log_kafka.NewWithConfig(o.config)
+	case base.LogOtlp:
+		return log_otlp.NewWithConfig(o.config)
+	}
+	return nil
+}
+
+func (o *manager) newTraceAdapter(name base.TraceAdapter) adapters.TraceAdapter {
+	switch name {
+	case base.TraceJaeger:
+		return trace_jaeger.NewWithConfig(o.config)
+	case base.TraceZipkin:
+		return trace_zipkin.NewWithConfig(o.config)
+	case base.TraceKafka:
+		return trace_kafka.NewWithConfig(o.config)
+	case base.TraceOtlp:
+		return trace_otlp.NewWithConfig(o.config)
+	}
+	return nil
+}
+
+// 重载系统资源.
+// 仅全局管理器在应用名称或版本号变更时发布新的资源.
+func (o *manager) reloadAdapterResource(change *config.Change) {
+	if !o.global || !change.Service {
+		return
+	}
+
+	var (
+		c   = o.config.Snapshot()
+		res = make(adapters.Attr)
+	)
+	for k, v := range adapters.GetResource() {
+		res[k] = v
+	}
+	adapters.SetResource(res.
+		Set("service.name", c.Name).
+		Set("service.version", c.Version),
+	)
+}
+
+// 重载日志适配器.
+func (o *manager) reloadLogAdapter(change *config.Change) {
+	var (
+		added = base.LogAdapters{}
+		names = o.config.Snapshot().LogAdapter
+	)
+
+	// 1. 删除适配器.
+	//    已从配置中移除或连接参数变更须重建.
+	for _, name := range o.logMulti.Names() {
+		if !names.Contains(name) || change.LogRebuild.Contains(name) {
+			o.logMulti.Del(name)
+		}
+	}
+
+	// 2. 添加适配器.
+	for _, name := range names {
+		if o.logMulti.Get(name) == nil {
+			if adapter := o.newLogAdapter(name); adapter != nil {
+				o.logMulti.Add(name, adapter)
+				added = append(added, name)
+			}
+		}
+	}
+
+	// 3. 重启适配器.
+	//    刷新频率变更, 重启后按新频率刷新; 新添加的适配器已按新配置启动.
+	for _, name := range change.LogRestart {
+		if added.Contains(name) {
+			continue
+		}
+		if adapter := o.logMulti.Get(name); adapter != nil {
+			adapter.Keeper().Restart()
+		}
+	}
+}
+
+// 重载链路适配器.
+func (o *manager) reloadTraceAdapter(change *config.Change) {
+	// 1. 重启适配器.
+	if !change.TraceAdapter {
+		if adapter := o.GetTraceAdapter(); change.TraceRestart && adapter != nil {
+			adapter.Keeper().Restart()
+		}
+		return
+	}
+
+	// 2. 替换适配器.
+	//    新旧适配器的 Keeper 可能同名, 先移除旧 Keeper 再添加; 发布新适配器
+	//    后, 此后的跨度不再发往旧适配器.
+	old := o.GetTraceAdapter()
+	if old != nil {
+		o.keeper.Del(old.Keeper())
+	}
+
+	adapter := o.newTraceAdapter(o.config.Snapshot().TraceAdapter)
+	if adapter != nil {
+		o.keeper.Add(adapter.Keeper())
+	}
+
+	o.mu.Lock()
+	o.traceAdapter = adapter
+	o.mu.Unlock()
+
+	if o.global {
+		trace.SetTraceManager(adapter)
+	}
+
+	// 3. 退出旧适配器.
+	//    等待其 Keeper 退出, 即数据桶中的跨度已上报.
+	if old != nil {
+		old.Keeper().Stop()
+		for !old.Keeper().Stopped() {
+			time.Sleep(time.Millisecond * 10)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-23
+
+package managers
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+	"os"
+	"time"
+)
+
+type (
+	// 配置监听.
+	//
+	// 定时检查配置文件的修改时间, 变更后重新加载配置.
+	watcher struct {
+		config  *config.Configuration
+		keeper  base.Keeper
+		modTime time.Time
+		name    string
+	}
+)
+
+func newWatcher(c *config.Configuration) *watcher {
+	return (&watcher{config: c}).init()
+}
+
+// +---------------------------------------------------------------------------+
+// | Event methods                                                             |
+// +---------------------------------------------------------------------------+
+
+func (o *watcher) onListen(ctx context.Context) (ignored bool) {
+	// 1. 初始时间.
+	o.modTime = o.stat()
+
+	// 2. 定时检查.
+	//    每隔指定时长(默认: 1000ms)检查1次.
+	ticker := time.NewTicker(time.Duration(o.config.Snapshot().WatchMilliseconds) * time.Millisecond)
+	defer ticker.Stop()
+
+	// 3. 监听信号.
+	for {
+		select {
+		case <-ticker.C:
+			o.check()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+func (o *watcher) check() {
+	// 1. 未修改.
+	t := o.stat()
+	if t.IsZero() || t.Equal(o.modTime) {
+		return
+	}
+
+	// 2. 重新加载.
+	o.modTime = t
+	if _, err := o.config.Reload(); err != nil {
+		_, _ = fmt.Fprintf(base.Stderr(), "%s reload: %v\n", o.name, err)
+	}
+}
+
+func (o *watcher) init() *watcher {
+	o.name = fmt.Sprintf("log-watcher")
+	o.keeper = base.NewKeeper(o.name).Listen(o.onListen)
+	return o
+}
+
+func (o *watcher) stat() time.Time {
+	if path := o.config.Path(); path != "" {
+		if info, err := os.Stat(path); err == nil {
+			return info.ModTime()
+		}
+	}
+	return time.Time{}
+}
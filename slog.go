@@ -0,0 +1,185 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-27
+
+//go:build go1.21
+// +build go1.21
+
+package log
+
+import (
+	"context"
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/base"
+	"log/slog"
+	"time"
+)
+
+type (
+	// 标准库 slog 处理器.
+	slogHandler struct {
+		fields Field
+		logger *Logger
+		prefix string
+	}
+)
+
+// SlogHandler
+// 标准库 log/slog 处理器.
+//
+// 日志经全局管理器发往已配置的适配器, 如: 文件、Kafka 等.
+//
+//	slog.SetDefault(slog.New(log.SlogHandler()))
+//	slog.InfoContext(ctx, "paid", "order", id)
+func SlogHandler() slog.Handler {
+	return std.SlogHandler()
+}
+
+// SlogHandler
+// 标准库 log/slog 处理器.
+//
+// 级别对应: 低于 INFO 为 DEBUG, 低于 WARN 为 INFO, 低于 ERROR 为 WARN, 其它为
+// ERROR; 属性写入 Line.Attr, 分组以点号连接, 如: request.method; 上下文中的
+// 链路与 Debugfc 等方法相同.
+func (o *Logger) SlogHandler() slog.Handler {
+	return &slogHandler{logger: o}
+}
+
+// +---------------------------------------------------------------------------+
+// | Interface methods                                                         |
+// +---------------------------------------------------------------------------+
+
+func (o *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	switch slogLevel(level) {
+	case base.Debug:
+		return o.logger.config.DebugOn()
+	case base.Info:
+		return o.logger.config.InfoOn()
+	case base.Warn:
+		return o.logger.config.WarnOn()
+	}
+	return o.logger.config.ErrorOn()
+}
+
+func (o *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	// 1. 绑定字段.
+	//    先复制 With 系列方法绑定在日志实例上的字段, 再复制处理器字段及记录属性,
+	//    同名时后者覆盖前者.
+	fields := make(Field, len(o.logger.fields)+len(o.fields)+r.NumAttrs())
+	for k, v := range o.logger.fields {
+		fields[k] = v
+	}
+	for k, v := range o.fields {
+		fields[k] = v
+	}
+
+	r.Attrs(func(a slog.Attr) bool {
+		slogAttr(fields, o.prefix, a)
+		return true
+	})
+
+	// 2. 创建日志.
+	//    正文不格式化, 使用记录时间; 记录时间为零值时保持创建时间.
+	line := adapters.NewLine(o.logger.context(ctx), slogLevel(r.Level), r.Message)
+	if !r.Time.IsZero() {
+		line.Time = r.Time
+	}
+	if len(fields) > 0 {
+		line.Attr = adapters.Attr(fields)
+	}
+
+	o.logger.manager.Send(line)
+	return nil
+}
+
+func (o *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return o
+	}
+
+	c := &slogHandler{logger: o.logger, prefix: o.prefix, fields: make(Field, len(o.fields)+len(attrs))}
+	for k, v := range o.fields {
+		c.fields[k] = v
+	}
+	for _, a := range attrs {
+		slogAttr(c.fields, o.prefix, a)
+	}
+	return c
+}
+
+func (o *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return o
+	}
+	return &slogHandler{logger: o.logger, prefix: o.prefix + name + ".", fields: o.fields}
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+// 写入属性.
+// 忽略空名称的属性; 分组展开为 group.key, 空名称的分组直接展开.
+func slogAttr(fields Field, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+
+	// 1. 分组属性.
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix = prefix + a.Key + "."
+		}
+		for _, x := range v.Group() {
+			slogAttr(fields, prefix, x)
+		}
+		return
+	}
+
+	// 2. 忽略属性.
+	if a.Key == "" {
+		return
+	}
+
+	// 3. 普通属性.
+	fields[prefix+a.Key] = slogValue(v)
+}
+
+// 日志级别.
+func slogLevel(level slog.Level) base.LogLevel {
+	switch {
+	case level < slog.LevelInfo:
+		return base.Debug
+	case level < slog.LevelWarn:
+		return base.Info
+	case level < slog.LevelError:
+		return base.Warn
+	}
+	return base.Error
+}
+
+// 属性值.
+// 时长、时间及错误转为字符串, 其它保持原值.
+func slogValue(v slog.Value) interface{} {
+	switch v.Kind() {
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	}
+
+	x := v.Any()
+	if err, ok := x.(error); ok {
+		return err.Error()
+	}
+	return x
+}
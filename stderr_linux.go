@@ -0,0 +1,35 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-06-10
+
+package log
+
+import (
+	"os"
+	"syscall"
+)
+
+// 复制标准错误输出的文件描述符.
+func dupStderr() (*os.File, error) {
+	fd, err := syscall.Dup(2)
+	if err != nil {
+		return nil, err
+	}
+	return os.NewFile(uintptr(fd), "/dev/stderr"), nil
+}
+
+// 重定向文件描述符2.
+func redirectStderr(file *os.File) error {
+	return syscall.Dup3(int(file.Fd()), 2, 0)
+}
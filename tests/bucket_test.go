@@ -0,0 +1,178 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-06-03
+
+package tests
+
+import (
+	"github.com/go-wares/log"
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 创建有界数据桶.
+func newBoundedBucket(c *config.Bucket) *adapters.Bucket {
+	return adapters.NewBoundedBucket(func() *config.Bucket { return c })
+}
+
+// 数据桶中日志内容.
+func bucketTexts(b *adapters.Bucket) []string {
+	list, _ := b.Popn(b.Count())
+	texts := make([]string, 0, len(list))
+	for _, v := range list {
+		texts = append(texts, v.(*adapters.Line).Text)
+	}
+	return texts
+}
+
+func TestBucket_DropNewest(t *testing.T) {
+	b := newBoundedBucket(&config.Bucket{Capacity: 2, Overflow: config.OverflowDropNewest})
+	for _, s := range []string{"a", "b", "c"} {
+		b.Add(adapters.NewLine(nil, base.Info, s))
+	}
+
+	if texts := bucketTexts(b); len(texts) != 2 || texts[0] != "a" || texts[1] != "b" || b.Dropped() != 1 {
+		t.Errorf("bucket drop newest: %v, dropped %d", texts, b.Dropped())
+	}
+}
+
+func TestBucket_DropOldest(t *testing.T) {
+	b := newBoundedBucket(&config.Bucket{Capacity: 2, Overflow: config.OverflowDropOldest})
+	for _, s := range []string{"a", "b", "c"} {
+		b.Add(adapters.NewLine(nil, base.Info, s))
+	}
+
+	if texts := bucketTexts(b); len(texts) != 2 || texts[0] != "b" || texts[1] != "c" || b.Dropped() != 1 {
+		t.Errorf("bucket drop oldest: %v, dropped %d", texts, b.Dropped())
+	}
+}
+
+func TestBucket_DropLevel(t *testing.T) {
+	b := newBoundedBucket(&config.Bucket{Capacity: 2, Overflow: config.OverflowDropLevel, OverflowLogLevel: base.Warn})
+	b.Add(adapters.NewLine(nil, base.Info, "a"))
+	b.Add(adapters.NewLine(nil, base.Info, "b"))
+
+	// 1. 低于保留级别, 丢弃新日志.
+	b.Add(adapters.NewLine(nil, base.Debug, "c"))
+
+	// 2. 不低于保留级别, 丢弃最早的日志.
+	b.Add(adapters.NewLine(nil, base.Error, "d"))
+
+	if texts := bucketTexts(b); len(texts) != 2 || texts[0] != "b" || texts[1] != "d" || b.Dropped() != 2 {
+		t.Errorf("bucket drop level: %v, dropped %d", texts, b.Dropped())
+	}
+}
+
+func TestBucket_Block(t *testing.T) {
+	b := newBoundedBucket(&config.Bucket{Capacity: 1, Overflow: config.OverflowBlock, OverflowMilliseconds: 50})
+	b.Add(adapters.NewLine(nil, base.Info, "a"))
+
+	// 1. 超时丢弃.
+	begin := time.Now()
+	b.Add(adapters.NewLine(nil, base.Info, "b"))
+	if d := time.Since(begin); d < 50*time.Millisecond || b.Dropped() != 1 {
+		t.Errorf("bucket block timeout: %v, dropped %d", d, b.Dropped())
+	}
+
+	// 2. 取出后写入.
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		b.Pop()
+	}()
+	b.Add(adapters.NewLine(nil, base.Info, "c"))
+	if texts := bucketTexts(b); len(texts) != 1 || texts[0] != "c" || b.Dropped() != 1 {
+		t.Errorf("bucket block: %v, dropped %d", texts, b.Dropped())
+	}
+}
+
+func TestBucket_Unbounded(t *testing.T) {
+	c, err := config.NewFromBytes([]byte("log_adapter: file\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	// 默认不限容量.
+	b := newBoundedBucket(&c.LogAdapterFile.Bucket)
+	for i := 0; i < 20000; i++ {
+		b.Add(i)
+	}
+	if b.Count() != 20000 || b.Dropped() != 0 {
+		t.Errorf("bucket unbounded: count %d, dropped %d", b.Count(), b.Dropped())
+	}
+}
+
+func TestBucket_DropReport(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+	if err != nil {
+		t.Fatalf("stderr: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	origin := base.Stderr()
+	base.SetStderr(f)
+	defer base.SetStderr(origin)
+
+	// 仅首次丢弃时提示.
+	b := newBoundedBucket(&config.Bucket{Capacity: 1, Overflow: config.OverflowDropNewest})
+	for i := 0; i < 5; i++ {
+		b.Add(i)
+	}
+	body, _ := os.ReadFile(f.Name())
+	if n := strings.Count(string(body), "bucket overflow"); n != 1 || b.Dropped() != 4 {
+		t.Errorf("bucket drop report: %q, dropped %d", body, b.Dropped())
+	}
+}
+
+func TestBucket_LoggerDropped(t *testing.T) {
+	dir := t.TempDir()
+	c, err := config.NewFromBytes([]byte("log_adapter: file\nlog_adapter_file:\n  capacity: 5\n  batch: 100\n  milliseconds: 60000\n  path: " + dir + "\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	logger := log.New(log.Options{Config: c})
+	for i := 0; i < 8; i++ {
+		logger.Info("overflow")
+	}
+
+	if n := logger.Dropped()["log_file"]; n != 3 {
+		t.Errorf("bucket dropped: %d, %v", n, logger.Dropped())
+	}
+	logger.Stop()
+}
+
+func TestBucket_OverflowInvalid(t *testing.T) {
+	if _, err := config.NewFromBytes([]byte("log_adapter_file:\n  overflow: drop_all\n")); err == nil {
+		t.Errorf("bucket overflow: expect error")
+	}
+}
+
+func TestBucket_BlockMulti(t *testing.T) {
+	// 单个日志适配器支持 block.
+	if _, err := config.NewFromBytes([]byte("log_adapter: file\nlog_adapter_file:\n  overflow: block\n")); err != nil {
+		t.Errorf("bucket block: %v", err)
+	}
+
+	// 多个日志适配器时拒绝 block.
+	_, err := config.NewFromBytes([]byte("log_adapter: [term, file]\nlog_adapter_file:\n  overflow: block\n"))
+	if err == nil || !strings.Contains(err.Error(), "log_adapter_file.overflow") {
+		t.Errorf("bucket block multi: %v", err)
+	}
+}
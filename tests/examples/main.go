@@ -58,7 +58,7 @@ func main() {
 		},
 		Level:          "INFO",
 		Time:           curr.Format("2006-01-02T15:04:05.999999Z"),
-		TimestampMs:    curr.UnixMilli(),
+		TimeMs:         curr.UnixMilli(),
 		SpanId:         fmt.Sprintf("span-id-%d", curr.Unix()),
 		TraceId:        fmt.Sprintf("trace-id-%d", curr.Unix()),
 		RequestMethod:  "POST",
@@ -16,8 +16,15 @@
 package tests
 
 import (
+	"encoding/json"
+	"errors"
 	"github.com/go-wares/log"
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
 	"github.com/go-wares/log/managers"
+	"math"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -37,3 +44,115 @@ func TestField(t *testing.T) {
 	field.Error("error")
 	field.Fatal("fatal")
 }
+
+func TestField_Typed(t *testing.T) {
+	text := "100%"
+	line := adapters.NewLine(nil, base.Info, text)
+	defer line.Release()
+
+	line.Attr = adapters.Attr{"uid": 1, "key": "map"}
+	line.Fields = append(line.Fields,
+		log.String("key", "say \"hi\"\n"),
+		log.Int("amount", -3),
+		log.Uint64("max", math.MaxUint64),
+		log.Float64("rate", 0.5),
+		log.Bool("paid", true),
+		log.Duration("cost", 1500*time.Millisecond),
+		log.Err(errors.New("timeout")),
+		log.Any("tags", []string{"a"}),
+	)
+
+	// 1. 正文不格式化.
+	if line.Text != "100%" {
+		t.Errorf("typed text: %q", line.Text)
+	}
+
+	// 2. 编码结果.
+	expect := `{"uid":1,"key":"say \"hi\"\n","amount":-3,"max":18446744073709551615,"rate":0.5,"paid":true,"cost":"1.5s","error":"timeout","tags":["a"]}`
+	if s := string(line.AppendFields(nil)); s != expect {
+		t.Errorf("typed json:\nexpect %s\ngot    %s", expect, s)
+	}
+
+	// 3. 字典合并.
+	if m := line.FieldMap(); m["key"] != "say \"hi\"\n" || m["uid"] != 1 || m["cost"] != "1.5s" {
+		t.Errorf("typed map: %v", m)
+	}
+}
+
+func TestField_TypedAllocs(t *testing.T) {
+	buf := make([]byte, 0, 256)
+	n := testing.AllocsPerRun(100, func() {
+		line := adapters.NewLine(nil, base.Info, "paid")
+		line.Fields = append(line.Fields, log.String("order", "A001"), log.Int("amount", 100), log.Duration("cost", time.Second))
+		buf = line.AppendFields(buf[:0])
+		line.Release()
+	})
+	if n != 0 {
+		t.Errorf("typed allocs: expect 0, got %v", n)
+	}
+}
+
+func TestField_AppendLogData(t *testing.T) {
+	c, err := config.NewFromBytes([]byte("name: order\nversion: 1.2\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	line := adapters.NewLine(log.Context(), base.Info, "paid \"order\"")
+	defer line.Release()
+	line.Attr = adapters.Attr{"uid": 1}
+	line.Fields = append(line.Fields, log.String("note", "say hi"), log.Duration("cost", time.Second))
+
+	// 与 json.Marshal(NewLogData) 编码结果一致.
+	var expect, got map[string]interface{}
+	body, _ := json.Marshal(adapters.NewLogData(c, line))
+	_ = json.Unmarshal(body, &expect)
+	if err = json.Unmarshal(adapters.AppendLogData(nil, c, line), &got); err != nil {
+		t.Fatalf("log data: %v", err)
+	}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("log data:\nexpect %v\ngot    %v", expect, got)
+	}
+}
+
+func BenchmarkField_Map(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		line := adapters.NewLine(nil, base.Info, "paid")
+		line.Attr = adapters.Attr(log.Field{"order": "A001", "amount": 100, "cost": time.Second})
+		_ = line.Attr.Json()
+		line.Release()
+	}
+}
+
+func BenchmarkField_Typed(b *testing.B) {
+	buf := make([]byte, 0, 256)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		line := adapters.NewLine(nil, base.Info, "paid")
+		line.Fields = append(line.Fields, log.String("order", "A001"), log.Int("amount", 100), log.Duration("cost", time.Second))
+		buf = line.AppendFields(buf[:0])
+		line.Release()
+	}
+}
+
+func BenchmarkField_LoggerTyped(b *testing.B) {
+	logger := newBenchLogger(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info("paid", log.String("order", "A001"), log.Int("amount", 100), log.Duration("cost", time.Second))
+	}
+	b.StopTimer()
+	logger.Stop()
+}
+
+// 基准测试实例.
+// 适配器仅接收 ERROR 日志, INFO 日志经完整调用链路后丢弃.
+func newBenchLogger(b *testing.B) *log.Logger {
+	c, err := config.NewFromBytes([]byte("level: info\nlog_adapter: file\nlog_adapter_file:\n  level: error\n  path: " + b.TempDir() + "\n"))
+	if err != nil {
+		b.Fatalf("config: %v", err)
+	}
+	return log.New(log.Options{Config: c})
+}
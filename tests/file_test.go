@@ -0,0 +1,417 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-30
+
+package tests
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"github.com/go-wares/log"
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/adapters/log_file"
+	"github.com/go-wares/log/adapters/log_kafka"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 按格式写入1行日志, 返回文件内容.
+func writeFileFormat(t *testing.T, format string) string {
+	dir := t.TempDir()
+	c, err := config.NewFromBytes([]byte("name: order\nlog_adapter: file\nlog_adapter_file:\n  format: " + format + "\n  path: " + dir + "\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	logger := log.New(log.Options{Config: c})
+	logger.WithContext(log.Context()).With("user_id", 1).Info("paid order", log.String("note", "say hi"))
+	logger.Stop()
+	return strings.TrimSpace(readLogDir(t, dir))
+}
+
+func TestFile_FormatJson(t *testing.T) {
+	var (
+		data = &log_kafka.Data{}
+		text = writeFileFormat(t, "json")
+	)
+
+	if err := json.Unmarshal([]byte(text), data); err != nil {
+		t.Fatalf("file json: %v, %q", err, text)
+	}
+	if data.Content != "paid order" || data.Level != "INFO" || data.ServiceName != "order" || data.TraceId == "" || data.SpanId == "" {
+		t.Errorf("file json: %+v", data)
+	}
+	if data.Keywords["note"] != "say hi" || data.Keywords["user_id"] != float64(1) {
+		t.Errorf("file json fields: %v", data.Keywords)
+	}
+}
+
+func TestFile_FormatLogfmt(t *testing.T) {
+	text := writeFileFormat(t, "logfmt")
+
+	for _, s := range []string{"time=", " level=INFO", " trace_id=", " span_id=", ` msg="paid order"`, ` user_id=1 note="say hi"`} {
+		if !strings.Contains(text, s) {
+			t.Errorf("file logfmt: missing %q in %q", s, text)
+		}
+	}
+}
+
+func TestFile_FormatText(t *testing.T) {
+	text := writeFileFormat(t, "text")
+
+	if !strings.Contains(text, `[INFO] [trace-id=`) || !strings.HasSuffix(text, `{"user_id":1,"note":"say hi"} paid order`) {
+		t.Errorf("file text: %q", text)
+	}
+}
+
+func TestFile_FormatInvalid(t *testing.T) {
+	if _, err := config.NewFromBytes([]byte("log_adapter_file:\n  format: xml\n")); err == nil || !strings.Contains(err.Error(), `log_adapter_file.format: unknown format "xml"`) {
+		t.Errorf("file format: %v", err)
+	}
+}
+
+func TestFile_Rotate(t *testing.T) {
+	dir := t.TempDir()
+	c, err := config.NewFromBytes([]byte("log_adapter: file\nlog_adapter_file:\n  max_size: 1\n  path: " + dir + "\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	// 1. 写入 2.4MB.
+	logger := log.New(log.Options{Config: c})
+	text := strings.Repeat("x", 8*1024)
+	for i := 0; i < 300; i++ {
+		logger.Info(text)
+	}
+	logger.Stop()
+
+	// 2. 切割文件.
+	//    当前文件及带序号的文件均不超过 1MB.
+	var names []string
+	_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			names = append(names, info.Name())
+			if info.Size() > 1024*1024 {
+				t.Errorf("file rotate: %s size %d", info.Name(), info.Size())
+			}
+		}
+		return err
+	})
+	if len(names) < 3 || !strings.HasSuffix(names[0], ".1.log") {
+		t.Errorf("file rotate: %v", names)
+	}
+}
+
+// 写入历史文件, age 为修改时间距今的天数.
+func writeRetentionFiles(t *testing.T, dir string, files map[string]int) {
+	_ = os.MkdirAll(dir, 0755)
+	for name, age := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(name+"\n"), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		mod := time.Now().Add(-time.Duration(age) * 24 * time.Hour)
+		_ = os.Chtimes(path, mod, mod)
+	}
+}
+
+// 目录下的文件名.
+func retentionNames(dir string) []string {
+	names := make([]string, 0)
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestFile_Retention(t *testing.T) {
+	dir := t.TempDir()
+	c, err := config.NewFromBytes([]byte("log_adapter: file\nlog_adapter_file:\n  max_age: 5\n  max_count: 1\n  compress: true\n  error_name: 2006-01-02.error\n  path: " + dir + "\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	// 1. 历史文件.
+	//    按天数删除 01, 按数量删除 02, 压缩 03; 错误日志单独计数; 其它文件
+	//    不参与清理.
+	history := filepath.Join(dir, "2023-01")
+	writeRetentionFiles(t, history, map[string]int{
+		"2023-01-01.log":       10,
+		"2023-01-02.log":       3,
+		"2023-01-03.log":       2,
+		"2023-01-02.error.log": 3,
+		"2023-01-03.error.log": 2,
+		"other.log":            10,
+	})
+
+	// 2. 启动清理.
+	logger := log.New(log.Options{Config: c})
+	logger.Info("active")
+	time.Sleep(time.Millisecond * 100)
+	logger.Stop()
+
+	// 3. 校验结果.
+	if names := retentionNames(history); strings.Join(names, ",") != "2023-01-03.error.log.gz,2023-01-03.log.gz,other.log" {
+		t.Fatalf("file retention: %v", names)
+	}
+	if f, err := os.Open(filepath.Join(history, "2023-01-03.log.gz")); err == nil {
+		defer func() { _ = f.Close() }()
+		if r, err := gzip.NewReader(f); err != nil {
+			t.Errorf("file retention gzip: %v", err)
+		} else if body, _ := io.ReadAll(r); string(body) != "2023-01-03.log\n" {
+			t.Errorf("file retention gzip: %q", body)
+		}
+	}
+
+	// 4. 当前文件不参与清理.
+	if !strings.Contains(readLogDir(t, filepath.Join(dir, time.Now().Format("2006-01"))), "active") {
+		t.Errorf("file retention: active file removed")
+	}
+}
+
+func TestFile_RetentionKeep(t *testing.T) {
+	dir := t.TempDir()
+	c, err := config.NewFromBytes([]byte("log_adapter: file\nlog_adapter_file:\n  max_age: 5\n  compress: true\n  path: " + dir + "\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	// 1. 历史文件.
+	//    2023-01-03.log.gz 已存在, 压缩 2023-01-03.log 时不可覆盖.
+	var (
+		current = filepath.Join(dir, time.Now().Format("2006-01"))
+		history = filepath.Join(dir, "2023-01")
+		mod     = time.Now().Add(-time.Hour)
+	)
+	_ = os.MkdirAll(history, 0755)
+	_ = os.WriteFile(filepath.Join(history, "2023-01-03.log"), []byte("c\n"), 0644)
+	if f, err := os.Create(filepath.Join(history, "2023-01-03.log.gz")); err == nil {
+		w := gzip.NewWriter(f)
+		_, _ = w.Write([]byte("old\n"))
+		_ = w.Close()
+		_ = f.Close()
+	}
+	for _, name := range []string{"2023-01-03.log", "2023-01-03.log.gz"} {
+		_ = os.Chtimes(filepath.Join(history, name), mod, mod)
+	}
+
+	// 2. 当前时间段的过期文件.
+	//    删除文件后保留目录.
+	writeRetentionFiles(t, current, map[string]int{"2000-01-01.log": 10})
+	expired := filepath.Join(current, "2000-01-01.log")
+
+	// 3. 启动清理.
+	logger := log.New(log.Options{Config: c})
+	time.Sleep(time.Millisecond * 100)
+	if _, err = os.Stat(expired); !os.IsNotExist(err) {
+		t.Errorf("file retention: expired file kept")
+	}
+	if _, err = os.Stat(current); err != nil {
+		t.Errorf("file retention: current folder removed")
+	}
+	logger.Info("active")
+	logger.Stop()
+
+	// 4. 校验结果.
+	for name, expect := range map[string]string{"2023-01-03.log.gz": "old\n", "2023-01-03.1.log.gz": "c\n"} {
+		f, err := os.Open(filepath.Join(history, name))
+		if err != nil {
+			t.Errorf("file retention: %v", err)
+			continue
+		}
+		if r, err := gzip.NewReader(f); err != nil {
+			t.Errorf("file retention gzip: %v", err)
+		} else if body, _ := io.ReadAll(r); string(body) != expect {
+			t.Errorf("file retention gzip %s: %q", name, body)
+		}
+		_ = f.Close()
+	}
+	if !strings.Contains(readLogDir(t, current), "active") {
+		t.Errorf("file retention: active file missing")
+	}
+}
+
+func TestFile_Handle(t *testing.T) {
+	dir := t.TempDir()
+	c, err := config.NewFromBytes([]byte("log_adapter: file\nlog_adapter_file:\n  milliseconds: 10\n  sync: batch\n  path: " + dir + "\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	// 1. 多批写入.
+	//    每批写入后刷新缓冲区, 未退出前即可读取.
+	logger := log.New(log.Options{Config: c})
+	defer logger.Stop()
+	for i := 0; i < 3; i++ {
+		logger.Info("batch")
+		time.Sleep(time.Millisecond * 50)
+	}
+	if n := strings.Count(readLogDir(t, dir), "batch"); n != 3 {
+		t.Errorf("file handle: %d lines", n)
+	}
+}
+
+func TestFile_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	c, err := config.NewFromBytes([]byte("log_adapter: file\nlog_adapter_file:\n  milliseconds: 10\n  path: " + dir + "\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	var (
+		adapter = log_file.NewWithConfig(c).(*log_file.Manager)
+		ctx     = context.Background()
+		path    = filepath.Join(dir, time.Now().Format("2006-01"), time.Now().Format("2006-01-02")+".log")
+	)
+
+	go func() { _ = adapter.Keeper().Start(ctx) }()
+	defer adapter.Keeper().Stop()
+
+	// 1. 移动文件.
+	//    重开前仍写入已移动的文件.
+	adapter.Send(adapters.NewLine(nil, base.Info, "before"))
+	time.Sleep(time.Millisecond * 50)
+	if err = os.Rename(path, path+".old"); err != nil {
+		t.Fatalf("file reopen: %v", err)
+	}
+
+	// 2. 重开文件.
+	adapter.Reopen()
+	adapter.Send(adapters.NewLine(nil, base.Info, "after"))
+	time.Sleep(time.Millisecond * 50)
+
+	if body, _ := os.ReadFile(path); !strings.Contains(string(body), "after") || strings.Contains(string(body), "before") {
+		t.Errorf("file reopen: %q", body)
+	}
+	if body, _ := os.ReadFile(path + ".old"); !strings.Contains(string(body), "before") {
+		t.Errorf("file reopen old: %q", body)
+	}
+}
+
+func TestFile_SyncInvalid(t *testing.T) {
+	if _, err := config.NewFromBytes([]byte("log_adapter_file:\n  sync: always\n")); err == nil || !strings.Contains(err.Error(), "log_adapter_file.sync") {
+		t.Errorf("file sync: %v", err)
+	}
+}
+
+func TestFile_Mode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file mode is not supported on windows")
+	}
+
+	dir := t.TempDir()
+	c, err := config.NewFromBytes([]byte("log_adapter: file\nlog_adapter_file:\n  file_mode: \"0600\"\n  dir_mode: 0700\n  path: " + dir + "\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	logger := log.New(log.Options{Config: c})
+	logger.Info("mode")
+	logger.Stop()
+
+	// 1. 目录权限.
+	folder := filepath.Join(dir, time.Now().Format("2006-01"))
+	if info, se := os.Stat(folder); se != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("file dir mode: %v, %v", info.Mode(), se)
+	}
+
+	// 2. 文件权限.
+	if info, se := os.Stat(filepath.Join(folder, time.Now().Format("2006-01-02")+".log")); se != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("file mode: %v, %v", info.Mode(), se)
+	}
+}
+
+func TestFile_ModeInvalid(t *testing.T) {
+	if _, err := config.NewFromBytes([]byte("log_adapter_file:\n  file_mode: rw-r--r--\n")); err == nil || !strings.Contains(err.Error(), `log_adapter_file.file_mode: invalid mode "rw-r--r--"`) {
+		t.Errorf("file mode: %v", err)
+	}
+}
+
+func TestFile_Symlink(t *testing.T) {
+	dir := t.TempDir()
+	c, err := config.NewFromBytes([]byte("log_adapter: file\nlog_adapter_file:\n  symlink: current.log\n  path: " + dir + "\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	logger := log.New(log.Options{Config: c})
+	logger.Info("linked")
+	logger.Stop()
+
+	// 1. 相对路径.
+	link := filepath.Join(dir, "current.log")
+	target, err := os.Readlink(link)
+	if err != nil || target != filepath.Join(time.Now().Format("2006-01"), time.Now().Format("2006-01-02")+".log") {
+		t.Fatalf("file symlink: %q, %v", target, err)
+	}
+
+	// 2. 读取链接.
+	if body, _ := os.ReadFile(link); !strings.Contains(string(body), "linked") {
+		t.Errorf("file symlink: %q", body)
+	}
+}
+
+func TestFile_ErrorFile(t *testing.T) {
+	dir := t.TempDir()
+	c, err := config.NewFromBytes([]byte("log_adapter: file\nlog_adapter_file:\n  max_size: 1\n  error_name: 2006-01-02.error\n  path: " + dir + "\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	// 1. 写入 1.6MB 普通日志及少量告警.
+	logger := log.New(log.Options{Config: c})
+	text := strings.Repeat("x", 8*1024)
+	for i := 0; i < 200; i++ {
+		logger.Info(text)
+	}
+	logger.Warn("disk low")
+	logger.Error("disk full")
+	logger.Stop()
+
+	var (
+		folder = filepath.Join(dir, time.Now().Format("2006-01"))
+		prefix = time.Now().Format("2006-01-02")
+	)
+
+	// 2. 错误日志.
+	//    仅含 WARN 及以上级别, 未达到切割大小.
+	body, err := os.ReadFile(filepath.Join(folder, prefix+".error.log"))
+	if err != nil {
+		t.Fatalf("file error: %v", err)
+	}
+	if s := string(body); strings.Count(s, "\n") != 2 || !strings.Contains(s, "disk low") || !strings.Contains(s, "disk full") {
+		t.Errorf("file error: %q", s)
+	}
+	if _, err = os.Stat(filepath.Join(folder, prefix+".error.1.log")); err == nil {
+		t.Errorf("file error: unexpected rotation")
+	}
+
+	// 3. 主文件.
+	//    含全部日志, 独立切割.
+	if _, err = os.Stat(filepath.Join(folder, prefix+".1.log")); err != nil {
+		t.Errorf("file rotate: %v", err)
+	}
+	if body, _ = os.ReadFile(filepath.Join(folder, prefix+".log")); !strings.Contains(string(body), "disk full") {
+		t.Errorf("file error: missing line in main file")
+	}
+}
@@ -16,95 +16,332 @@
 package tests
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/adapters/log_kafka"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
 
-func TestKafka_Connect(t *testing.T) {
-	var (
-		cfg    = kafkaConfig()
-		client sarama.Client
-		err    error
-		topic  string
-		topics []string
-	)
+// 启动适配器.
+// 使用内存生产者, 返回停止函数; 停止时等待数据桶清空.
+func startKafka(t *testing.T, yaml string, producer log_kafka.Producer) (*log_kafka.Manager, func()) {
+	c, err := config.NewFromBytes([]byte("log_adapter: kafka\nlog_adapter_kafka:\n  milliseconds: 10000\n" + yaml))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	adapter := log_kafka.NewWithConfig(c).(*log_kafka.Manager)
+	adapter.SetProducer(producer)
+	return adapter, runKeeper(adapter.Keeper())
+}
 
-	// Build client.
-	if client, err = sarama.NewClient(kafkaAddr(), cfg); err != nil {
-		t.Errorf("[kafka client]: %v", err)
-		return
+// 启动 Keeper.
+// 等待启动完成, 返回停止函数; 停止时等待退出.
+func runKeeper(keeper base.Keeper) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = keeper.Start(ctx)
+	}()
+	for i := 0; i < 100 && keeper.Stopped(); i++ {
+		time.Sleep(time.Millisecond)
 	}
+	return func() { cancel(); <-done }
+}
 
-	// Broker list.
-	for _, broker := range client.Brokers() {
-		t.Logf("%v", broker)
+// 发送日志.
+func sendKafka(adapter *log_kafka.Manager, from, to int) {
+	for i := from; i < to; i++ {
+		adapter.Send(adapters.NewLine(context.Background(), base.Info, "msg-%d", i))
 	}
+}
 
-	// Topic list.
-	if topics, err = client.Topics(); err != nil {
-		t.Errorf("%v", err)
-		return
+// 校验消息顺序.
+func expectKafka(t *testing.T, msg []*sarama.ProducerMessage, n int) {
+	if len(msg) != n {
+		t.Fatalf("kafka messages: expect %d, got %d", n, len(msg))
+	}
+	for i, m := range msg {
+		value, _ := m.Value.Encode()
+		if !bytes.Contains(value, []byte(fmt.Sprintf(`"msg-%d"`, i))) {
+			t.Errorf("kafka message %d: %s", i, value)
+		}
 	}
+}
+
+func TestKafka_Batch(t *testing.T) {
+	producer := log_kafka.NewMemoryProducer()
+	adapter, stop := startKafka(t, "  batch: 3\n", producer)
 
-	// Topic range.
-	t.Logf("[kafka topics]: %d", len(topics))
-	for _, topic = range topics {
-		t.Logf("[kafka  topic]: %v", topic)
+	sendKafka(adapter, 0, 7)
+	stop()
+
+	for _, batch := range producer.Batches() {
+		if len(batch) == 0 || len(batch) > 3 {
+			t.Errorf("kafka batch size: %d", len(batch))
+		}
+	}
+	expectKafka(t, producer.Messages(), 7)
+	if producer.Closed() {
+		t.Errorf("kafka producer: closed by adapter")
 	}
 }
 
-func TestKafka_SyncProducer(t *testing.T) {
+func TestKafka_Retry(t *testing.T) {
+	producer := log_kafka.NewMemoryProducer()
+	producer.FailNext(1, errors.New("broker down"))
+	adapter, stop := startKafka(t, "  batch: 3\n  topic_template: logs-{level}\n  spool_path: "+t.TempDir()+"\n  spool_milliseconds: 10\n", producer)
+	defer stop()
+
+	// 1. 首批失败.
+	//    写入暂存, 恢复后按顺序重放.
+	sendKafka(adapter, 0, 3)
+	for i := 0; i < 100 && len(producer.Messages()) < 3; i++ {
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	// 2. 后续批次.
+	//    排在重放之后.
+	sendKafka(adapter, 3, 6)
+	for i := 0; i < 100 && len(producer.Messages()) < 6; i++ {
+		time.Sleep(time.Millisecond * 10)
+	}
+	expectKafka(t, producer.Messages(), 6)
+
+	// 3. 重放保留主题.
+	for _, m := range producer.Messages() {
+		if m.Topic != "logs-info" {
+			t.Errorf("kafka replay topic: %s", m.Topic)
+		}
+	}
+}
+
+func TestKafka_ErrorHandler(t *testing.T) {
 	var (
-		cfg      = kafkaConfig()
-		client   sarama.Client
-		err      error
-		producer sarama.SyncProducer
+		failed   []string
+		mu       sync.Mutex
+		producer = log_kafka.NewMemoryProducer()
 	)
 
-	cfg.Net.DialTimeout = time.Second
-	cfg.Net.ReadTimeout = time.Second
-	cfg.Net.WriteTimeout = time.Second
+	producer.FailNext(1, errors.New("broker down"))
+	adapter, stop := startKafka(t, "  batch: 100\n", producer)
+	adapter.SetErrorHandler(func(msg *sarama.ProducerMessage, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		value, _ := msg.Value.Encode()
+		failed = append(failed, fmt.Sprintf("%v: %d", err, len(value)))
+	})
+
+	// 未启用暂存.
+	// 失败的消息逐条回调, 后续批次正常发送.
+	sendKafka(adapter, 0, 2)
+	stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(failed) != 2 {
+		t.Fatalf("kafka error handler: %v", failed)
+	}
+	for _, s := range failed {
+		if !bytes.HasPrefix([]byte(s), []byte("broker down: ")) {
+			t.Errorf("kafka error handler: %s", s)
+		}
+	}
+	if n := len(producer.Messages()); n != 0 {
+		t.Errorf("kafka messages: expect 0, got %d", n)
+	}
+}
+
+func TestKafka_AsyncProducer(t *testing.T) {
+	var (
+		c      = sarama.NewConfig()
+		failed []*sarama.ProducerMessage
+		mock   = mocks.NewAsyncProducer(t, c)
+	)
+
+	mock.ExpectInputAndSucceed()
+	mock.ExpectInputAndFail(errors.New("broker down"))
+	mock.ExpectInputAndSucceed()
+
+	producer := log_kafka.NewAsyncProducer(mock, func(msg *sarama.ProducerMessage, err error) {
+		failed = append(failed, msg)
+	})
 
-	cfg.Producer.Return.Errors = true
-	cfg.Producer.Return.Successes = true
+	msg := []*sarama.ProducerMessage{
+		{Topic: "logs", Value: sarama.StringEncoder("a")},
+		{Topic: "logs", Value: sarama.StringEncoder("b")},
+		{Topic: "logs", Value: sarama.StringEncoder("c")},
+	}
+	if err := producer.Send(msg); err != nil {
+		t.Fatalf("async send: %v", err)
+	}
 
-	if client, err = sarama.NewClient(kafkaAddr(), cfg); err != nil {
-		t.Errorf("%v", err)
-		return
+	// 关闭后回调已完成.
+	_ = producer.Close()
+	if len(failed) != 1 || failed[0] != msg[1] {
+		t.Errorf("async failed: %v", failed)
 	}
 
-	t.Logf("client: %+v", client.Brokers()[0])
+	// 关闭后拒绝发送.
+	if err := producer.Send(msg[:1]); err != sarama.ErrShuttingDown {
+		t.Errorf("async send after close: %v", err)
+	}
+}
 
-	if producer, err = sarama.NewSyncProducerFromClient(client); err != nil {
-		t.Errorf("%v", err)
-		return
+func TestKafka_Closed(t *testing.T) {
+	c, err := config.NewFromBytes([]byte("log_adapter: kafka\nlog_adapter_kafka:\n  batch: 1\n  host: [127.0.0.1:1]\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
 	}
 
 	var (
-		partition int32
-		offset    int64
+		adapter = log_kafka.NewWithConfig(c).(*log_kafka.Manager)
+		failed  = make(chan error, 1)
 	)
+	adapter.SetErrorHandler(func(msg *sarama.ProducerMessage, err error) {
+		select {
+		case failed <- err:
+		default:
+		}
+	})
 
-	if partition, offset, err = producer.SendMessage(&sarama.ProducerMessage{
-		Topic: "go-wares-log",
-		Value: sarama.StringEncoder(`{"service_addr": ["172.20.0.10", "192.168.0.130"]}`),
-	}); err != nil {
-		t.Errorf("%v", err)
-		return
+	runKeeper(adapter.Keeper())()
+
+	// 退出后不再创建生产者.
+	sendKafka(adapter, 0, 1)
+	select {
+	case err = <-failed:
+		if !strings.Contains(err.Error(), "producer closed") {
+			t.Errorf("kafka closed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("kafka closed: no error reported")
 	}
+}
+
+// 延迟失败的异步生产者.
+// 失败消息在 AsyncClose 之后才进入 Errors 通道, 即在适配器关闭生产者期间回调.
+type kafkaLateProducer struct {
+	*mocks.AsyncProducer
+	errors chan *sarama.ProducerError
+}
 
-	t.Logf("producer: partition=%d, offset=%d", partition, offset)
+func (o *kafkaLateProducer) AsyncClose() {
+	o.AsyncProducer.AsyncClose()
+	go func() {
+		defer close(o.errors)
+		for e := range o.AsyncProducer.Errors() {
+			o.errors <- e
+		}
+	}()
 }
 
-func kafkaAddr() []string {
-	return []string{
-		"192.168.0.130:9092",
-		// "127.0.0.1:9092",
+func (o *kafkaLateProducer) Errors() <-chan *sarama.ProducerError { return o.errors }
+
+func TestKafka_AsyncClose(t *testing.T) {
+	c, err := config.NewFromBytes([]byte("log_adapter: kafka\nlog_adapter_kafka:\n  batch: 1\n  mode: async\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	var (
+		adapter = log_kafka.NewWithConfig(c).(*log_kafka.Manager)
+		failed  = make(chan error, 1)
+	)
+	adapter.SetErrorHandler(func(msg *sarama.ProducerMessage, err error) { failed <- err })
+	adapter.SetProducerFactory(func(k *config.LogAdapterKafka, onError log_kafka.ErrorHandler) (log_kafka.Producer, error) {
+		pc := sarama.NewConfig()
+		pc.Producer.Return.Errors = true
+		mock := mocks.NewAsyncProducer(t, pc)
+		mock.ExpectInputAndFail(errors.New("broker down"))
+		return log_kafka.NewAsyncProducer(&kafkaLateProducer{AsyncProducer: mock, errors: make(chan *sarama.ProducerError)}, onError), nil
+	})
+
+	stop := runKeeper(adapter.Keeper())
+
+	// 关闭时回调失败消息, 不可死锁.
+	sendKafka(adapter, 0, 1)
+	done := make(chan struct{})
+	go func() { defer close(done); stop() }()
+	select {
+	case <-done:
+	case <-time.After(time.Second * 3):
+		t.Fatalf("kafka async close: deadlock")
+	}
+	select {
+	case err = <-failed:
+		if err.Error() != "broker down" {
+			t.Errorf("kafka async close: %v", err)
+		}
+	default:
+		t.Errorf("kafka async close: no error reported")
 	}
 }
 
-func kafkaConfig() (cfg *sarama.Config) {
-	cfg = sarama.NewConfig()
-	return
+func TestKafka_Restart(t *testing.T) {
+	c, err := config.NewFromBytes([]byte("log_adapter: kafka\nlog_adapter_kafka:\n  batch: 1\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	var (
+		adapter   = log_kafka.NewWithConfig(c).(*log_kafka.Manager)
+		failed    = make(chan error, 10)
+		mu        sync.Mutex
+		producers []*log_kafka.MemoryProducer
+	)
+	adapter.SetErrorHandler(func(msg *sarama.ProducerMessage, err error) { failed <- err })
+	adapter.SetProducerFactory(func(k *config.LogAdapterKafka, onError log_kafka.ErrorHandler) (log_kafka.Producer, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		p := log_kafka.NewMemoryProducer()
+		producers = append(producers, p)
+		return p, nil
+	})
+
+	// 停止后重新启动, 重建生产者.
+	for i := 0; i < 2; i++ {
+		stop := runKeeper(adapter.Keeper())
+		sendKafka(adapter, i, i+1)
+		stop()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(producers) != 2 || len(producers[1].Messages()) != 1 || !producers[0].Closed() || !producers[1].Closed() {
+		t.Errorf("kafka restart: %d producers", len(producers))
+	}
+	select {
+	case err = <-failed:
+		t.Errorf("kafka restart: %v", err)
+	default:
+	}
+}
+
+func TestKafka_ModeInvalid(t *testing.T) {
+	if _, err := config.NewFromBytes([]byte("log_adapter_kafka:\n  mode: fire\n")); err == nil {
+		t.Errorf("kafka mode: expect error")
+	}
+	c, err := config.NewFromBytes([]byte("log_adapter_kafka:\n  mode: async\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	pc, err := log_kafka.NewProducerConfig(c.LogAdapterKafka)
+	if err != nil {
+		t.Fatalf("producer config: %v", err)
+	}
+	if pc.Producer.Return.Successes || !pc.Producer.Return.Errors {
+		t.Errorf("async producer config: successes=%v, errors=%v", pc.Producer.Return.Successes, pc.Producer.Return.Errors)
+	}
 }
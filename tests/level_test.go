@@ -0,0 +1,94 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-22
+
+package tests
+
+import (
+	"github.com/go-wares/log"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLevel_Set(t *testing.T) {
+	origin := log.GetLevel()
+	defer func() { _ = log.SetLevel(base.Level(origin.String())) }()
+
+	if err := log.SetLevel("error"); err != nil {
+		t.Fatalf("set level: %v", err)
+	}
+	if config.Config.WarnOn() || !config.Config.ErrorOn() {
+		t.Errorf("set level: warn=%v, error=%v", config.Config.WarnOn(), config.Config.ErrorOn())
+	}
+
+	if err := log.SetLevel("verbose"); err == nil {
+		t.Errorf("set level: unknown level accepted")
+	}
+	if log.GetLevel() != base.Error {
+		t.Errorf("set level: unknown level changed level to %v", log.GetLevel())
+	}
+}
+
+func TestLevel_SetFor(t *testing.T) {
+	origin := log.GetLevel()
+	defer func() { _ = log.SetLevel(base.Level(origin.String())) }()
+
+	_ = log.SetLevel("WARN")
+
+	// 重复临时修改时, 仍还原为首次修改前的级别.
+	_ = log.SetLevelFor("DEBUG", time.Millisecond*50)
+	_ = log.SetLevelFor("INFO", time.Millisecond*50)
+	if log.GetLevel() != base.Info {
+		t.Errorf("set level for: expect INFO, got %v", log.GetLevel())
+	}
+
+	time.Sleep(time.Millisecond * 150)
+	if log.GetLevel() != base.Warn {
+		t.Errorf("set level for: expect revert to WARN, got %v", log.GetLevel())
+	}
+
+	// 永久修改后取消定时还原.
+	_ = log.SetLevelFor("DEBUG", time.Millisecond*50)
+	_ = log.SetLevel("ERROR")
+	time.Sleep(time.Millisecond * 150)
+	if log.GetLevel() != base.Error {
+		t.Errorf("set level for: expect ERROR, got %v", log.GetLevel())
+	}
+}
+
+func TestLevel_Concurrent(t *testing.T) {
+	origin := log.GetLevel()
+	defer func() { _ = log.SetLevel(base.Level(origin.String())) }()
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				_ = config.Config.DebugOn()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = log.SetLevelFor("DEBUG", time.Millisecond)
+			}
+		}()
+	}
+	wg.Wait()
+}
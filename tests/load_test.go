@@ -0,0 +1,175 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-24
+
+package tests
+
+import (
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad_Validate(t *testing.T) {
+	origin := config.Config.Path()
+	defer func() { _ = config.Load(origin) }()
+
+	for body, expect := range map[string]string{
+		`level: verbose`:                                     `level: unknown level "VERBOSE"`,
+		`log_adapter: term, syslog`:                          `log_adapter: unknown adapter "syslog"`,
+		`trace_adapter: skywalking`:                          `trace_adapter: unknown adapter "skywalking"`,
+		"log_adapter_file:\n  milliseconds: -1":              `log_adapter_file.milliseconds: must be positive, got -1`,
+		"log_adapter_kafka:\n  level: loud":                  `log_adapter_kafka.level: unknown level "LOUD"`,
+		"log_route:\n  - adapter: kafka\n    level: [ERROR]": `log_route[0].adapter: "kafka" is not enabled in log_adapter`,
+	} {
+		err := config.LoadFromBytes([]byte(body))
+		if err == nil || !strings.Contains(err.Error(), expect) {
+			t.Errorf("validate %q: expect %q, got %v", body, expect, err)
+		}
+	}
+
+	// 校验失败时保留当前配置.
+	if config.Config.GetLevel() != base.Debug {
+		t.Errorf("validate: level changed to %v", config.Config.GetLevel())
+	}
+}
+
+func TestLoad_Env(t *testing.T) {
+	origin := config.Config.Path()
+	defer func() { _ = config.Load(origin) }()
+
+	env := map[string]string{
+		"GO_WARES_LOG_LEVEL":                     "error",
+		"GO_WARES_LOG_ADAPTER":                   "term, kafka",
+		"GO_WARES_LOG_ADAPTER_KAFKA_HOST":        "10.0.0.1:9092, 10.0.0.2:9092",
+		"GO_WARES_LOG_ADAPTER_FILE_MILLISECONDS": "2s",
+		"GO_WARES_LOG_ADAPTER_TERM_COLOR":        "false",
+		"GO_WARES_LOG_ADAPTER_OTLP_HEADERS":      "Authorization=Bearer x",
+	}
+	for k, v := range env {
+		_ = os.Setenv(k, v)
+	}
+	defer func() {
+		for k := range env {
+			_ = os.Unsetenv(k)
+		}
+	}()
+
+	if err := config.LoadFromBytes([]byte(`level: debug`)); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	c := config.Config.Snapshot()
+	if config.Config.GetLevel() != base.Error {
+		t.Errorf("env level: %v", config.Config.GetLevel())
+	}
+	if len(c.LogAdapter) != 2 || !c.LogAdapter.Contains(base.LogKafka) {
+		t.Errorf("env log adapter: %v", c.LogAdapter)
+	}
+	if len(c.LogAdapterKafka.Host) != 2 || c.LogAdapterKafka.Host[1] != "10.0.0.2:9092" {
+		t.Errorf("env kafka host: %v", c.LogAdapterKafka.Host)
+	}
+	if c.LogAdapterFile.Milliseconds != 2000 || *c.LogAdapterTerm.Color {
+		t.Errorf("env file milliseconds: %d, term color: %v", c.LogAdapterFile.Milliseconds, *c.LogAdapterTerm.Color)
+	}
+	if c.LogAdapterOtlp.Headers["Authorization"] != "Bearer x" {
+		t.Errorf("env otlp headers: %v", c.LogAdapterOtlp.Headers)
+	}
+
+	// 无效时长.
+	_ = os.Setenv("GO_WARES_LOG_ADAPTER_FILE_MILLISECONDS", "soon")
+	if err := config.LoadFromBytes(nil); err == nil || !strings.Contains(err.Error(), "GO_WARES_LOG_ADAPTER_FILE_MILLISECONDS") {
+		t.Errorf("env malformed duration: %v", err)
+	}
+}
+
+func TestLoad_EnvNested(t *testing.T) {
+	origin := config.Config.Path()
+	defer func() { _ = config.Load(origin) }()
+
+	// 值类型子配置、内嵌配置及布尔值.
+	env := map[string]string{
+		"GO_WARES_LOG_ADAPTER_KAFKA_SASL_MECHANISM":  "PLAIN",
+		"GO_WARES_LOG_ADAPTER_KAFKA_SASL_USERNAME":   "user",
+		"GO_WARES_LOG_ADAPTER_KAFKA_SASL_PASSWORD":   "secret",
+		"GO_WARES_LOG_ADAPTER_KAFKA_TLS_ENABLE":      "true",
+		"GO_WARES_LOG_ADAPTER_KAFKA_SCHEMA_FLATTEN":  "true",
+		"GO_WARES_LOG_ADAPTER_KAFKA_CAPACITY":        "50",
+		"GO_WARES_LOG_ADAPTER_KAFKA_SPOOL_PATH":      "/tmp/spool",
+		"GO_WARES_LOG_TRACE_ADAPTER_JAEGER_OVERFLOW": "drop_oldest",
+		"GO_WARES_LOG_ADAPTER_FILE_OVERFLOW_LEVEL":   "ERROR",
+		"GO_WARES_LOG_ADAPTER_FILE_REOPEN_ON_SIGHUP": "true",
+	}
+	for k, v := range env {
+		_ = os.Setenv(k, v)
+	}
+	defer func() {
+		for k := range env {
+			_ = os.Unsetenv(k)
+		}
+	}()
+
+	if err := config.LoadFromBytes(nil); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	c := config.Config.Snapshot()
+	if k := c.LogAdapterKafka; k.Sasl.Mechanism != "PLAIN" || k.Sasl.Username != "user" || k.Sasl.Password != "secret" || !k.Tls.Enable || !k.Schema.Flatten {
+		t.Errorf("env kafka nested: sasl=%+v, tls=%v, flatten=%v", k.Sasl, k.Tls.Enable, k.Schema.Flatten)
+	}
+	if k := c.LogAdapterKafka; k.Capacity != 50 || k.SpoolPath != "/tmp/spool" {
+		t.Errorf("env kafka inline: capacity=%d, spool=%s", k.Capacity, k.SpoolPath)
+	}
+	if c.TraceAdapterJaeger.Overflow != config.OverflowDropOldest || c.LogAdapterFile.OverflowLevel != "ERROR" || !*c.LogAdapterFile.ReopenOnSighup {
+		t.Errorf("env inline: jaeger=%s, file=%s", c.TraceAdapterJaeger.Overflow, c.LogAdapterFile.OverflowLevel)
+	}
+
+	// 无效布尔值.
+	_ = os.Setenv("GO_WARES_LOG_ADAPTER_KAFKA_TLS_ENABLE", "maybe")
+	if err := config.LoadFromBytes(nil); err == nil || !strings.Contains(err.Error(), "GO_WARES_LOG_ADAPTER_KAFKA_TLS_ENABLE") {
+		t.Errorf("env malformed boolean: %v", err)
+	}
+}
+
+func TestLoad_Service(t *testing.T) {
+	origin := config.Config.Path()
+	defer func() { _ = config.Load(origin) }()
+
+	// 应用名称及版本号.
+	// 加载时生效, 热加载时保留.
+	if err := config.LoadFromBytes([]byte("name: billing\nversion: \"3.1\"\n")); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if c := config.Config.Snapshot(); c.Name != "billing" || c.Version != "3.1" || c.TraceAdapterJaeger.Topic != "billing" {
+		t.Errorf("load service: name=%s, version=%s, jaeger topic=%s", c.Name, c.Version, c.TraceAdapterJaeger.Topic)
+	}
+	if res := adapters.GetResource(); res["service.name"] != "billing" || res["service.version"] != "3.1" {
+		t.Errorf("load resource: %v", res)
+	}
+
+	path := filepath.Join(t.TempDir(), "log.yaml")
+	if err := os.WriteFile(path, []byte("name: ignored\n"), 0644); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if _, err := config.Config.ReloadFile(path); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if c := config.Config.Snapshot(); c.Name != "billing" || c.TraceAdapterJaeger.Topic != "billing" {
+		t.Errorf("reload service: name=%s, jaeger topic=%s", c.Name, c.TraceAdapterJaeger.Topic)
+	}
+}
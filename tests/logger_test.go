@@ -0,0 +1,95 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-25
+
+package tests
+
+import (
+	"fmt"
+	"github.com/go-wares/log"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogger_New(t *testing.T) {
+	var (
+		dirs    = []string{t.TempDir(), t.TempDir()}
+		loggers = make([]*log.Logger, 0)
+	)
+
+	// 1. 独立实例.
+	//    各自写入不同目录, 级别互不影响.
+	for i, dir := range dirs {
+		c, err := config.NewFromBytes([]byte(fmt.Sprintf("level: %s\nlog_adapter: file\nlog_adapter_file:\n  path: %s\n", []string{"debug", "error"}[i], dir)))
+		if err != nil {
+			t.Fatalf("config: %v", err)
+		}
+		loggers = append(loggers, log.New(log.Options{Config: c}))
+	}
+
+	loggers[0].Debugf("library %s", "debug")
+	loggers[1].Debugf("application %s", "debug")
+	loggers[1].Errorf("application %s", "error")
+
+	span := loggers[0].NewSpan("library-span")
+	span.Info("span info")
+	span.End()
+
+	for _, logger := range loggers {
+		logger.Stop()
+	}
+
+	// 2. 校验输出.
+	library, application := readLogDir(t, dirs[0]), readLogDir(t, dirs[1])
+	for _, s := range []string{"library debug", "span info"} {
+		if !strings.Contains(library, s) {
+			t.Errorf("library: missing %q in %q", s, library)
+		}
+	}
+	if !strings.Contains(application, "application error") {
+		t.Errorf("application: missing error in %q", application)
+	}
+	for _, s := range []string{"application debug", "library"} {
+		if strings.Contains(application, s) {
+			t.Errorf("application: unexpected %q in %q", s, application)
+		}
+	}
+
+	// 3. 全局配置不受影响.
+	if config.Config.GetLevel() != base.Debug || loggers[1].GetLevel() != base.Error {
+		t.Errorf("level: global %v, logger %v", config.Config.GetLevel(), loggers[1].GetLevel())
+	}
+}
+
+func readLogDir(t *testing.T, dir string) string {
+	var buf strings.Builder
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			body, re := os.ReadFile(path)
+			if re != nil {
+				return re
+			}
+			buf.Write(body)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("read %s: %v", dir, err)
+	}
+	return buf.String()
+}
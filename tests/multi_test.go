@@ -0,0 +1,103 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-20
+
+package tests
+
+import (
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/adapters/log_multi"
+	"github.com/go-wares/log/base"
+	"gopkg.in/yaml.v3"
+	"sync"
+	"testing"
+)
+
+// 测试适配器.
+type memoryAdapter struct {
+	sync.Mutex
+	keeper base.Keeper
+	lines  []*adapters.Line
+	panic  bool
+	texts  []string
+}
+
+func newMemoryAdapter(name string) *memoryAdapter {
+	return &memoryAdapter{keeper: base.NewKeeper(name)}
+}
+
+func (o *memoryAdapter) Keeper() base.Keeper                  { return o.keeper }
+func (o *memoryAdapter) SetFormatter(_ adapters.LogFormatter) {}
+
+func (o *memoryAdapter) Send(line *adapters.Line) {
+	if o.panic {
+		panic("memory adapter")
+	}
+
+	o.Lock()
+	defer o.Unlock()
+	o.lines = append(o.lines, line)
+	o.texts = append(o.texts, line.Text)
+}
+
+func (o *memoryAdapter) release() {
+	o.Lock()
+	defer o.Unlock()
+	for _, line := range o.lines {
+		line.Release()
+	}
+	o.lines = nil
+}
+
+func TestMulti_Send(t *testing.T) {
+	var (
+		a1    = newMemoryAdapter("memory-1")
+		a2    = newMemoryAdapter("memory-2")
+		a3    = newMemoryAdapter("memory-3")
+		multi = log_multi.New().Add("m1", a1).Add("m2", a2).Add("m3", a3)
+	)
+
+	a3.panic = true
+	multi.Send(adapters.NewLine(nil, base.Info, "multi"))
+
+	if len(a1.texts) != 1 || len(a2.texts) != 1 {
+		t.Fatalf("multi send: a1=%d, a2=%d", len(a1.texts), len(a2.texts))
+	}
+
+	// 第1个适配器释放后, 日志仍由第2个适配器持有.
+	line := a2.lines[0]
+	a1.release()
+	if line.Text != "multi" {
+		t.Errorf("multi released too early: %q", line.Text)
+	}
+	a2.release()
+}
+
+func TestMulti_Config(t *testing.T) {
+	for body, expect := range map[string]int{
+		`log_adapter: term`:                 1,
+		`log_adapter: term, file`:           2,
+		`log_adapter: [term, file, term]`:   2,
+		"log_adapter:\n  - term\n  - kafka": 2,
+	} {
+		v := &struct {
+			LogAdapter base.LogAdapters `yaml:"log_adapter"`
+		}{}
+		if err := yaml.Unmarshal([]byte(body), v); err != nil {
+			t.Errorf("%s: %v", body, err)
+		} else if len(v.LogAdapter) != expect {
+			t.Errorf("%s: expect %d, got %v", body, expect, v.LogAdapter)
+		}
+	}
+}
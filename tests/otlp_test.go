@@ -0,0 +1,149 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-19
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/go-wares/log"
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/adapters/log_otlp"
+	"github.com/go-wares/log/adapters/otlp"
+	"github.com/go-wares/log/adapters/trace_otlp"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+	"github.com/go-wares/log/trace"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type otlpRequest struct {
+	contentType string
+	body        []byte
+}
+
+func otlpServer() (*httptest.Server, chan otlpRequest) {
+	ch := make(chan otlpRequest, 1)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ch <- otlpRequest{contentType: r.Header.Get("Content-Type"), body: body}
+	})), ch
+}
+
+func TestOtlp_TraceJson(t *testing.T) {
+	server, ch := otlpServer()
+	defer server.Close()
+
+	c, err := config.NewFromBytes([]byte("trace_adapter_otlp:\n  endpoint: " + server.URL + "\n  encoding: json\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	adapter := trace_otlp.NewWithConfig(c)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = adapter.Keeper().Start(ctx) }()
+
+	sp := trace.NewSpan("otlp span")
+	sp.Attr().Set("uid", 1)
+	sp.Info("otlp event")
+	adapter.Send(sp)
+
+	select {
+	case req := <-ch:
+		if req.contentType != "application/json" {
+			t.Errorf("otlp content type: %s", req.contentType)
+		}
+
+		data := &struct {
+			ResourceSpans []struct {
+				Resource struct {
+					Attributes []map[string]interface{} `json:"attributes"`
+				} `json:"resource"`
+				ScopeSpans []struct {
+					Spans []struct {
+						Name   string                   `json:"name"`
+						Events []map[string]interface{} `json:"events"`
+					} `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}{}
+		if err := json.Unmarshal(req.body, data); err != nil {
+			t.Fatalf("otlp body: %v", err)
+		}
+
+		spans := data.ResourceSpans[0].ScopeSpans[0].Spans
+		if len(spans) != 1 || spans[0].Name != "otlp span" || len(spans[0].Events) != 1 {
+			t.Errorf("otlp spans: %s", req.body)
+		}
+	case <-time.After(time.Second * 3):
+		t.Errorf("otlp: no spans received")
+	}
+}
+
+func TestOtlp_LogProtobuf(t *testing.T) {
+	server, ch := otlpServer()
+	defer server.Close()
+
+	c, err := config.NewFromBytes([]byte("log_adapter_otlp:\n  endpoint: " + server.URL + "\n  encoding: protobuf\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	adapter := log_otlp.NewWithConfig(c)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = adapter.Keeper().Start(ctx) }()
+
+	line := adapters.NewLine(log.Context(), base.Warn, "otlp log %d", 1)
+	line.Attr = adapters.Attr{"uid": 1}
+	adapter.Send(line)
+
+	select {
+	case req := <-ch:
+		if req.contentType != "application/x-protobuf" {
+			t.Errorf("otlp content type: %s", req.contentType)
+		}
+		if len(req.body) == 0 || req.body[0] != 0x0a || !bytes.Contains(req.body, []byte("otlp log 1")) {
+			t.Errorf("otlp body: %x", req.body)
+		}
+	case <-time.After(time.Second * 3):
+		t.Errorf("otlp: no logs received")
+	}
+}
+
+func TestOtlp_Protobuf(t *testing.T) {
+	line := adapters.NewLine(nil, base.Info, "hi")
+	defer line.Release()
+
+	// LogRecord{time_unix_nano=1, severity_number=9, severity_text="INFO", body="hi"}
+	line.Time = time.Unix(0, 1)
+	body := otlp.NewLogsData(otlp.NewLogRecord(line, line.Text)).Protobuf()
+	record := []byte{
+		0x09, 1, 0, 0, 0, 0, 0, 0, 0,
+		0x10, 9,
+		0x1a, 4, 'I', 'N', 'F', 'O',
+		0x2a, 4, 0x0a, 2, 'h', 'i',
+		0x59, 1, 0, 0, 0, 0, 0, 0, 0,
+	}
+	if !bytes.Contains(body, record) {
+		t.Errorf("otlp protobuf: %x", body)
+	}
+}
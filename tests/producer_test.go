@@ -0,0 +1,331 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-06-05
+
+package tests
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/pem"
+	"github.com/Shopify/sarama"
+	"github.com/go-wares/log"
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/adapters/log_kafka"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 生产者配置.
+func newProducerConfig(t *testing.T, yaml string) (*sarama.Config, error) {
+	c, err := config.NewFromBytes([]byte("log_adapter_kafka:\n" + yaml))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	return log_kafka.NewProducerConfig(c.LogAdapterKafka)
+}
+
+func TestProducer_Config(t *testing.T) {
+	c, err := newProducerConfig(t, "  acks: all\n  compression: zstd\n  version: 2.8.0\n  client_id: order-api\n")
+	if err != nil {
+		t.Fatalf("producer config: %v", err)
+	}
+
+	if c.Producer.RequiredAcks != sarama.WaitForAll || c.Producer.Compression != sarama.CompressionZSTD || c.ClientID != "order-api" || c.Version != sarama.V2_8_0_0 {
+		t.Errorf("producer config: acks %v, compression %v, client %q, version %v", c.Producer.RequiredAcks, c.Producer.Compression, c.ClientID, c.Version)
+	}
+	if c.Net.TLS.Enable || c.Net.SASL.Enable {
+		t.Errorf("producer config: tls %v, sasl %v", c.Net.TLS.Enable, c.Net.SASL.Enable)
+	}
+}
+
+func TestProducer_ConfigInvalid(t *testing.T) {
+	for yaml, expect := range map[string]string{
+		"  acks: one\n":                                     `log_adapter_kafka.acks: unknown mode "one"`,
+		"  compression: brotli\n":                           `log_adapter_kafka.compression: unknown codec "brotli"`,
+		"  compression: zstd\n":                             `log_adapter_kafka.compression: zstd requires version 2.1.0`,
+		"  version: 2.8\n":                                  `log_adapter_kafka.version: malformed version "2.8"`,
+		"  client_id: order api\n":                          `log_adapter_kafka.client_id: invalid id "order api"`,
+		"  tls:\n    enable: true\n    cert_file: a.pem\n":  `log_adapter_kafka.tls: cert_file and key_file must be set together`,
+		"  tls:\n    enable: true\n    ca_file: none.pem\n": `log_adapter_kafka.tls.ca_file:`,
+		"  sasl:\n    mechanism: GSSAPI\n":                  `log_adapter_kafka.sasl.mechanism: unknown mechanism "GSSAPI"`,
+		"  sasl:\n    mechanism: plain\n":                   `log_adapter_kafka.sasl.username: must not be empty`,
+	} {
+		if _, err := config.NewFromBytes([]byte("log_adapter_kafka:\n" + yaml)); err == nil || !strings.Contains(err.Error(), expect) {
+			t.Errorf("producer config %q: %v", yaml, err)
+		}
+	}
+}
+
+func TestProducer_Tls(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	// 1. 根证书.
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(ca, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0644); err != nil {
+		t.Fatalf("write ca: %v", err)
+	}
+
+	c, err := newProducerConfig(t, "  tls:\n    enable: true\n    ca_file: "+ca+"\n    server_name: example.com\n")
+	if err != nil {
+		t.Fatalf("producer tls: %v", err)
+	}
+	if !c.Net.TLS.Enable || c.Net.TLS.Config.RootCAs == nil || c.Net.TLS.Config.ServerName != "example.com" {
+		t.Errorf("producer tls: %+v", c.Net.TLS)
+	}
+}
+
+func TestProducer_SaslPlain(t *testing.T) {
+	c, err := newProducerConfig(t, "  sasl:\n    mechanism: PLAIN\n    username: user\n    password: pencil\n")
+	if err != nil {
+		t.Fatalf("producer sasl: %v", err)
+	}
+	if !c.Net.SASL.Enable || c.Net.SASL.Mechanism != sarama.SASLTypePlaintext || c.Net.SASL.User != "user" || c.Net.SASL.Password != "pencil" {
+		t.Errorf("producer sasl: %+v", c.Net.SASL)
+	}
+}
+
+// RFC 7677 测试向量, 客户端随机数由客户端生成.
+func TestProducer_SaslScram(t *testing.T) {
+	c, err := newProducerConfig(t, "  sasl:\n    mechanism: SCRAM-SHA-256\n    username: user\n    password: pencil\n")
+	if err != nil {
+		t.Fatalf("producer sasl: %v", err)
+	}
+
+	// 密码经 SASLprep 处理, 软连字符及零宽空格映射为空.
+	for _, password := range []string{"pencil", "pen\u00adcil\u200b"} {
+		t.Run(password, func(t *testing.T) {
+			scramVerify(t, c.Net.SASL.SCRAMClientGeneratorFunc(), password)
+		})
+	}
+
+}
+
+// 按 RFC 7677 测试向量校验客户端证明及服务端签名.
+func scramVerify(t *testing.T, client sarama.SCRAMClient, password string) {
+	// 1. 客户端首条消息.
+	if err := client.Begin("user", password, ""); err != nil {
+		t.Fatalf("scram begin: %v", err)
+	}
+	first, _ := client.Step("")
+	if !strings.HasPrefix(first, "n,,n=user,r=") {
+		t.Fatalf("scram first: %q", first)
+	}
+
+	// 2. 客户端证明.
+	var (
+		nonce       = strings.TrimPrefix(first, "n,,n=user,r=") + "%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0"
+		serverFirst = "r=" + nonce + ",s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096"
+	)
+	final, err := client.Step(serverFirst)
+	if err != nil || !strings.HasPrefix(final, "c=biws,r="+nonce+",p=") {
+		t.Fatalf("scram final: %q, %v", final, err)
+	}
+
+	// 3. 服务端校验.
+	//    SaltedPassword 取自 RFC 7677.
+	var (
+		salted, _   = base64.StdEncoding.DecodeString("xKSVEDI6tPlSysH6mUQZOeeOp01r6B3fcJbodRPcYV0=")
+		authMessage = strings.TrimPrefix(first, "n,,") + "," + serverFirst + "," + strings.Split(final, ",p=")[0]
+		mac         = func(key []byte, s string) []byte {
+			m := hmac.New(sha256.New, key)
+			m.Write([]byte(s))
+			return m.Sum(nil)
+		}
+		clientKey = mac(salted, "Client Key")
+		storedKey = sha256.Sum256(clientKey)
+		signature = mac(storedKey[:], authMessage)
+		proof, _  = base64.StdEncoding.DecodeString(strings.Split(final, ",p=")[1])
+	)
+	for i := range proof {
+		proof[i] ^= signature[i]
+	}
+	if !hmac.Equal(proof, clientKey) {
+		t.Fatalf("scram proof mismatch")
+	}
+
+	// 4. 服务端签名.
+	serverSignature := base64.StdEncoding.EncodeToString(mac(mac(salted, "Server Key"), authMessage))
+	if _, err := client.Step("v=" + serverSignature); err != nil || !client.Done() {
+		t.Errorf("scram server final: %v", err)
+	}
+}
+
+func TestProducer_SaslScramInvalid(t *testing.T) {
+	c, err := newProducerConfig(t, "  sasl:\n    mechanism: SCRAM-SHA-256\n    username: user\n    password: pencil\n")
+	if err != nil {
+		t.Fatalf("producer sasl: %v", err)
+	}
+
+	// 1. 禁用字符.
+	for _, password := range []string{"pen\x07cil", "pen\ue000cil", "pen\u202ecil"} {
+		if err = c.Net.SASL.SCRAMClientGeneratorFunc().Begin("user", password, ""); err == nil || !strings.Contains(err.Error(), "prohibited character") {
+			t.Errorf("scram password %q: %v", password, err)
+		}
+	}
+
+	// 2. 迭代次数过少.
+	client := c.Net.SASL.SCRAMClientGeneratorFunc()
+	_ = client.Begin("user", "pencil", "")
+	first, _ := client.Step("")
+	nonce := strings.TrimPrefix(first, "n,,n=user,r=") + "server"
+	if _, err = client.Step("r=" + nonce + ",s=W22ZaJ0SNY7soEsUEjb6gQ==,i=1024"); err == nil || !strings.Contains(err.Error(), "below minimum") {
+		t.Errorf("scram iteration: %v", err)
+	}
+
+	// 3. 服务端签名不符.
+	client = c.Net.SASL.SCRAMClientGeneratorFunc()
+	_ = client.Begin("user", "pencil", "")
+	first, _ = client.Step("")
+	nonce = strings.TrimPrefix(first, "n,,n=user,r=") + "server"
+	_, _ = client.Step("r=" + nonce + ",s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096")
+	if _, err = client.Step("v=AAAA"); err == nil {
+		t.Errorf("scram server final: expect signature mismatch")
+	}
+}
+
+// 构建消息.
+func newKafkaMessage(t *testing.T, yaml string, line *adapters.Line) *sarama.ProducerMessage {
+	c, err := config.NewFromBytes([]byte("name: order\nlog_adapter_kafka:\n" + yaml))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	return log_kafka.NewMessage(c, line, []byte("{}"))
+}
+
+// 消息键.
+func kafkaKey(msg *sarama.ProducerMessage) string {
+	if msg.Key == nil {
+		return ""
+	}
+	b, _ := msg.Key.Encode()
+	return string(b)
+}
+
+func TestProducer_PartitionKey(t *testing.T) {
+	line := adapters.NewLine(log.Context(), base.Info, "paid")
+	line.Attr = adapters.Attr{"user_id": 1}
+	line.Fields = append(line.Fields, log.String("order_id", "A100"))
+	defer line.Release()
+
+	for yaml, expect := range map[string]string{
+		"  partition_key: trace_id\n":                           line.TraceId,
+		"  partition_key: service\n":                            "order",
+		"  partition_key: field\n  partition_field: order_id\n": "A100",
+		"  partition_key: field\n  partition_field: user_id\n":  "1",
+		"  partition_key: field\n  partition_field: missing\n":  line.TraceId,
+		"  partition_key: round_robin\n":                        "",
+	} {
+		if key := kafkaKey(newKafkaMessage(t, yaml, line)); key != expect {
+			t.Errorf("partition key %q: expect %q, got %q", yaml, expect, key)
+		}
+	}
+
+	// 轮询分区.
+	if c, err := newProducerConfig(t, "  partition_key: round_robin\n"); err != nil || c.Producer.Partitioner("logs").RequiresConsistency() {
+		t.Errorf("partition round robin: %v", err)
+	}
+}
+
+func TestProducer_Headers(t *testing.T) {
+	line := adapters.NewLine(log.Context(), base.Warn, "low stock")
+	defer line.Release()
+
+	// 1. 默认开启.
+	headers := make(map[string]string)
+	for _, h := range newKafkaMessage(t, "  topic: logs\n", line).Headers {
+		headers[string(h.Key)] = string(h.Value)
+	}
+	if headers["level"] != "WARN" || headers["service"] != "order" || headers["trace_id"] != line.TraceId || headers["span_id"] != line.SpanId || line.TraceId == "" {
+		t.Errorf("kafka headers: %v", headers)
+	}
+
+	// 2. 关闭.
+	if msg := newKafkaMessage(t, "  headers: false\n", line); len(msg.Headers) != 0 {
+		t.Errorf("kafka headers disabled: %v", msg.Headers)
+	}
+
+	// 3. 版本过低.
+	if _, err := config.NewFromBytes([]byte("log_adapter_kafka:\n  version: 0.10.2.1\n")); err == nil || !strings.Contains(err.Error(), "headers: requires version 0.11.0.0") {
+		t.Errorf("kafka headers version: %v", err)
+	}
+}
+
+func TestProducer_Topic(t *testing.T) {
+	audit := adapters.NewLine(log.Context(), base.Info, "login")
+	audit.Attr = adapters.Attr{"topic": "audit"}
+	defer audit.Release()
+
+	access := adapters.NewLine(log.Context(), base.Error, "GET /")
+	access.Fields = append(access.Fields, log.String("kind", "access"))
+	defer access.Release()
+
+	yaml := "  topic: logs\n  topic_field: topic\n  topic_level:\n    error: logs-error\n  topic_template: logs-{service}-{level}\n"
+	for i, c := range []struct {
+		yaml   string
+		line   *adapters.Line
+		expect string
+	}{
+		// 1. 字段优先.
+		{yaml, audit, "audit"},
+		// 2. 按级别.
+		{yaml, access, "logs-error"},
+		// 3. 按模板.
+		{"  topic: logs\n  topic_template: logs-{service}-{level}\n", audit, "logs-order-info"},
+		{"  topic: logs\n  topic_template: logs-{kind}\n", access, "logs-access"},
+		// 4. 模板字段不存在.
+		{"  topic: logs\n  topic_template: logs-{kind}\n", audit, "logs"},
+		// 5. 不在允许列表.
+		{yaml + "  topic_allow: [logs, logs-*]\n", audit, "logs-order-info"},
+		// 6. 未配置路由.
+		{"  topic: logs\n", audit, "logs"},
+	} {
+		if topic := newKafkaMessage(t, c.yaml, c.line).Topic; topic != c.expect {
+			t.Errorf("kafka topic %d: expect %q, got %q", i, c.expect, topic)
+		}
+	}
+}
+
+func TestProducer_TopicInvalid(t *testing.T) {
+	for yaml, expect := range map[string]string{
+		"  topic: a/b\n": "topic: invalid or not allowed",
+		"  topic: logs\n  topic_allow: [audit]\n":         "topic: invalid or not allowed",
+		"  topic_allow: ['[']\n":                          "topic_allow: malformed pattern",
+		"  topic_level:\n    fatal: 'x y'\n":              "topic_level.FATAL: invalid",
+		"  topic_level:\n    notice: logs\n":              "unknown level",
+		"  topic_template: 'logs-{level'\n":               "topic_template: malformed",
+		"  topic_template: 'logs-{}'\n":                   "topic_template: malformed",
+		"  topic_template: 'logs-}{level}'\n":             "topic_template: malformed",
+		"  topic: logs\n  topic_template: logs-{level}\n": "",
+	} {
+		_, err := config.NewFromBytes([]byte("log_adapter_kafka:\n" + yaml))
+		if expect == "" {
+			if err != nil {
+				t.Errorf("kafka topic %q: %v", yaml, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), expect) {
+			t.Errorf("kafka topic %q: expect %q, got %v", yaml, expect, err)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-23
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-wares/log/adapters/log_multi"
+	"github.com/go-wares/log/adapters/trace_jaeger"
+	"github.com/go-wares/log/adapters/trace_zipkin"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+	"github.com/go-wares/log/managers"
+	"github.com/go-wares/log/trace"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReload_File(t *testing.T) {
+	var (
+		origin = config.Config.Path()
+		path   = filepath.Join(t.TempDir(), "log.yaml")
+	)
+
+	body, err := os.ReadFile(origin)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+
+	// 修改级别、时间格式、适配器.
+	s := strings.NewReplacer(
+		`level: "debug"`, `level: "warn"`,
+		`log_adapter: "kafka"`, `log_adapter: "term, file"`,
+		`trace_adapter: "jaeger"`, `trace_adapter: "zipkin"`,
+		`milliseconds: 350                             # 定时刷盘`, `milliseconds: 500 # 定时刷盘`,
+	).Replace(string(body))
+	if err = os.WriteFile(path, []byte(s), 0644); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+
+	changes := make([]*config.Change, 0)
+	config.Config.OnChange(func(change *config.Change) { changes = append(changes, change) })
+
+	change, err := config.Config.ReloadFile(path)
+	defer func() { _, _ = config.Config.ReloadFile(origin) }()
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+
+	if !change.LogAdapter || !change.TraceAdapter || !change.LogRestart.Contains(base.LogFile) {
+		t.Errorf("reload change: %+v", change)
+	}
+	if len(changes) != 1 || changes[0] != change {
+		t.Errorf("reload: change handler not called")
+	}
+	if c := config.Config.Snapshot(); config.Config.GetLevel() != base.Warn || c.LogAdapterFile.Milliseconds != 500 {
+		t.Errorf("reload: level=%v, milliseconds=%d", config.Config.GetLevel(), c.LogAdapterFile.Milliseconds)
+	}
+
+	// 适配器重建.
+	names := managers.Manager.GetLogAdapter().(*log_multi.Manager).Names()
+	if len(names) != 2 || names[0] != base.LogTerm || names[1] != base.LogFile {
+		t.Errorf("reload log adapters: %v", names)
+	}
+	if _, ok := managers.Manager.GetTraceAdapter().(*trace_zipkin.Manager); !ok {
+		t.Errorf("reload trace adapter: %T", managers.Manager.GetTraceAdapter())
+	}
+}
+
+func TestReload_KeeperStop(t *testing.T) {
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		parent      = base.NewKeeper("reload-parent").Listen(func(ctx context.Context) bool { <-ctx.Done(); return false })
+		child       = base.NewKeeper("reload-child").Listen(func(ctx context.Context) bool { <-ctx.Done(); return false })
+		added       = base.NewKeeper("reload-added").Listen(func(ctx context.Context) bool { <-ctx.Done(); return false })
+	)
+	defer cancel()
+
+	parent.Add(child)
+	go func() { _ = parent.Start(ctx) }()
+	time.Sleep(time.Millisecond * 20)
+
+	// 运行中添加的子 Keeper 立即启动.
+	parent.Add(added)
+	time.Sleep(time.Millisecond * 20)
+	if added.Stopped() {
+		t.Errorf("keeper: child added at runtime not started")
+	}
+
+	// 上级仍在运行时, 子 Keeper 退出后不再重启.
+	parent.Del(child)
+	child.Stop()
+	time.Sleep(time.Millisecond * 20)
+	if !child.Stopped() {
+		t.Errorf("keeper: child restarted after stop")
+	}
+}
+
+func TestReload_TraceAdapter(t *testing.T) {
+	var (
+		received = make(chan struct{}, 1)
+		server   = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			received <- struct{}{}
+		}))
+		path = filepath.Join(t.TempDir(), "log.yaml")
+		yaml = "log_adapter: term\nwatch: false\ntrace_adapter: %s\ntrace_adapter_zipkin:\n  endpoint: " + server.URL + "\n  milliseconds: 10000\n"
+	)
+	defer server.Close()
+
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(yaml, "zipkin")), 0644); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	c, err := config.NewFromFile(path)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	m := managers.New(c)
+	go m.Start()
+	defer m.Stop()
+	for i := 0; i < 100 && m.GetTraceAdapter().Keeper().Stopped(); i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	old := m.GetTraceAdapter()
+	trace.NewTracer(m).NewSpan("reload span").End()
+
+	// 替换后旧适配器已退出, 跨度已上报.
+	if err = os.WriteFile(path, []byte(fmt.Sprintf(yaml, "jaeger")), 0644); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if _, err = c.Reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if !old.Keeper().Stopped() {
+		t.Errorf("reload trace adapter: old adapter not stopped")
+	}
+	if _, ok := m.GetTraceAdapter().(*trace_jaeger.Manager); !ok {
+		t.Errorf("reload trace adapter: %T", m.GetTraceAdapter())
+	}
+	select {
+	case <-received:
+	default:
+		t.Errorf("reload trace adapter: spans not flushed")
+	}
+}
@@ -0,0 +1,119 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-21
+
+package tests
+
+import (
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/adapters/log_multi"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+	"github.com/go-wares/log/trace"
+	"testing"
+)
+
+func TestRoute_Match(t *testing.T) {
+	r := &config.LogRoute{
+		Level: []base.Level{"ERROR", "FATAL"},
+		Field: map[string]string{"order_id": "*", "topic": "order"},
+		Span:  "http.*",
+	}
+
+	for i, c := range []struct {
+		level  base.LogLevel
+		fields map[string]interface{}
+		span   string
+		expect bool
+	}{
+		{base.Error, map[string]interface{}{"order_id": 1, "topic": "order"}, "http.get", true},
+		{base.Info, map[string]interface{}{"order_id": 1, "topic": "order"}, "http.get", false},
+		{base.Error, map[string]interface{}{"topic": "order"}, "http.get", false},
+		{base.Error, map[string]interface{}{"order_id": 1, "topic": "user"}, "http.get", false},
+		{base.Error, map[string]interface{}{"order_id": 1, "topic": "order"}, "db.query", false},
+		{base.Error, map[string]interface{}{"order_id": 1, "topic": "order"}, "", false},
+	} {
+		if got := r.Match(c.level, c.fields, c.span); got != c.expect {
+			t.Errorf("route case %d: expect %v, got %v", i, c.expect, got)
+		}
+	}
+
+	if !(&config.LogRoute{}).Match(base.Debug, nil, "") {
+		t.Errorf("route: empty rule should match any line")
+	}
+}
+
+func TestRoute_SendTo(t *testing.T) {
+	var (
+		file  = newMemoryAdapter("memory-file")
+		term  = newMemoryAdapter("memory-term")
+		kafka = newMemoryAdapter("memory-kafka")
+	)
+
+	// 文件仅接收 WARN 及以上; ERROR 仅发往 Kafka 和文件; order_id 仅发往 Kafka.
+	c, err := config.NewFromBytes([]byte(`
+level: debug
+log_adapter: [file, term, kafka]
+log_adapter_file:
+  level: warn
+log_route:
+  - level: [error]
+    adapter: [kafka, file]
+  - field: {order_id: ""}
+    adapter: [kafka]
+  - span: route.*
+    adapter: [term]
+`))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	multi := log_multi.NewWithConfig(c).Add(base.LogFile, file).Add(base.LogTerm, term).Add(base.LogKafka, kafka)
+
+	send := func(level base.LogLevel, text string, attr adapters.Attr) {
+		line := adapters.NewLine(nil, level, text)
+		line.Attr = attr
+		multi.Send(line)
+	}
+
+	send(base.Debug, "debug", nil)
+	send(base.Warn, "warn", nil)
+	send(base.Error, "error", nil)
+	send(base.Info, "order", adapters.Attr{"order_id": 1})
+
+	sp := trace.NewSpan("route.span")
+	line := adapters.NewLine(sp.Context(), base.Info, "span")
+	multi.Send(line)
+	sp.End()
+
+	for name, c := range map[string]struct {
+		adapter *memoryAdapter
+		expect  []string
+	}{
+		"file":  {file, []string{"warn", "error"}},
+		"term":  {term, []string{"debug", "warn", "span"}},
+		"kafka": {kafka, []string{"debug", "warn", "error", "order"}},
+	} {
+		if len(c.adapter.texts) != len(c.expect) {
+			t.Errorf("route %s: expect %v, got %v", name, c.expect, c.adapter.texts)
+			continue
+		}
+		for i, s := range c.expect {
+			if c.adapter.texts[i] != s {
+				t.Errorf("route %s: expect %v, got %v", name, c.expect, c.adapter.texts)
+				break
+			}
+		}
+		c.adapter.release()
+	}
+}
@@ -0,0 +1,279 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-06-09
+
+package tests
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"github.com/go-wares/log"
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/adapters/log_kafka"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+	"github.com/go-wares/log/trace"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// 格式化消息体.
+func kafkaPayload(t *testing.T, yaml string, line *adapters.Line) []byte {
+	c, err := config.NewFromBytes([]byte("name: order\nversion: \"2.0\"\nlog_adapter_kafka:\n" + yaml))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	return log_kafka.NewFormatter(c).Byte(line)
+}
+
+// 解析 json 消息体.
+func kafkaJson(t *testing.T, yaml string, line *adapters.Line) map[string]interface{} {
+	m := make(map[string]interface{})
+	body := kafkaPayload(t, yaml, line)
+	if err := json.Unmarshal(body, &m); err != nil {
+		t.Fatalf("kafka payload %s: %v", body, err)
+	}
+	return m
+}
+
+// 日志.
+func newSchemaLine() *adapters.Line {
+	line := adapters.NewLine(log.Context(), base.Error, "db down\nretry 3")
+	line.Attr = adapters.Attr{"level": "custom", "id": 7}
+	line.Fields = append(line.Fields, log.String("order_id", "A100"))
+	return line
+}
+
+func TestSchema_Default(t *testing.T) {
+	line := newSchemaLine()
+	defer line.Release()
+
+	// 1. 与 LogData 一致.
+	c, _ := config.NewFromBytes([]byte("name: order\n"))
+	expect, _ := json.Marshal(adapters.NewLogData(c, line))
+	if body := log_kafka.NewFormatter(c).Byte(line); string(body) != string(expect) {
+		t.Errorf("default schema:\n%s\n%s", body, expect)
+	}
+
+	// 2. 展开及重命名.
+	//    同名字段保留在 fields.
+	m := kafkaJson(t, "  schema:\n    flatten: true\n    rename:\n      content: message\n      time_ms: ts\n", line)
+	if m["message"] != "db down\nretry 3" || m["ts"] == nil || m["content"] != nil || m["time_ms"] != nil {
+		t.Errorf("rename: %v", m)
+	}
+	if m["order_id"] != "A100" || m["id"] != float64(7) || m["level"] != "ERROR" {
+		t.Errorf("flatten: %v", m)
+	}
+	if fields, _ := m["fields"].(map[string]interface{}); len(fields) != 1 || fields["level"] != "custom" {
+		t.Errorf("flatten conflict: %v", m["fields"])
+	}
+}
+
+func TestSchema_Ecs(t *testing.T) {
+	line := newSchemaLine()
+	defer line.Release()
+
+	m := kafkaJson(t, "  schema:\n    layout: ecs\n", line)
+	if m["log.level"] != "error" || m["message"] != "db down\nretry 3" || m["ecs.version"] != "1.6.0" || m["service.name"] != "order" || m["service.version"] != "2.0" || m["trace.id"] != line.TraceId {
+		t.Errorf("ecs: %v", m)
+	}
+	if ts, _ := m["@timestamp"].(string); !strings.HasSuffix(ts, "Z") || len(ts) != 24 {
+		t.Errorf("ecs timestamp: %v", m["@timestamp"])
+	}
+	if labels, _ := m["labels"].(map[string]interface{}); labels["order_id"] != "A100" {
+		t.Errorf("ecs labels: %v", m["labels"])
+	}
+
+	// 展开到顶层.
+	if m = kafkaJson(t, "  schema:\n    layout: ecs\n    flatten: true\n", line); m["order_id"] != "A100" || m["labels"] != nil {
+		t.Errorf("ecs flatten: %v", m)
+	}
+}
+
+func TestSchema_Gelf(t *testing.T) {
+	line := newSchemaLine()
+	defer line.Release()
+
+	m := kafkaJson(t, "  schema:\n    layout: gelf\n", line)
+	if m["version"] != "1.1" || m["host"] != "order" || m["short_message"] != "db down" || m["full_message"] != "db down\nretry 3" || m["level"] != float64(3) {
+		t.Errorf("gelf: %v", m)
+	}
+	if m["_order_id"] != "A100" || m["_level"] != "custom" || m["_field_id"] != float64(7) || m["_id"] != nil || m["_trace_id"] != line.TraceId {
+		t.Errorf("gelf fields: %v", m)
+	}
+	if ts, _ := m["timestamp"].(float64); int64(ts*1000+0.5) != line.Time.UnixMilli() {
+		t.Errorf("gelf timestamp: %v", m["timestamp"])
+	}
+}
+
+func TestSchema_Protobuf(t *testing.T) {
+	line := newSchemaLine()
+	defer line.Release()
+
+	// 按字段号收集字符串字段.
+	var (
+		body   = kafkaPayload(t, "  schema:\n    encoding: protobuf\n", line)
+		fields = make(map[uint64][]string)
+	)
+	for len(body) > 0 {
+		tag, n := binary.Uvarint(body)
+		body = body[n:]
+		v, n := binary.Uvarint(body)
+		body = body[n:]
+		if tag&7 == 0 {
+			fields[tag>>3] = append(fields[tag>>3], "varint")
+			continue
+		}
+		fields[tag>>3] = append(fields[tag>>3], string(body[:v]))
+		body = body[v:]
+	}
+	if fields[1][0] != "db down\nretry 3" || fields[3][0] != "ERROR" || fields[8][0] != line.TraceId || fields[14][0] != "order" || len(fields[2]) != 3 || fields[5] == nil {
+		t.Errorf("protobuf: %q", fields)
+	}
+	if fields[2][0] != "\x0a\x02id\x12\x017" {
+		t.Errorf("protobuf map entry: %q", fields[2][0])
+	}
+}
+
+func TestSchema_Avro(t *testing.T) {
+	line := newSchemaLine()
+	defer line.Release()
+
+	var (
+		body = kafkaPayload(t, "  schema:\n    encoding: avro\n", line)
+		long = func() int64 {
+			v, n := binary.Varint(body)
+			body = body[n:]
+			return v
+		}
+		str = func() string {
+			n := long()
+			s := string(body[:n])
+			body = body[n:]
+			return s
+		}
+	)
+
+	if s := str(); s != "db down\nretry 3" {
+		t.Fatalf("avro content: %q", s)
+	}
+	fields := make(map[string]string)
+	for n := long(); n > 0; n = long() {
+		for i := int64(0); i < n; i++ {
+			fields[str()] = str()
+		}
+	}
+	if fields["id"] != "7" || fields["level"] != "custom" || fields["order_id"] != "A100" {
+		t.Errorf("avro fields: %v", fields)
+	}
+	if s := str(); s != "ERROR" {
+		t.Errorf("avro level: %q", s)
+	}
+	if !json.Valid([]byte(log_kafka.AvroSchema)) {
+		t.Errorf("avro schema: invalid json")
+	}
+}
+
+func TestSchema_Request(t *testing.T) {
+	req := httptest.NewRequest("POST", "/orders?id=1", nil)
+	req.Header.Set("User-Agent", "curl/8.0")
+
+	span := trace.NewSpanFromRequest(req, "checkout")
+	defer span.End()
+
+	line := adapters.NewLine(span.Context(), base.Info, "paid")
+	defer line.Release()
+
+	m := kafkaJson(t, "  topic: logs\n", line)
+	if m["request_method"] != "POST" || m["request_url"] != "/orders" || m["user_agent"] != "curl/8.0" {
+		t.Errorf("request fields: %v", m)
+	}
+}
+
+func TestSchema_RequestOptional(t *testing.T) {
+	req := httptest.NewRequest("POST", "/orders", nil)
+	span := trace.NewSpanFromRequest(req, "checkout")
+	defer span.End()
+
+	// 未实现 SpanRequest 的跨度.
+	// 仅嵌入 Span 接口, 不填充请求字段.
+	ctx := context.WithValue(span.Context(), config.OpenTelemetrySpan, struct{ adapters.Span }{span})
+	line := adapters.NewLine(ctx, base.Info, "paid")
+	defer line.Release()
+
+	m := kafkaJson(t, "  topic: logs\n", line)
+	if _, ok := m["request_method"]; ok {
+		t.Errorf("request fields: %v", m)
+	}
+	if m["trace_id"] != span.Trace().TraceId().String() {
+		t.Errorf("trace fields: %v", m)
+	}
+}
+
+func TestSchema_Rename(t *testing.T) {
+	line := newSchemaLine()
+	defer line.Release()
+
+	// 1. 内置字段已重命名时可作为新字段名.
+	m := kafkaJson(t, "  schema:\n    rename:\n      content: level\n      level: severity\n", line)
+	if m["level"] != "db down\nretry 3" || m["severity"] != "ERROR" {
+		t.Errorf("rename swap: %v", m)
+	}
+
+	// 2. 与展开的日志字段同名时不重命名.
+	m = kafkaJson(t, "  schema:\n    flatten: true\n    rename:\n      content: order_id\n", line)
+	if m["order_id"] != "A100" || m["content"] != "db down\nretry 3" {
+		t.Errorf("rename flatten: %v", m)
+	}
+}
+
+func TestSchema_RequestRace(t *testing.T) {
+	req := httptest.NewRequest("GET", "/orders", nil)
+	span := trace.NewSpanFromRequest(req, "list")
+	defer span.End()
+
+	// 读取请求属性与写入并发.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			span.(interface{ ReadRequest(*http.Request) }).ReadRequest(req)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		adapters.NewLine(span.Context(), base.Info, "list").Release()
+	}
+	<-done
+}
+
+func TestSchema_Invalid(t *testing.T) {
+	for yaml, expect := range map[string]string{
+		"  schema:\n    layout: syslog\n":                                  "schema.layout: unknown layout",
+		"  schema:\n    encoding: xml\n":                                   "schema.encoding: unknown encoding",
+		"  schema:\n    encoding: avro\n    layout: gelf\n":                "avro requires layout default",
+		"  schema:\n    encoding: protobuf\n    flatten: true\n":           "protobuf does not support flatten",
+		"  schema:\n    rename:\n      content: ''\n":                      "schema.rename.content: must not be empty",
+		"  schema:\n    rename:\n      content: msg\n      level: msg\n":   "both renamed to \"msg\"",
+		"  schema:\n    rename:\n      content: level\n":                   "schema.rename.content: \"level\" conflicts with built-in field",
+		"  schema:\n    layout: ecs\n    rename:\n      message: labels\n": "schema.rename.message: \"labels\" conflicts with built-in field",
+	} {
+		_, err := config.NewFromBytes([]byte("log_adapter_kafka:\n" + yaml))
+		if err == nil || !strings.Contains(err.Error(), expect) {
+			t.Errorf("kafka schema %q: expect %q, got %v", yaml, expect, err)
+		}
+	}
+}
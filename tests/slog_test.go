@@ -0,0 +1,108 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-27
+
+//go:build go1.21
+// +build go1.21
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"github.com/go-wares/log"
+	"github.com/go-wares/log/config"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlog_Handler(t *testing.T) {
+	dir := t.TempDir()
+	c, err := config.NewFromBytes([]byte("level: info\nlog_adapter: file\nlog_adapter_file:\n  path: " + dir + "\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	logger := log.New(log.Options{Config: c})
+	sl := slog.New(logger.SlogHandler()).With("service", "order")
+
+	// 1. 级别过滤.
+	sl.Debug("debug")
+
+	// 2. 属性及分组.
+	sl.WithGroup("request").InfoContext(log.Context(), "paid",
+		"method", "POST",
+		slog.Group("user", "id", 1),
+		"err", errors.New("timeout"),
+	)
+	sl.Log(context.Background(), slog.LevelWarn+1, "warn")
+	logger.Stop()
+
+	// 3. 校验输出.
+	lines := strings.Split(strings.TrimSpace(readLogDir(t, dir)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("slog: expect 2 lines, got %d: %q", len(lines), lines)
+	}
+	for _, s := range []string{"[INFO]", "[trace-id=", `"service":"order"`, `"request.method":"POST"`, `"request.user.id":1`, `"request.err":"timeout"`, " paid"} {
+		if !strings.Contains(lines[0], s) {
+			t.Errorf("slog: missing %q in %q", s, lines[0])
+		}
+	}
+	if !strings.Contains(lines[1], "[WARN]") {
+		t.Errorf("slog level: %q", lines[1])
+	}
+}
+
+func TestSlog_Record(t *testing.T) {
+	dir := t.TempDir()
+	c, err := config.NewFromBytes([]byte("level: info\nlog_adapter: file\nlog_adapter_file:\n  path: " + dir + "\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	logger := log.New(log.Options{Config: c})
+	h := logger.WithFields(log.Field{"region": "cn"}).With("service", "order").SlogHandler()
+
+	// 1. 记录时间.
+	r := slog.NewRecord(time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local), slog.LevelInfo, "paid", 0)
+	r.AddAttrs(slog.Int("order", 1))
+	if err = h.Handle(context.Background(), r); err != nil {
+		t.Fatalf("slog handle: %v", err)
+	}
+
+	// 2. 处理器属性覆盖绑定字段.
+	slog.New(h).With("service", "pay").Info("refund")
+	logger.Stop()
+
+	// 3. 校验输出.
+	lines := strings.Split(strings.TrimSpace(readLogDir(t, dir)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("slog: expect 2 lines, got %d: %q", len(lines), lines)
+	}
+	for _, s := range []string{"2020-01-02 03:04:05", `"region":"cn"`, `"service":"order"`, `"order":1`} {
+		if !strings.Contains(lines[0], s) {
+			t.Errorf("slog record: missing %q in %q", s, lines[0])
+		}
+	}
+	for _, s := range []string{`"region":"cn"`, `"service":"pay"`} {
+		if !strings.Contains(lines[1], s) {
+			t.Errorf("slog fields: missing %q in %q", s, lines[1])
+		}
+	}
+	if strings.Contains(lines[1], "2020-01-02") {
+		t.Errorf("slog time: %q", lines[1])
+	}
+}
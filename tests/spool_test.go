@@ -0,0 +1,254 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-06-04
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-wares/log/adapters/spool"
+	"github.com/go-wares/log/adapters/trace_jaeger"
+	"github.com/go-wares/log/config"
+	"github.com/go-wares/log/trace"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSpool_Replay(t *testing.T) {
+	var (
+		c      = &config.Spool{SpoolPath: t.TempDir(), SpoolMaxSize: 1, SpoolMilliseconds: 10, SpoolMaxMilliseconds: 40}
+		fail   = int32(1)
+		mu     sync.Mutex
+		values []string
+	)
+
+	s := spool.New("test-spool", func() *config.Spool { return c }, func(records []spool.Record) error {
+		if atomic.LoadInt32(&fail) == 1 {
+			return errors.New("sink down")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, rec := range records {
+			values = append(values, string(rec.Key)+"="+string(rec.Value))
+			for _, h := range rec.Headers {
+				values = append(values, string(h.Key)+":"+string(h.Value))
+			}
+		}
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = s.Keeper().Start(ctx) }()
+
+	// 1. 下游故障.
+	//    批次保留在暂存目录.
+	for i := 0; i < 3; i++ {
+		if err := s.Write([]spool.Record{{Key: []byte("k"), Value: []byte(fmt.Sprintf("v%d", i)), Headers: []spool.Header{{Key: []byte("h"), Value: []byte("1")}}}, {Value: []byte("x")}}); err != nil {
+			t.Fatalf("spool write: %v", err)
+		}
+	}
+	time.Sleep(time.Millisecond * 50)
+	if !s.Pending() {
+		t.Fatalf("spool pending: expect true")
+	}
+
+	// 2. 下游恢复.
+	//    按写入顺序重放并删除文件.
+	atomic.StoreInt32(&fail, 0)
+	for i := 0; i < 100 && s.Pending(); i++ {
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(values) != "[k=v0 h:1 =x k=v1 h:1 =x k=v2 h:1 =x]" {
+		t.Errorf("spool replay: %v", values)
+	}
+	if entries, _ := os.ReadDir(c.SpoolPath); len(entries) != 0 {
+		t.Errorf("spool files: %d left", len(entries))
+	}
+}
+
+func TestSpool_ReadError(t *testing.T) {
+	var (
+		c      = &config.Spool{SpoolPath: t.TempDir(), SpoolMaxSize: 1, SpoolMilliseconds: 10, SpoolMaxMilliseconds: 40}
+		mu     sync.Mutex
+		values []string
+	)
+
+	s := spool.New("test-spool", func() *config.Spool { return c }, func(records []spool.Record) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, rec := range records {
+			values = append(values, string(rec.Value))
+		}
+		return nil
+	})
+	for i := 0; i < 3; i++ {
+		if err := s.Write([]spool.Record{{Value: []byte(fmt.Sprintf("v%d", i))}}); err != nil {
+			t.Fatalf("spool write: %v", err)
+		}
+	}
+
+	// 1. 首个文件无法读取.
+	//    以失效的符号链接替换, 后续文件不得先于其重放.
+	entries, _ := os.ReadDir(c.SpoolPath)
+	if len(entries) != 3 {
+		t.Fatalf("spool files: expect 3, got %d", len(entries))
+	}
+	first := filepath.Join(c.SpoolPath, entries[0].Name())
+	if err := os.Rename(first, first+".bak"); err != nil {
+		t.Fatalf("spool rename: %v", err)
+	}
+	if err := os.Symlink(first+".missing", first); err != nil {
+		t.Fatalf("spool symlink: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = s.Keeper().Start(ctx) }()
+
+	time.Sleep(time.Millisecond * 50)
+	mu.Lock()
+	if len(values) != 0 {
+		t.Errorf("spool order: replayed %v after read error", values)
+	}
+	mu.Unlock()
+
+	// 2. 文件恢复.
+	//    按写入顺序重放.
+	_ = os.Remove(first)
+	if err := os.Rename(first+".bak", first); err != nil {
+		t.Fatalf("spool restore: %v", err)
+	}
+	for i := 0; i < 100 && s.Pending(); i++ {
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(values) != "[v0 v1 v2]" {
+		t.Errorf("spool replay: %v", values)
+	}
+}
+
+func TestSpool_Limit(t *testing.T) {
+	// 1. 未启用.
+	c := &config.Spool{SpoolMaxSize: 1}
+	s := spool.New("test-spool", func() *config.Spool { return c }, func([]spool.Record) error { return nil })
+	if err := s.Write([]spool.Record{{Value: []byte("x")}}); err != spool.ErrDisabled {
+		t.Errorf("spool disabled: %v", err)
+	}
+
+	// 2. 超过容量.
+	c.SpoolPath = t.TempDir()
+	if err := s.Write([]spool.Record{{Value: make([]byte, 600*1024)}}); err != nil {
+		t.Fatalf("spool write: %v", err)
+	}
+	if err := s.Write([]spool.Record{{Value: make([]byte, 600*1024)}}); err != spool.ErrFull {
+		t.Errorf("spool full: %v", err)
+	}
+}
+
+func TestSpool_Jaeger(t *testing.T) {
+	var (
+		down     = int32(1)
+		received = make(chan struct{}, 1)
+		server   = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.LoadInt32(&down) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+			received <- struct{}{}
+		}))
+	)
+	defer server.Close()
+
+	c, err := config.NewFromBytes([]byte("trace_adapter: jaeger\ntrace_adapter_jaeger:\n  endpoint: " + server.URL + "\n  milliseconds: 10\n  spool_path: " + t.TempDir() + "\n  spool_milliseconds: 20\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	adapter := trace_jaeger.NewWithConfig(c)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = adapter.Keeper().Start(ctx) }()
+
+	// 1. 上报失败.
+	adapter.Send(trace.NewSpan("jaeger spool"))
+	time.Sleep(time.Millisecond * 50)
+	if entries, _ := os.ReadDir(c.TraceAdapterJaeger.SpoolPath); len(entries) != 1 {
+		t.Fatalf("jaeger spool: expect 1 file, got %d", len(entries))
+	}
+
+	// 2. 恢复后重放.
+	atomic.StoreInt32(&down, 0)
+	select {
+	case <-received:
+	case <-time.After(time.Second * 3):
+		t.Errorf("jaeger spool: not replayed")
+	}
+}
+
+func TestSpool_JaegerSerial(t *testing.T) {
+	var (
+		busy, max, posts int32
+		server           = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&busy, 1)
+			defer atomic.AddInt32(&busy, -1)
+			for {
+				if m := atomic.LoadInt32(&max); n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond * 5)
+			atomic.AddInt32(&posts, 1)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+	)
+	defer server.Close()
+
+	c, err := config.NewFromBytes([]byte("trace_adapter: jaeger\ntrace_adapter_jaeger:\n  endpoint: " + server.URL + "\n  batch: 1\n  milliseconds: 10\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	adapter := trace_jaeger.NewWithConfig(c)
+	stop := runKeeper(adapter.Keeper())
+
+	// 1. 并发触发保存.
+	//    批次串行上报, 同一时刻至多1个请求.
+	for i := 0; i < 20; i++ {
+		adapter.Send(trace.NewSpan("jaeger serial"))
+	}
+
+	// 2. 退出时等待上报完成.
+	stop()
+	if n := atomic.LoadInt32(&posts); n != 20 {
+		t.Errorf("jaeger posts: expect 20, got %d", n)
+	}
+	if n := atomic.LoadInt32(&max); n != 1 {
+		t.Errorf("jaeger concurrency: expect 1, got %d", n)
+	}
+}
@@ -0,0 +1,175 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-06-10
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/go-wares/log/adapters/trace_kafka"
+	"github.com/go-wares/log/adapters/trace_zipkin"
+	"github.com/go-wares/log/config"
+	"github.com/go-wares/log/trace"
+	"sync"
+	"testing"
+)
+
+// 记录批次的生产者.
+type traceKafkaProducer struct {
+	*mocks.SyncProducer
+	batches [][]*sarama.ProducerMessage
+	mu      sync.Mutex
+}
+
+func (o *traceKafkaProducer) SendMessages(msg []*sarama.ProducerMessage) error {
+	o.mu.Lock()
+	o.batches = append(o.batches, msg)
+	o.mu.Unlock()
+	return o.SyncProducer.SendMessages(msg)
+}
+
+// 启动适配器.
+// 返回停止函数; 停止时等待数据桶清空.
+func startTraceKafka(t *testing.T, yaml string, producer sarama.SyncProducer) (*trace_kafka.Manager, func()) {
+	c, err := config.NewFromBytes([]byte("trace_adapter: kafka\ntrace_adapter_kafka:\n  milliseconds: 10000\n" + yaml))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	adapter := trace_kafka.NewWithConfig(c).(*trace_kafka.Manager)
+	adapter.SetProducer(producer)
+	return adapter, runKeeper(adapter.Keeper())
+}
+
+func TestTraceKafka_Json(t *testing.T) {
+	var (
+		mock    = mocks.NewSyncProducer(t, nil)
+		sp      = trace.NewSpan("kafka span")
+		traceId = sp.Trace().TraceId().String()
+	)
+
+	mock.ExpectSendMessageWithCheckerFunctionAndSucceed(func(value []byte) error {
+		// 单个跨度对象, 非数组.
+		span := &trace_zipkin.Span{}
+		if err := json.Unmarshal(value, span); err != nil {
+			return err
+		}
+		if span.Name != "kafka span" || span.TraceId != traceId {
+			t.Errorf("trace kafka json: %s", value)
+		}
+		return nil
+	})
+
+	producer := &traceKafkaProducer{SyncProducer: mock}
+	adapter, stop := startTraceKafka(t, "  topic: spans\n", producer)
+	adapter.Send(sp)
+	stop()
+
+	if len(producer.batches) != 1 {
+		t.Fatalf("trace kafka batches: %d", len(producer.batches))
+	}
+	if m := producer.batches[0][0]; m.Topic != "spans" {
+		t.Errorf("trace kafka topic: %s", m.Topic)
+	} else if key, _ := m.Key.Encode(); string(key) != traceId {
+		t.Errorf("trace kafka key: %s", key)
+	}
+	if err := mock.Close(); err != nil {
+		t.Errorf("trace kafka: %v", err)
+	}
+}
+
+func TestTraceKafka_Thrift(t *testing.T) {
+	mock := mocks.NewSyncProducer(t, nil)
+	mock.ExpectSendMessageWithCheckerFunctionAndSucceed(func(value []byte) error {
+		if len(value) == 0 || value[0] == '[' || !bytes.Contains(value, []byte("kafka thrift")) {
+			t.Errorf("trace kafka thrift: %q", value)
+		}
+		return nil
+	})
+
+	adapter, stop := startTraceKafka(t, "  encoding: thrift\n", mock)
+	adapter.Send(trace.NewSpan("kafka thrift"))
+	stop()
+
+	if err := mock.Close(); err != nil {
+		t.Errorf("trace kafka: %v", err)
+	}
+}
+
+func TestTraceKafka_Batch(t *testing.T) {
+	mock := mocks.NewSyncProducer(t, nil)
+	for i := 0; i < 5; i++ {
+		mock.ExpectSendMessageAndSucceed()
+	}
+
+	producer := &traceKafkaProducer{SyncProducer: mock}
+	adapter, stop := startTraceKafka(t, "  batch: 2\n", producer)
+	for i := 0; i < 5; i++ {
+		adapter.Send(trace.NewSpan("kafka batch"))
+	}
+	stop()
+
+	// 按批次发送, 退出前清空数据桶.
+	total := 0
+	for _, batch := range producer.batches {
+		if len(batch) == 0 || len(batch) > 2 {
+			t.Errorf("trace kafka batch size: %d", len(batch))
+		}
+		total += len(batch)
+	}
+	if total != 5 {
+		t.Errorf("trace kafka messages: expect 5, got %d", total)
+	}
+	if err := mock.Close(); err != nil {
+		t.Errorf("trace kafka: %v", err)
+	}
+}
+
+func TestTraceKafka_Restart(t *testing.T) {
+	c, err := config.NewFromBytes([]byte("trace_adapter: kafka\ntrace_adapter_kafka:\n  batch: 1\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	var (
+		adapter   = trace_kafka.NewWithConfig(c).(*trace_kafka.Manager)
+		mu        sync.Mutex
+		producers []*traceKafkaProducer
+	)
+	adapter.SetProducerFactory(func(k *config.TraceAdapterKafka) (sarama.SyncProducer, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		mock := mocks.NewSyncProducer(t, nil)
+		mock.ExpectSendMessageAndSucceed()
+		p := &traceKafkaProducer{SyncProducer: mock}
+		producers = append(producers, p)
+		return p, nil
+	})
+
+	// 停止后重新启动, 重建生产者.
+	for i := 0; i < 2; i++ {
+		stop := runKeeper(adapter.Keeper())
+		adapter.Send(trace.NewSpan("kafka restart"))
+		stop()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(producers) != 2 || len(producers[1].batches) != 1 {
+		t.Errorf("trace kafka restart: %d producers", len(producers))
+	}
+}
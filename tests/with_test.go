@@ -0,0 +1,66 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-26
+
+package tests
+
+import (
+	"github.com/go-wares/log"
+	"github.com/go-wares/log/config"
+	"strings"
+	"testing"
+)
+
+func TestWith(t *testing.T) {
+	dir := t.TempDir()
+	c, err := config.NewFromBytes([]byte("log_adapter: file\nlog_adapter_file:\n  path: " + dir + "\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	logger := log.New(log.Options{Config: c})
+
+	// 1. 链式绑定.
+	//    子实例不影响上级实例.
+	parent := logger.With("user_id", 1)
+	child := parent.With("order", "A001").WithContext(log.Context())
+	child.Info("child")
+	parent.Info("parent")
+	parent.WithFields(log.Field{"user_id": 2}).Info("override")
+	logger.Stop()
+
+	// 2. 校验输出.
+	lines := strings.Split(strings.TrimSpace(readLogDir(t, dir)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("with: expect 3 lines, got %d: %q", len(lines), lines)
+	}
+	for _, line := range lines {
+		switch {
+		case strings.HasSuffix(line, " child"):
+			if !strings.Contains(line, `"order":"A001"`) || !strings.Contains(line, `"user_id":1`) || !strings.Contains(line, "[trace-id=") {
+				t.Errorf("with child: %q", line)
+			}
+		case strings.HasSuffix(line, " parent"):
+			if strings.Contains(line, "order") || strings.Contains(line, "trace-id") || !strings.Contains(line, `"user_id":1`) {
+				t.Errorf("with parent: %q", line)
+			}
+		case strings.HasSuffix(line, " override"):
+			if !strings.Contains(line, `"user_id":2`) {
+				t.Errorf("with override: %q", line)
+			}
+		default:
+			t.Errorf("with: unexpected line %q", line)
+		}
+	}
+}
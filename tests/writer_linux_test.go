@@ -0,0 +1,51 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-06-10
+
+package tests
+
+import (
+	"fmt"
+	"github.com/go-wares/log"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestWriter_CaptureFd(t *testing.T) {
+	dir := t.TempDir()
+	c, err := config.NewFromBytes([]byte("log_adapter: file\nlog_adapter_file:\n  path: " + dir + "\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	logger := log.New(log.Options{Config: c})
+	restore, err := logger.CaptureStderr(base.Error)
+	if err != nil {
+		t.Fatalf("capture stderr: %v", err)
+	}
+
+	// 直接写文件描述符2的内容被捕获, 诊断输出不被捕获.
+	_, _ = syscall.Write(2, []byte("fd line\n"))
+	_, _ = fmt.Fprintln(base.Stderr(), "diagnostic line")
+	restore()
+	logger.Stop()
+
+	text := readLogDir(t, dir)
+	if !strings.Contains(text, "[ERROR] fd line") || strings.Contains(text, "diagnostic line") {
+		t.Errorf("capture fd: %q", text)
+	}
+}
@@ -0,0 +1,76 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-28
+
+package tests
+
+import (
+	"fmt"
+	"github.com/go-wares/log"
+	"github.com/go-wares/log/base"
+	"github.com/go-wares/log/config"
+	stdlog "log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	dir := t.TempDir()
+	c, err := config.NewFromBytes([]byte("level: info\nlog_adapter: file\nlog_adapter_file:\n  path: " + dir + "\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	logger := log.New(log.Options{Config: c})
+
+	// 1. 按行写入.
+	//    不完整的行在 Close 时输出; 低于配置级别的忽略.
+	w := logger.With("lib", "third").Writer(base.Warn)
+	_, _ = w.Write([]byte("first li"))
+	_, _ = w.Write([]byte("ne\r\n\nsecond line\nthird"))
+	_ = w.Close()
+	_, _ = logger.Writer(base.Debug).Write([]byte("ignored\n"))
+
+	// 2. 标准库 log.
+	restore := logger.RedirectStdLog()
+	stdlog.Printf("std %s", "log")
+	restore()
+
+	// 3. 标准错误输出.
+	if restore, err = logger.CaptureStderr(base.Error); err != nil {
+		t.Fatalf("capture stderr: %v", err)
+	}
+	_, _ = fmt.Fprintln(os.Stderr, "stderr line")
+	restore()
+	logger.Stop()
+
+	// 4. 校验输出.
+	lines := strings.Split(strings.TrimSpace(readLogDir(t, dir)), "\n")
+	expects := []string{
+		`[WARN] {"lib":"third"} first line`,
+		`[WARN] {"lib":"third"} second line`,
+		`[WARN] {"lib":"third"} third`,
+		`[INFO] std log`,
+		`[ERROR] stderr line`,
+	}
+	if len(lines) != len(expects) {
+		t.Fatalf("writer: expect %d lines, got %d: %q", len(expects), len(lines), lines)
+	}
+	for i, s := range expects {
+		if !strings.HasSuffix(lines[i], s) {
+			t.Errorf("writer: line %d expect %q, got %q", i, s, lines[i])
+		}
+	}
+}
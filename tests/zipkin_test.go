@@ -0,0 +1,77 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-18
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-wares/log/adapters/trace_zipkin"
+	"github.com/go-wares/log/config"
+	"github.com/go-wares/log/trace"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestZipkin(t *testing.T) {
+	var (
+		received = make(chan []*trace_zipkin.Span, 1)
+		server   = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			list := make([]*trace_zipkin.Span, 0)
+			if err := json.Unmarshal(body, &list); err != nil {
+				t.Errorf("zipkin body: %v", err)
+			}
+			w.WriteHeader(http.StatusAccepted)
+			received <- list
+		}))
+	)
+	defer server.Close()
+
+	c, err := config.NewFromBytes([]byte("trace_adapter_zipkin:\n  endpoint: " + server.URL + "\n"))
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	adapter := trace_zipkin.NewWithConfig(c)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { _ = adapter.Keeper().Start(ctx) }()
+
+	sp := trace.NewSpan("zipkin span")
+	sp.Attr().Set("uid", 1)
+	sp.Info("zipkin annotation")
+	adapter.Send(sp.Child("zipkin child"))
+	adapter.Send(sp)
+
+	select {
+	case list := <-received:
+		if len(list) != 2 {
+			t.Fatalf("zipkin spans: expect 2, got %d", len(list))
+		}
+		if list[0].ParentId != list[1].Id {
+			t.Errorf("zipkin parent: expect %s, got %s", list[1].Id, list[0].ParentId)
+		}
+		if list[1].Tags["uid"] != "1" || len(list[1].Annotations) != 1 {
+			t.Errorf("zipkin span: %+v", list[1])
+		}
+	case <-time.After(time.Second * 3):
+		t.Errorf("zipkin: no spans received")
+	}
+
+	cancel()
+}
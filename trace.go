@@ -23,23 +23,23 @@ import (
 )
 
 func NewSpan(name string) adapters.Span {
-	return trace.NewSpan(name)
+	return std.NewSpan(name)
 }
 
 func NewSpanFromContext(ctx context.Context, name string) adapters.Span {
-	return trace.NewSpanFromContext(ctx, name)
+	return std.NewSpanFromContext(ctx, name)
 }
 
 func NewSpanFromRequest(req *http.Request, name string) adapters.Span {
-	return trace.NewSpanFromRequest(req, name)
+	return std.NewSpanFromRequest(req, name)
 }
 
 func NewTrace(name string) adapters.Trace {
-	return trace.NewTrace(name)
+	return std.NewTrace(name)
 }
 
 func NewTraceFromContext(ctx context.Context, name string) adapters.Trace {
-	return trace.NewTraceFromContext(ctx, name)
+	return std.NewTraceFromContext(ctx, name)
 }
 
 func SpanExists(ctx context.Context) (span adapters.Span, exists bool) {
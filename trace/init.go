@@ -23,8 +23,20 @@ import (
 var (
 	LogManager   adapters.LogAdapter
 	TraceManager adapters.TraceAdapter
+
+	traceManagerMu sync.RWMutex
 )
 
+// SetTraceManager
+// 设置全局链路适配器.
+//
+// 运行期间替换 TraceManager 时须经此方法, 与上报跨度时的读取互斥.
+func SetTraceManager(adapter adapters.TraceAdapter) {
+	traceManagerMu.Lock()
+	defer traceManagerMu.Unlock()
+	TraceManager = adapter
+}
+
 func init() {
 	new(sync.Once).Do(func() {
 		spanPool.New = func() interface{} { return (&span{}).init() }
@@ -41,6 +41,7 @@ type (
 		mu                   *sync.RWMutex
 		name                 string
 		running              bool
+		source               Source
 		spanId, parentSpanId adapters.SpanId
 		trace                adapters.Trace
 	}
@@ -49,34 +50,19 @@ type (
 // NewSpan
 // 创建跨度.
 func NewSpan(name string) adapters.Span {
-	return NewTrace(name).Begin(name)
+	return std.NewSpan(name)
 }
 
 // NewSpanFromContext
 // 基于上下文创建跨度.
 func NewSpanFromContext(ctx context.Context, name string) adapters.Span {
-	// 1. 基于跨度.
-	//    从跨度(Span)上开启子跨度(Span).
-	if x := ctx.Value(config.OpenTelemetrySpan); x != nil {
-		if o, ok := x.(adapters.Span); ok {
-			return o.Child(name)
-		}
-	}
-
-	// 2. 创建跨度.
-	return NewTraceFromContext(ctx, name).Begin(name)
+	return std.NewSpanFromContext(ctx, name)
 }
 
 // NewSpanFromRequest
 // 基于HTTP请求创建跨度.
 func NewSpanFromRequest(req *http.Request, name string) adapters.Span {
-	o := NewTraceFromRequest(req, name)
-	s := o.Begin(name)
-	s.(*span).ReadRequest(req)
-	return s
-	// o := NewSpanFromContext(req.Context(), name)
-	// o.(*span).ReadRequest(req)
-	// return o
+	return std.NewSpanFromRequest(req, name)
 }
 
 func SpanExists(ctx context.Context) (span adapters.Span, exists bool) {
@@ -92,6 +78,7 @@ func (o *span) Child(name string) adapters.Span {
 	v := spanPool.Get().(*span).before()
 	v.name = name
 	v.parentSpanId = o.spanId
+	v.source = o.source
 	v.trace = o.trace
 	v.withCtx(o.ctx)
 	return v
@@ -118,31 +105,31 @@ func (o *span) Trace() adapters.Trace         { return o.trace }
 // +---------------------------------------------------------------------------+
 
 func (o *span) Debug(format string, args ...interface{}) {
-	if config.Config.DebugOn() {
+	if getConfig(o.source).DebugOn() {
 		o.log(base.Debug, format, args...)
 	}
 }
 
 func (o *span) Info(format string, args ...interface{}) {
-	if config.Config.InfoOn() {
+	if getConfig(o.source).InfoOn() {
 		o.log(base.Info, format, args...)
 	}
 }
 
 func (o *span) Warn(format string, args ...interface{}) {
-	if config.Config.WarnOn() {
+	if getConfig(o.source).WarnOn() {
 		o.log(base.Warn, format, args...)
 	}
 }
 
 func (o *span) Error(format string, args ...interface{}) {
-	if config.Config.ErrorOn() {
+	if getConfig(o.source).ErrorOn() {
 		o.log(base.Error, format, args...)
 	}
 }
 
 func (o *span) Fatal(format string, args ...interface{}) {
-	if config.Config.FatalOn() {
+	if getConfig(o.source).FatalOn() {
 		o.log(base.Fatal, format, args...)
 	}
 }
@@ -154,11 +141,24 @@ func (o *span) Fatal(format string, args ...interface{}) {
 func (o *span) ReadRequest(request *http.Request) {
 	buf, _ := json.Marshal(request.Header)
 
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
 	o.attr.Set("http.header", string(buf))
 	o.attr.Set("http.protocol", request.Proto)
-	o.attr.Set("http.request.method", request.Method)
-	o.attr.Set("http.request.uri", request.URL.Path)
-	o.attr.Set("http.user.agent", request.UserAgent())
+	o.attr.Set(adapters.AttrHttpRequestMethod, request.Method)
+	o.attr.Set(adapters.AttrHttpRequestUri, request.URL.Path)
+	o.attr.Set(adapters.AttrHttpUserAgent, request.UserAgent())
+}
+
+func (o *span) Request() (method, url, userAgent string) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	method, _ = o.attr[adapters.AttrHttpRequestMethod].(string)
+	url, _ = o.attr[adapters.AttrHttpRequestUri].(string)
+	userAgent, _ = o.attr[adapters.AttrHttpUserAgent].(string)
+	return
 }
 
 func (o *span) WriteRequest(request *http.Request) {
@@ -184,6 +184,7 @@ func (o *span) after() {
 	o.endTime = spanNilTime
 	o.lines = nil
 	o.parentSpanId = nil
+	o.source = nil
 	o.spanId = nil
 	o.trace = nil
 }
@@ -214,8 +215,8 @@ func (o *span) end() {
 	o.mu.Unlock()
 
 	// 3. 上报链路.
-	if TraceManager != nil {
-		TraceManager.Send(o)
+	if m := getTraceManager(o.source); m != nil {
+		m.Send(o)
 	} else {
 		o.Release()
 	}
@@ -242,10 +243,12 @@ func (o *span) log(level base.LogLevel, format string, args ...interface{}) {
 
 	// 2. 日志同步.
 	//    当记录链路(跨度)日志时, 同步写一份到日志系统中.
-	if *config.Config.TraceAdapterSyncLog {
-		line := adapters.NewLine(o.ctx, level, format, args...)
-		// line.Attr = o.Attr()
-		LogManager.Send(line)
+	if *getConfig(o.source).Snapshot().TraceAdapterSyncLog {
+		if m := getLogManager(o.source); m != nil {
+			line := adapters.NewLine(o.ctx, level, format, args...)
+			// line.Attr = o.Attr()
+			m.Send(line)
+		}
 	}
 }
 
@@ -28,6 +28,7 @@ type (
 		ctx          context.Context
 		name         string
 		parentSpanId adapters.SpanId
+		source       Source
 		traceId      adapters.TraceId
 	}
 )
@@ -35,7 +36,25 @@ type (
 // NewTrace
 // 创建根链路.
 func NewTrace(name string) adapters.Trace {
-	o := (&trace{name: name}).init()
+	return std.NewTrace(name)
+}
+
+// NewTraceFromContext
+// 基于上下文创建链路.
+func NewTraceFromContext(ctx context.Context, name string) adapters.Trace {
+	return std.NewTraceFromContext(ctx, name)
+}
+
+// NewTraceFromRequest
+// 基于HTTP请求创建链路.
+func NewTraceFromRequest(req *http.Request, name string) adapters.Trace {
+	return std.NewTraceFromRequest(req, name)
+}
+
+// NewTrace
+// 创建根链路.
+func (t *Tracer) NewTrace(name string) adapters.Trace {
+	o := (&trace{name: name, source: t.source}).init()
 	o.traceId = adapters.NewTraceId()
 	o.ctx = context.WithValue(context.Background(), config.OpenTelemetryTrace, o)
 	return o
@@ -43,7 +62,7 @@ func NewTrace(name string) adapters.Trace {
 
 // NewTraceFromContext
 // 基于上下文创建链路.
-func NewTraceFromContext(ctx context.Context, name string) adapters.Trace {
+func (t *Tracer) NewTraceFromContext(ctx context.Context, name string) adapters.Trace {
 	// 1. 链路复用.
 	if g := ctx.Value(config.OpenTelemetryTrace); g != nil {
 		if o, ok := g.(*trace); ok {
@@ -52,7 +71,7 @@ func NewTraceFromContext(ctx context.Context, name string) adapters.Trace {
 	}
 
 	// 2. 创建链路
-	o := (&trace{name: name}).init()
+	o := (&trace{name: name, source: t.source}).init()
 
 	// 3. 复用链路ID.
 	if g := ctx.Value(config.OpenTracingTraceId); g != nil {
@@ -78,8 +97,10 @@ func NewTraceFromContext(ctx context.Context, name string) adapters.Trace {
 	return o
 }
 
-func NewTraceFromRequest(req *http.Request, name string) adapters.Trace {
-	o := (&trace{name: name}).init()
+// NewTraceFromRequest
+// 基于HTTP请求创建链路.
+func (t *Tracer) NewTraceFromRequest(req *http.Request, name string) adapters.Trace {
+	o := (&trace{name: name, source: t.source}).init()
 
 	if s := req.Header.Get(config.OpenTracingTraceId); s != "" {
 		o.traceId = adapters.NewTraceIdFromString(s)
@@ -103,6 +124,7 @@ func (o *trace) Begin(name string) adapters.Span {
 	v := spanPool.Get().(*span).before()
 	v.name = name
 	v.parentSpanId = o.parentSpanId
+	v.source = o.source
 	v.trace = o
 	v.withCtx(o.ctx)
 	return v
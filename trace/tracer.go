@@ -0,0 +1,114 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-25
+
+package trace
+
+import (
+	"context"
+	"github.com/go-wares/log/adapters"
+	"github.com/go-wares/log/config"
+	"net/http"
+)
+
+var (
+	// 全局链路创建器.
+	// 使用全局配置和 LogManager, TraceManager.
+	std = &Tracer{}
+)
+
+type (
+	// Source
+	// 适配器来源.
+	//
+	// 非全局日志实例通过此接口提供配置和适配器; 未指定时使用全局配置和全局
+	// LogManager, TraceManager.
+	Source interface {
+		GetConfig() *config.Configuration
+		GetLogAdapter() adapters.LogAdapter
+		GetTraceAdapter() adapters.TraceAdapter
+	}
+
+	// Tracer
+	// 链路创建器.
+	//
+	// 由此创建的链路及其跨度, 同步日志和上报跨度时使用绑定的适配器来源.
+	Tracer struct {
+		source Source
+	}
+)
+
+// NewTracer
+// 创建链路创建器.
+func NewTracer(source Source) *Tracer {
+	return &Tracer{source: source}
+}
+
+// NewSpan
+// 创建跨度.
+func (t *Tracer) NewSpan(name string) adapters.Span {
+	return t.NewTrace(name).Begin(name)
+}
+
+// NewSpanFromContext
+// 基于上下文创建跨度.
+func (t *Tracer) NewSpanFromContext(ctx context.Context, name string) adapters.Span {
+	// 1. 基于跨度.
+	//    从跨度(Span)上开启子跨度(Span).
+	if x := ctx.Value(config.OpenTelemetrySpan); x != nil {
+		if o, ok := x.(adapters.Span); ok {
+			return o.Child(name)
+		}
+	}
+
+	// 2. 创建跨度.
+	return t.NewTraceFromContext(ctx, name).Begin(name)
+}
+
+// NewSpanFromRequest
+// 基于HTTP请求创建跨度.
+func (t *Tracer) NewSpanFromRequest(req *http.Request, name string) adapters.Span {
+	o := t.NewTraceFromRequest(req, name)
+	s := o.Begin(name)
+	s.(*span).ReadRequest(req)
+	return s
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+func getConfig(source Source) *config.Configuration {
+	if source != nil {
+		return source.GetConfig()
+	}
+	return config.Config
+}
+
+func getLogManager(source Source) adapters.LogAdapter {
+	if source != nil {
+		return source.GetLogAdapter()
+	}
+	return LogManager
+}
+
+func getTraceManager(source Source) adapters.TraceAdapter {
+	if source != nil {
+		return source.GetTraceAdapter()
+	}
+
+	traceManagerMu.RLock()
+	defer traceManagerMu.RUnlock()
+	return TraceManager
+}
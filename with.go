@@ -0,0 +1,97 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-26
+
+package log
+
+import (
+	"context"
+)
+
+// With
+// 绑定字段, 返回子实例.
+//
+//	logger := log.With("user_id", 1).With("order", id)
+//	logger.Info("paid")
+func With(key string, value interface{}) *Logger {
+	return std.With(key, value)
+}
+
+// WithContext
+// 绑定上下文, 返回子实例.
+func WithContext(ctx context.Context) *Logger {
+	return std.WithContext(ctx)
+}
+
+// WithFields
+// 绑定多个字段, 返回子实例.
+func WithFields(fields Field) *Logger {
+	return std.WithFields(fields)
+}
+
+// +---------------------------------------------------------------------------+
+// | With methods                                                              |
+// +---------------------------------------------------------------------------+
+
+// With
+// 绑定字段, 返回子实例.
+//
+// 子实例复制已绑定的字段后追加, 原实例不受影响; 同名字段以后绑定的为准. 子
+// 实例与原实例共用配置及适配器, 可在请求开始时创建一次后重复使用.
+func (o *Logger) With(key string, value interface{}) *Logger {
+	return o.WithFields(Field{key: value})
+}
+
+// WithContext
+// 绑定上下文, 返回子实例.
+//
+// 日志关联上下文中的链路; 调用 Debugfc 等方法时, 以参数指定的上下文为准.
+func (o *Logger) WithContext(ctx context.Context) *Logger {
+	c := o.clone()
+	c.ctx = ctx
+	return c
+}
+
+// WithFields
+// 绑定多个字段, 返回子实例.
+func (o *Logger) WithFields(fields Field) *Logger {
+	c := o.clone()
+	c.fields = make(Field, len(o.fields)+len(fields))
+
+	for k, v := range o.fields {
+		c.fields[k] = v
+	}
+	for k, v := range fields {
+		c.fields[k] = v
+	}
+	return c
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+func (o *Logger) clone() *Logger {
+	c := *o
+	return &c
+}
+
+// 日志上下文.
+// 优先使用参数指定的上下文, 未指定时使用绑定的上下文.
+func (o *Logger) context(ctx context.Context) context.Context {
+	if ctx != nil {
+		return ctx
+	}
+	return o.ctx
+}
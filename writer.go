@@ -0,0 +1,238 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-05-28
+
+package log
+
+import (
+	"bytes"
+	"github.com/go-wares/log/base"
+	"io"
+	stdlog "log"
+	"os"
+	"sync"
+)
+
+type (
+	// Writer
+	// 日志写入器.
+	//
+	// 实现 io.Writer, 写入内容按行转为指定级别的日志; 不完整的行暂存, 收到换行
+	// 或调用 Close 后输出. 用于对接标准库 log 包及只接受 io.Writer 的第三方库.
+	Writer struct {
+		buf    []byte
+		level  base.LogLevel
+		logger *Logger
+		mu     sync.Mutex
+	}
+)
+
+// NewWriter
+// 创建日志写入器.
+func NewWriter(level base.LogLevel) *Writer {
+	return std.Writer(level)
+}
+
+// RedirectStdLog
+// 重定向标准库 log 包的输出.
+//
+// 默认级别为 INFO, 返回还原函数.
+//
+//	restore := log.RedirectStdLog()
+//	defer restore()
+func RedirectStdLog(level ...base.LogLevel) (restore func()) {
+	return std.RedirectStdLog(level...)
+}
+
+// CaptureStderr
+// 捕获进程的标准错误输出.
+//
+// 写入内容按行转为指定级别的日志, 同时写入原始的标准错误输出; 返回还原函数.
+//
+// Linux 及 BSD 系统(含 macOS)重定向文件描述符2, cgo 及运行时(如: panic)直接
+// 写入的内容也被捕获; 其它系统仅替换 os.Stderr 变量. 捕获期间适配器的诊断
+// 信息(base.Stderr)写入原始的标准错误输出.
+//
+// 注意: 文件描述符2指向管道, 由后台协程读取后再写入原始输出及日志, 并非同步
+// 写入. 进程退出前直接写入的内容, 如: 未恢复的 panic、fatal error、os.Exit
+// 前的输出, 可能尚未读取即随进程结束而丢失, 原始输出及日志中均不可见. 须保留
+// 崩溃信息时不要使用此方法, 或由外部(如: systemd、容器运行时)收集标准错误
+// 输出; 正常退出前应先调用还原函数, 等待管道中的内容写完.
+func CaptureStderr(level base.LogLevel) (restore func(), err error) {
+	return std.CaptureStderr(level)
+}
+
+// +---------------------------------------------------------------------------+
+// | Logger methods                                                            |
+// +---------------------------------------------------------------------------+
+
+// Writer
+// 创建日志写入器, 日志携带实例绑定的字段及上下文.
+func (o *Logger) Writer(level base.LogLevel) *Writer {
+	return &Writer{level: level, logger: o}
+}
+
+// RedirectStdLog
+// 重定向标准库 log 包的输出.
+func (o *Logger) RedirectStdLog(level ...base.LogLevel) (restore func()) {
+	var (
+		flags  = stdlog.Flags()
+		output = stdlog.Writer()
+		writer = o.Writer(base.Info)
+	)
+
+	if len(level) > 0 {
+		writer.level = level[0]
+	}
+
+	// 时间等由适配器格式化.
+	stdlog.SetFlags(0)
+	stdlog.SetOutput(writer)
+
+	return func() {
+		stdlog.SetFlags(flags)
+		stdlog.SetOutput(output)
+		_ = writer.Close()
+	}
+}
+
+// CaptureStderr
+// 捕获进程的标准错误输出.
+//
+// 管道异步读取, 进程崩溃前的输出可能丢失, 见包级 CaptureStderr 说明.
+func (o *Logger) CaptureStderr(level base.LogLevel) (restore func(), err error) {
+	var (
+		done     = make(chan struct{})
+		origin   = os.Stderr
+		output   *os.File
+		previous = base.Stderr()
+		reader   *os.File
+		writer   *os.File
+	)
+
+	// 1. 原始输出.
+	//    支持时复制文件描述符2, 重定向后仍可写入原始的标准错误输出.
+	if output, err = dupStderr(); err != nil {
+		return
+	}
+	redirect := output != nil
+	if !redirect {
+		output = origin
+	}
+
+	if reader, writer, err = os.Pipe(); err != nil {
+		if redirect {
+			_ = output.Close()
+		}
+		return
+	}
+
+	// 2. 读取管道.
+	//    按行转为日志, 同时写入原始的标准错误输出.
+	go func() {
+		defer close(done)
+
+		w := o.Writer(level)
+		_, _ = io.Copy(io.MultiWriter(w, output), reader)
+		_ = w.Close()
+		_ = reader.Close()
+	}()
+
+	// 3. 替换输出.
+	//    适配器的诊断信息写入原始输出, 不经管道写回日志.
+	base.SetStderr(output)
+	if redirect {
+		err = redirectStderr(writer)
+	} else {
+		os.Stderr = writer
+	}
+
+	restore = func() {
+		if redirect {
+			_ = redirectStderr(output)
+		} else {
+			os.Stderr = origin
+		}
+		base.SetStderr(previous)
+		_ = writer.Close()
+		<-done
+		if redirect {
+			_ = output.Close()
+		}
+	}
+
+	// 4. 重定向失败.
+	if err != nil {
+		restore()
+		restore = nil
+	}
+	return
+}
+
+// +---------------------------------------------------------------------------+
+// | Interface methods                                                         |
+// +---------------------------------------------------------------------------+
+
+// Close
+// 输出暂存的不完整行.
+func (o *Writer) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if len(o.buf) > 0 {
+		o.send(o.buf)
+		o.buf = o.buf[:0]
+	}
+	return nil
+}
+
+// Write
+// 按行写入日志.
+func (o *Writer) Write(p []byte) (n int, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.buf = append(o.buf, p...)
+
+	for {
+		i := bytes.IndexByte(o.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		o.send(o.buf[:i])
+		o.buf = o.buf[i+1:]
+	}
+
+	// 释放已输出部分.
+	if len(o.buf) == 0 {
+		o.buf = nil
+	}
+	return len(p), nil
+}
+
+// +---------------------------------------------------------------------------+
+// | Access methods                                                            |
+// +---------------------------------------------------------------------------+
+
+func (o *Writer) send(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(bytes.TrimSpace(line)) == 0 {
+		return
+	}
+
+	if o.logger.config.LevelOn(o.level) {
+		o.logger.manager.Log(o.logger.ctx, o.logger.fields, o.level, "%s", string(line))
+	}
+}